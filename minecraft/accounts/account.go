@@ -0,0 +1,267 @@
+package accounts
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/df-mc/go-playfab/v2"
+	"github.com/df-mc/go-xsapi/v2"
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/auth"
+	"github.com/sandertv/gophertunnel/minecraft/service"
+	"golang.org/x/oauth2"
+	"golang.org/x/time/rate"
+)
+
+// Account is a Minecraft account held by a Manager. It lazily logs in to Xbox Live, PlayFab and the
+// Minecraft authorization service when first needed and caches the tokens obtained, refreshing them when
+// they expire. An Account is created using Manager.Add or Manager.Load. It is safe for concurrent use.
+//
+// Account implements oauth2.TokenSource, service.TokenSource, minecraft.MultiplayerTokenSource and
+// minecraft.ChainSource.
+type Account struct {
+	name    string
+	m       *Manager
+	src     oauth2.TokenSource
+	cache   *auth.XBLTokenCache
+	limiter *rate.Limiter
+	// loginKeys holds the public keys of recent logins that waited for the limiter, so that the multiplayer
+	// token and chain requested for the same login share a single wait.
+	loginKeys []*ecdsa.PublicKey
+	loginMu   sync.Mutex
+
+	mu      sync.Mutex
+	closed  bool
+	xbl     *xsapi.Client
+	playfab *playfab.Client
+	service service.TokenSource
+}
+
+// Name returns the name that the Account was added to its Manager with.
+func (a *Account) Name() string {
+	return a.name
+}
+
+// Token returns the Microsoft Live Connect token of the Account, refreshing it if it has expired.
+func (a *Account) Token() (*oauth2.Token, error) {
+	return a.src.Token()
+}
+
+// XBLClient returns the Xbox Live client of the Account, logging in to Xbox Live if the Account was not yet
+// logged in. The device token and XSTS tokens of the client are cached by the Account. The client is closed
+// when the Account is removed from its Manager and must not be closed by the caller.
+func (a *Account) XBLClient(ctx context.Context) (*xsapi.Client, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.xblClient(a.m.context(ctx))
+}
+
+// xblClient returns the Xbox Live client of the Account, creating it if necessary. a.mu must be held.
+func (a *Account) xblClient(ctx context.Context) (*xsapi.Client, error) {
+	if a.closed {
+		return nil, ErrClosed
+	}
+	if a.xbl != nil {
+		return a.xbl, nil
+	}
+	session := auth.ContextSession(auth.WithXBLTokenCache(ctx, a.cache), a.src)
+	client, err := xsapi.ClientConfig{
+		HTTPClient: a.m.conf.HTTPClient,
+		Logger:     a.m.conf.ErrorLog.With("src", "accounts", "account", a.name),
+		RTAMode:    xsapi.RTADisabled,
+	}.New(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("login to xbox live: %w", err)
+	}
+	a.xbl = client
+	if err := a.save(ctx); err != nil {
+		a.m.conf.ErrorLog.Error("save xbox live tokens: "+err.Error(), "account", a.name)
+	}
+	return client, nil
+}
+
+// PlayFabClient returns the PlayFab client of the Account, logging in to PlayFab with the Xbox Live account
+// if the Account was not yet logged in. The client is closed when the Account is removed from its Manager
+// and must not be closed by the caller.
+func (a *Account) PlayFabClient(ctx context.Context) (*playfab.Client, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.playFabClient(a.m.context(ctx))
+}
+
+// playFabClient returns the PlayFab client of the Account, creating it if necessary. a.mu must be held.
+func (a *Account) playFabClient(ctx context.Context) (*playfab.Client, error) {
+	if a.closed {
+		return nil, ErrClosed
+	}
+	if a.playfab != nil {
+		return a.playfab, nil
+	}
+	xbl, err := a.xblClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e, err := a.m.environment(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("request authorization environment: %w", err)
+	}
+	client, err := playfab.LoginWithXbox(ctx, e.PlayFabTitleID, xbl, playfab.ClientConfig{
+		HTTPClient:    a.m.conf.HTTPClient,
+		Logger:        a.m.conf.ErrorLog.With("src", "accounts", "account", a.name),
+		CreateAccount: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("login to playfab: %w", err)
+	}
+	a.playfab = client
+	return client, nil
+}
+
+// ServiceToken returns a token of the Minecraft authorization service for the Account, logging in to
+// PlayFab if necessary. The token is cached and renewed when it expires.
+func (a *Account) ServiceToken(ctx context.Context) (*service.Token, error) {
+	ctx = a.m.context(ctx)
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if a.service == nil {
+		client, err := a.playFabClient(ctx)
+		if err != nil {
+			a.mu.Unlock()
+			return nil, err
+		}
+		e, err := a.m.environment(ctx)
+		if err != nil {
+			a.mu.Unlock()
+			return nil, fmt.Errorf("request authorization environment: %w", err)
+		}
+		a.service = e.TokenSource(client, service.TokenConfig{})
+	}
+	src := a.service
+	a.mu.Unlock()
+	return src.ServiceToken(ctx)
+}
+
+// MultiplayerToken requests a multiplayer token for the key passed, used to log in to a server. If the
+// Manager of the Account has a LoginLimit, MultiplayerToken first waits until the Account is allowed to log
+// in again, or until the context.Context passed is cancelled, unless a chain was already requested for the
+// same key using MinecraftChain.
+func (a *Account) MultiplayerToken(ctx context.Context, key *ecdsa.PublicKey) (string, error) {
+	ctx = a.m.context(ctx)
+	if err := a.waitLogin(ctx, key); err != nil {
+		return "", err
+	}
+	e, err := a.m.environment(ctx)
+	if err != nil {
+		return "", fmt.Errorf("request authorization environment: %w", err)
+	}
+	return e.MultiplayerToken(ctx, a, key)
+}
+
+// MinecraftChain requests a Minecraft authentication chain for the key passed, logging in to Xbox Live if
+// the Account was not yet logged in. Like MultiplayerToken, it first waits until the Account is allowed to
+// log in again if its Manager has a LoginLimit, unless a multiplayer token was already requested for the
+// same key.
+func (a *Account) MinecraftChain(ctx context.Context, key *ecdsa.PrivateKey) (string, error) {
+	ctx = a.m.context(ctx)
+	if err := a.waitLogin(ctx, &key.PublicKey); err != nil {
+		return "", err
+	}
+	xbl, err := a.XBLClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	return auth.RequestMinecraftChain(ctx, xbl, key)
+}
+
+// maxLoginKeys is the maximum number of keys of recent logins held by an Account.
+const maxLoginKeys = 16
+
+// waitLogin waits until the Account is allowed to log in with the key passed. Logins are identified by their
+// key, so that requesting both the multiplayer token and the chain of a login only waits once.
+func (a *Account) waitLogin(ctx context.Context, key *ecdsa.PublicKey) error {
+	a.loginMu.Lock()
+	if i := slices.IndexFunc(a.loginKeys, func(k *ecdsa.PublicKey) bool { return k.Equal(key) }); i != -1 {
+		a.loginKeys = slices.Delete(a.loginKeys, i, i+1)
+		a.loginMu.Unlock()
+		return nil
+	}
+	a.loginMu.Unlock()
+
+	if err := a.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("wait for login rate limit: %w", err)
+	}
+	a.loginMu.Lock()
+	defer a.loginMu.Unlock()
+	a.loginKeys = append(a.loginKeys, key)
+	if len(a.loginKeys) > maxLoginKeys {
+		a.loginKeys = slices.Delete(a.loginKeys, 0, 1)
+	}
+	return nil
+}
+
+// Dialer returns a minecraft.Dialer that logs in to servers with the Account, logging in to Xbox Live first
+// if the Account was not yet logged in. All Dialers returned share the tokens cached by the Account and the
+// service.Cache of its Manager. Fields of the Dialer other than TokenSource, XBLClient and HTTPClient may be
+// changed freely.
+func (a *Account) Dialer(ctx context.Context) (minecraft.Dialer, error) {
+	xbl, err := a.XBLClient(ctx)
+	if err != nil {
+		return minecraft.Dialer{}, err
+	}
+	return minecraft.Dialer{
+		ErrorLog:     a.m.conf.ErrorLog,
+		HTTPClient:   a.m.conf.HTTPClient,
+		TokenSource:  a,
+		XBLClient:    xbl,
+		ServiceCache: a.m.conf.ServiceCache,
+	}, nil
+}
+
+// Save stores the Xbox Live tokens of the Account in the TokenStore of its Manager, so that they may be
+// reused after a restart. Save is called automatically after logging in to Xbox Live and when the Account
+// is closed. If the Manager has no TokenStore, Save does nothing.
+func (a *Account) Save(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.save(a.m.context(ctx))
+}
+
+// save stores the Xbox Live tokens of the Account. a.mu must be held.
+func (a *Account) save(ctx context.Context) error {
+	if a.m.conf.Store == nil {
+		return nil
+	}
+	return a.cache.Save(ctx, a.m.conf.Store, a.name+".xbl")
+}
+
+// close saves the Xbox Live tokens of the Account and closes its Xbox Live and PlayFab clients.
+func (a *Account) close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+
+	var errs []error
+	if a.xbl != nil {
+		if err := a.save(a.m.context(context.Background())); err != nil {
+			errs = append(errs, fmt.Errorf("save xbox live tokens: %w", err))
+		}
+		errs = append(errs, a.xbl.Close())
+	}
+	if a.playfab != nil {
+		errs = append(errs, a.playfab.Close())
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("close account %q: %w", a.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+// Package accounts implements a Manager that holds a set of named Minecraft accounts, such as the accounts of
+// a fleet of bots, and produces a minecraft.Dialer for each of them.
+//
+// Every Account lazily requests and caches the tokens needed to log in to servers: its Microsoft Live Connect
+// token, the device token and XSTS tokens of its Xbox Live session, its PlayFab session and the token of the
+// Minecraft authorization service. Dialers of the same Account share these tokens, so that dialing many
+// connections does not request new tokens for every connection. If a Manager has a TokenStore, the Live
+// token and Xbox Live tokens of each Account are stored in it, so that they may be reused after a restart.
+//
+// A Manager may additionally limit the rate at which every Account logs in to servers, to avoid running into
+// the rate limits of the Minecraft authorization service.
+package accounts
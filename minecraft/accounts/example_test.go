@@ -0,0 +1,42 @@
+package accounts_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sandertv/gophertunnel/minecraft/accounts"
+	"github.com/sandertv/gophertunnel/minecraft/auth"
+	"golang.org/x/time/rate"
+)
+
+func ExampleManager() {
+	// Store the tokens of all accounts in encrypted files, so that logging in again is not needed after a
+	// restart.
+	store, err := auth.NewFileTokenStore("tokens", []byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		panic(err)
+	}
+	// Allow every account to log in to a server at most once every 5 seconds.
+	m := accounts.NewManager(accounts.Config{Store: store, LoginLimit: rate.Every(5 * time.Second)})
+	defer m.Close()
+
+	for i := range 3 {
+		// Load restores the tokens of the account, or uses device auth to log in if none were stored.
+		if _, err := m.Load(fmt.Sprintf("bot-%v", i), os.Stdout); err != nil {
+			panic(err)
+		}
+	}
+	for _, account := range m.Accounts() {
+		dialer, err := account.Dialer(context.Background())
+		if err != nil {
+			panic(err)
+		}
+		conn, err := dialer.Dial("raknet", "127.0.0.1:19132")
+		if err != nil {
+			panic(err)
+		}
+		_ = conn.Close()
+	}
+}
@@ -0,0 +1,226 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/sandertv/gophertunnel/minecraft/auth"
+	"github.com/sandertv/gophertunnel/minecraft/internal"
+	"github.com/sandertv/gophertunnel/minecraft/service"
+	"golang.org/x/oauth2"
+	"golang.org/x/time/rate"
+)
+
+// Config holds the configuration of a Manager and the Accounts it holds.
+type Config struct {
+	// Auth is the auth.Config used to request the Live and Xbox Live tokens of all Accounts. If left empty,
+	// auth.AndroidConfig is used.
+	Auth auth.Config
+
+	// HTTPClient is the HTTP client used for all requests made to log in Accounts, and by the Dialers
+	// returned by Account.Dialer. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// ErrorLog is the logger passed to the Dialers returned by Account.Dialer and to the Xbox Live clients
+	// of Accounts. By default, errors are not logged.
+	ErrorLog *slog.Logger
+
+	// Store, if non-nil, is used to store the Live token and Xbox Live tokens of every Account, under the
+	// keys '<name>.live' and '<name>.xbl' respectively. Account names must then be valid keys of the
+	// TokenStore. Tokens stored may be restored using Manager.Load.
+	Store auth.TokenStore
+
+	// LoginLimit is the maximum rate, in logins per second, at which a single Account requests the
+	// multiplayer tokens and authentication chains needed to log in to servers. Dials that would exceed the
+	// limit wait until they are allowed to log in. The token and chain requested for the same login are
+	// limited once. If zero, logins are not limited.
+	LoginLimit rate.Limit
+	// LoginBurst is the number of logins an Account may perform at once before LoginLimit applies. If zero,
+	// a burst of 1 is used.
+	LoginBurst int
+
+	// ServiceCache is used to discover the authorization service of Minecraft for all Accounts, and is
+	// passed to the Dialers returned by Account.Dialer. If nil, a service.Cache keeping its entries in memory
+	// is used.
+	ServiceCache *service.Cache
+}
+
+var (
+	// ErrAccountExists is returned by Manager.Add and Manager.Load if the Manager already holds an Account
+	// with the name passed.
+	ErrAccountExists = errors.New("accounts: account already exists")
+	// ErrAccountNotFound is returned by Manager.Remove if the Manager holds no Account with the name passed.
+	ErrAccountNotFound = errors.New("accounts: account not found")
+	// ErrClosed is returned when using an Account that was removed from its Manager, or when adding an
+	// Account to a Manager that was closed.
+	ErrClosed = errors.New("accounts: use of closed account or manager")
+)
+
+// Manager holds a set of named Accounts and the tokens used to log them in. A Manager must be created using
+// NewManager. It is safe for concurrent use.
+type Manager struct {
+	conf Config
+
+	mu       sync.Mutex
+	accounts map[string]*Account
+	closed   bool
+}
+
+// NewManager returns a new Manager holding no Accounts, using the Config passed.
+func NewManager(conf Config) *Manager {
+	if conf.Auth.ClientID == "" {
+		conf.Auth = auth.AndroidConfig
+	}
+	if conf.HTTPClient == nil {
+		conf.HTTPClient = http.DefaultClient
+	}
+	if conf.ErrorLog == nil {
+		conf.ErrorLog = slog.New(internal.DiscardHandler{})
+	}
+	if conf.LoginLimit == 0 {
+		conf.LoginLimit = rate.Inf
+	}
+	if conf.LoginBurst <= 0 {
+		conf.LoginBurst = 1
+	}
+	if conf.ServiceCache == nil {
+		// A Cache without a directory cannot fail to be created.
+		conf.ServiceCache, _ = service.CacheConfig{}.New()
+	}
+	return &Manager{conf: conf, accounts: make(map[string]*Account)}
+}
+
+// Add adds a new Account with the name passed, which uses the oauth2.TokenSource passed to obtain Live
+// tokens, for example one returned by auth.RefreshTokenSource. If the Manager has a TokenStore, every token
+// returned by the oauth2.TokenSource is stored in it, and Xbox Live tokens stored previously for the Account
+// are reused.
+func (m *Manager) Add(name string, src oauth2.TokenSource) (*Account, error) {
+	if src == nil {
+		return nil, fmt.Errorf("add account %q: token source is nil", name)
+	}
+	if m.conf.Store != nil {
+		src = auth.SaveTokenSource(m.conf.Store, name+".live", src)
+	}
+	return m.add(name, src)
+}
+
+// Load adds a new Account with the name passed, restoring its Live token and Xbox Live tokens from the
+// TokenStore of the Manager. If no Live token was stored for the Account, or it can no longer be refreshed,
+// device auth is used to request a new one when the Account is first used, printing the auth URL and code
+// to the io.Writer passed. Load returns an error if the Manager has no TokenStore.
+func (m *Manager) Load(name string, w io.Writer) (*Account, error) {
+	if m.conf.Store == nil {
+		return nil, fmt.Errorf("load account %q: manager has no token store", name)
+	}
+	src, err := m.conf.Auth.StoreTokenSource(m.conf.Store, name+".live", w)
+	if err != nil {
+		return nil, fmt.Errorf("load account %q: %w", name, err)
+	}
+	return m.add(name, src)
+}
+
+// add adds a new Account with the name passed that obtains Live tokens from src.
+func (m *Manager) add(name string, src oauth2.TokenSource) (*Account, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, errors.New("add account: name must not be empty")
+	}
+	cache := m.conf.Auth.NewTokenCache()
+	if m.conf.Store != nil {
+		var err error
+		if cache, err = m.conf.Auth.LoadTokenCache(m.conf.Store, name+".xbl"); err != nil {
+			return nil, fmt.Errorf("add account %q: load xbox live tokens: %w", name, err)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, ErrClosed
+	}
+	if _, ok := m.accounts[name]; ok {
+		return nil, fmt.Errorf("add account %q: %w", name, ErrAccountExists)
+	}
+	a := &Account{
+		name:    name,
+		m:       m,
+		src:     src,
+		cache:   cache,
+		limiter: rate.NewLimiter(m.conf.LoginLimit, m.conf.LoginBurst),
+	}
+	m.accounts[name] = a
+	return a, nil
+}
+
+// Account returns the Account with the name passed. If the Manager holds no such Account, false is
+// returned.
+func (m *Manager) Account(name string) (*Account, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.accounts[name]
+	return a, ok
+}
+
+// Accounts returns all Accounts held by the Manager, sorted by name.
+func (m *Manager) Accounts() []*Account {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	accounts := make([]*Account, 0, len(m.accounts))
+	for _, a := range m.accounts {
+		accounts = append(accounts, a)
+	}
+	slices.SortFunc(accounts, func(a, b *Account) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return accounts
+}
+
+// Remove removes the Account with the name passed from the Manager. The Xbox Live tokens of the Account are
+// saved and its Xbox Live and PlayFab clients are closed. Using the Account after removing it returns
+// ErrClosed.
+func (m *Manager) Remove(name string) error {
+	m.mu.Lock()
+	a, ok := m.accounts[name]
+	delete(m.accounts, name)
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("remove account %q: %w", name, ErrAccountNotFound)
+	}
+	return a.close()
+}
+
+// Close removes and closes all Accounts held by the Manager. No Accounts may be added to the Manager after
+// it is closed.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	accounts := m.accounts
+	m.accounts, m.closed = make(map[string]*Account), true
+	m.mu.Unlock()
+
+	var errs []error
+	for _, a := range accounts {
+		errs = append(errs, a.close())
+	}
+	return errors.Join(errs...)
+}
+
+// context returns a copy of the context.Context passed holding the HTTP client of the Manager, for use with
+// functions in the auth package.
+func (m *Manager) context(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return auth.WithContextClient(ctx, m.conf.HTTPClient)
+}
+
+// environment returns the environment of the Minecraft authorization service, discovering it using the
+// service.Cache of the Manager if it was not yet discovered.
+func (m *Manager) environment(ctx context.Context) (*service.AuthorizationEnvironment, error) {
+	return m.conf.ServiceCache.AuthorizationEnvironment(ctx)
+}
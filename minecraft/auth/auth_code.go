@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/microsoft"
+)
+
+// AuthCodeFlow holds the options for a login request for Microsoft Live Connect using the authorization code
+// flow with PKCE, as done by RequestLiveTokenAuthCode.
+type AuthCodeFlow struct {
+	// Addr is the local address that the HTTP listener receiving the redirect after login listens on. If
+	// empty, 127.0.0.1 is used with a port chosen by the system. The redirect URI sent to Microsoft is
+	// http://<Addr>/callback, which must be allowed for the client ID of the Config used.
+	Addr string
+	// OpenURL is called with the URL at which the user must log in, for example to open it in a browser.
+	// If nil, the URL is printed to os.Stdout.
+	OpenURL func(url string) error
+}
+
+// RequestLiveTokenAuthCode does a login request for Microsoft Live Connect using the authorization code flow
+// with PKCE. It starts an HTTP listener on localhost that receives the redirect after the user logs in at the
+// URL passed to the OpenURL function of the AuthCodeFlow, and exchanges the code received for a token. The
+// token returned may be used with RefreshTokenSource. The context passed may be used to stop waiting for the
+// user to log in.
+func RequestLiveTokenAuthCode(ctx context.Context, flow AuthCodeFlow) (*oauth2.Token, error) {
+	return AndroidConfig.RequestLiveTokenAuthCode(ctx, flow)
+}
+
+// RequestLiveTokenAuthCode does a login request for Microsoft Live Connect using the authorization code flow
+// with PKCE. It starts an HTTP listener on localhost that receives the redirect after the user logs in at the
+// URL passed to the OpenURL function of the AuthCodeFlow, and exchanges the code received for a token. The
+// token returned may be used with Config.RefreshTokenSource. The context passed may be used to stop waiting
+// for the user to log in.
+//
+// Note that Microsoft only redirects to a localhost URI if it is registered for the ClientID of the Config.
+func (conf Config) RequestLiveTokenAuthCode(ctx context.Context, flow AuthCodeFlow) (*oauth2.Token, error) {
+	addr := flow.Addr
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listen for auth code callback: %w", err)
+	}
+	redirectURI := "http://" + l.Addr().String() + "/callback"
+	state, verifier := randomString(), oauth2.GenerateVerifier()
+
+	codes, errs := make(chan string, 1), make(chan error, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		code, err := authCodeFromCallback(r, state)
+		if err != nil {
+			http.Error(w, "Authentication failed: "+err.Error(), http.StatusBadRequest)
+			select {
+			case errs <- err:
+			default:
+			}
+			return
+		}
+		_, _ = w.Write([]byte("Authentication successful. You may close this window.\n"))
+		select {
+		case codes <- code:
+		default:
+		}
+	})
+	srv := &http.Server{Handler: mux}
+	go func() {
+		_ = srv.Serve(l)
+	}()
+	defer func() {
+		_ = srv.Close()
+	}()
+
+	u := conf.LiveAuthCodeURL(redirectURI, state, verifier)
+	if flow.OpenURL == nil {
+		_, _ = fmt.Fprintf(os.Stdout, "Authenticate at %v.\n", u)
+	} else if err := flow.OpenURL(u); err != nil {
+		return nil, fmt.Errorf("open auth code URL: %w", err)
+	}
+
+	var code string
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case err := <-errs:
+		return nil, err
+	case code = <-codes:
+	}
+	t, err := conf.Exchange(ctx, code, oauth2.VerifierOption(verifier), oauth2.SetAuthURLParam("redirect_uri", redirectURI))
+	if err != nil {
+		return nil, fmt.Errorf("exchange auth code: %w", err)
+	}
+	return t, nil
+}
+
+// LiveAuthCodeURL returns the URL of the Microsoft Live Connect login page for the authorization code flow,
+// which redirects to the redirect URI passed with the code and state passed as query parameters once the user
+// logs in. The verifier passed, as returned by oauth2.GenerateVerifier, is used to add a PKCE challenge to the
+// URL and must be passed to Config.Exchange along with the code using oauth2.VerifierOption.
+func (conf Config) LiveAuthCodeURL(redirectURI, state, verifier string) string {
+	endpoint := microsoft.LiveConnectEndpoint
+	endpoint.AuthStyle = oauth2.AuthStyleInParams
+	c := &oauth2.Config{
+		ClientID:    conf.ClientID,
+		Endpoint:    endpoint,
+		RedirectURL: redirectURI,
+		Scopes:      []string{liveScope},
+	}
+	return c.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))
+}
+
+// liveScope is the scope requested for Microsoft Live Connect tokens used to log in to Xbox Live.
+const liveScope = "service::user.auth.xboxlive.com::MBI_SSL"
+
+// authCodeFromCallback returns the authorization code from the redirect request passed, checking that the
+// state in the request matches the state passed.
+func authCodeFromCallback(r *http.Request, state string) (string, error) {
+	q := r.URL.Query()
+	if e := q.Get("error"); e != "" {
+		return "", fmt.Errorf("auth code callback: %v: %v", e, q.Get("error_description"))
+	}
+	if q.Get("state") != state {
+		return "", errors.New("auth code callback: state mismatch")
+	}
+	code := q.Get("code")
+	if code == "" {
+		return "", errors.New("auth code callback: missing code")
+	}
+	return code, nil
+}
+
+// randomString returns a random URL-safe string of 32 bytes of entropy.
+func randomString() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,38 @@
+// Package authtest implements a local stand-in for the Microsoft, Xbox Live and Minecraft services that are
+// used to authenticate with Minecraft: Bedrock Edition, so that the auth and service packages and the Dialer
+// and Listener in the minecraft package may be exercised in tests without live endpoints.
+//
+// A Server emulates the Microsoft Live Connect device code, authorization and token endpoints, Xbox Live
+// device, SISU and XSTS authentication, NSAL title data, the Minecraft chain endpoint used by
+// auth.RequestMinecraftChain, PlayFab login, service discovery and the Minecraft authorization service,
+// including its OpenID configuration, JWKS and multiplayer tokens. All of these are served from a single
+// httptest.Server under the host names of the real services: the *http.Client returned by Server.Client
+// routes requests to those hosts to the Server, and leaves requests to any other host untouched.
+//
+// The HTTP client may be passed wherever the packages accept one:
+//
+//	srv := authtest.NewServer(authtest.Config{DisplayName: "Steve"})
+//	defer srv.Close()
+//
+//	// auth.Config and service.Discover read the HTTP client from the context.
+//	ctx := srv.Context(context.Background())
+//	t, err := auth.RequestLiveTokenDeviceAuth(ctx, auth.DeviceAuth{})
+//
+//	// The Dialer and ListenConfig have an HTTPClient field.
+//	l, err := minecraft.ListenConfig{HTTPClient: srv.Client()}.Listen("raknet", "127.0.0.1:19132")
+//	conn, err := minecraft.Dialer{TokenSource: srv.TokenSource(), HTTPClient: srv.Client()}.Dial("raknet", l.Addr().String())
+//
+// Server.SetFault makes an endpoint fail or issue expired or malformed tokens, and Server.Requests reports
+// how often an endpoint was requested.
+//
+// Several results are cached globally by the packages using them: the NSAL default title data, the service
+// discovery result and the authorization environment of the minecraft package are requested only once per
+// process, along with the OpenID configuration and keys of the authorization service. A test binary should
+// therefore share a single Server between its tests, for example by creating it in TestMain, and faults set
+// on the EndpointTitleData, EndpointDiscovery and EndpointOpenID endpoints only have an effect before their
+// result was first cached.
+//
+// The Minecraft chain returned by the Server is signed by a key of the Server rather than the key of Mojang.
+// A Listener therefore only authenticates connections by their multiplayer token, and rejects connections
+// from Dialers with EnableLegacyAuth set.
+package authtest
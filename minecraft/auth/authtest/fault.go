@@ -0,0 +1,114 @@
+package authtest
+
+import "fmt"
+
+// Endpoint is an endpoint, or a group of closely related endpoints, emulated by a Server.
+type Endpoint int
+
+const (
+	// EndpointLiveDeviceCode is the Microsoft Live Connect endpoint that issues device codes for device auth.
+	EndpointLiveDeviceCode Endpoint = iota
+	// EndpointLiveAuthorize is the Microsoft Live Connect login page of the authorization code flow. The
+	// Server redirects to the redirect URI immediately, as if the user logged in.
+	EndpointLiveAuthorize
+	// EndpointLiveToken is the Microsoft Live Connect token endpoint, which issues tokens for device codes,
+	// authorization codes and refresh tokens.
+	EndpointLiveToken
+	// EndpointDeviceToken is the Xbox Live device authentication endpoint, which issues device tokens.
+	EndpointDeviceToken
+	// EndpointSISU is the Xbox Live SISU authorization endpoint, which issues title and user tokens along
+	// with an XSTS token for the relying party http://xboxlive.com.
+	EndpointSISU
+	// EndpointXSTS is the Xbox Live XSTS authorization endpoint, which issues XSTS tokens for other relying
+	// parties.
+	EndpointXSTS
+	// EndpointTitleData is the NSAL endpoint that returns the title data of the default and current title.
+	EndpointTitleData
+	// EndpointChain is the Minecraft endpoint that issues the login chain requested by
+	// auth.RequestMinecraftChain.
+	EndpointChain
+	// EndpointPlayFab is the PlayFab endpoint used to log in with Xbox Live and to exchange entity tokens.
+	EndpointPlayFab
+	// EndpointDiscovery is the Minecraft service discovery endpoint.
+	EndpointDiscovery
+	// EndpointSession is the endpoint of the Minecraft authorization service that starts and renews
+	// sessions, issuing the tokens returned by service.TokenSource.
+	EndpointSession
+	// EndpointMultiplayerToken is the endpoint of the Minecraft authorization service that issues
+	// multiplayer tokens.
+	EndpointMultiplayerToken
+	// EndpointOpenID is the OpenID configuration and JWKS of the Minecraft authorization service, used to
+	// verify multiplayer tokens.
+	EndpointOpenID
+	endpointCount
+)
+
+// String ...
+func (e Endpoint) String() string {
+	switch e {
+	case EndpointLiveDeviceCode:
+		return "live_device_code"
+	case EndpointLiveAuthorize:
+		return "live_authorize"
+	case EndpointLiveToken:
+		return "live_token"
+	case EndpointDeviceToken:
+		return "device_token"
+	case EndpointSISU:
+		return "sisu"
+	case EndpointXSTS:
+		return "xsts"
+	case EndpointTitleData:
+		return "title_data"
+	case EndpointChain:
+		return "chain"
+	case EndpointPlayFab:
+		return "playfab"
+	case EndpointDiscovery:
+		return "discovery"
+	case EndpointSession:
+		return "session"
+	case EndpointMultiplayerToken:
+		return "multiplayer_token"
+	case EndpointOpenID:
+		return "openid"
+	}
+	return fmt.Sprintf("Endpoint(%d)", int(e))
+}
+
+// Fault is a fault that a Server may be set to produce on an Endpoint using Server.SetFault.
+type Fault int
+
+const (
+	// FaultNone makes an endpoint behave normally.
+	FaultNone Fault = iota
+	// FaultUnavailable makes an endpoint respond with 503 Service Unavailable.
+	FaultUnavailable
+	// FaultMalformed makes an endpoint respond with 200 OK and a body that is not valid JSON.
+	FaultMalformed
+	// FaultExpired makes an endpoint issue tokens that have already expired. The Live token endpoint
+	// instead responds with expired_token to device code polls and invalid_grant to other grants.
+	// FaultExpired has no effect on endpoints that do not issue tokens.
+	FaultExpired
+	// FaultInvalidSignature makes an endpoint issue JWTs signed with a key other than the key published for
+	// it, so that their signature cannot be verified. It only has an effect on EndpointChain, EndpointSession
+	// and EndpointMultiplayerToken.
+	FaultInvalidSignature
+)
+
+// String ...
+func (f Fault) String() string {
+	switch f {
+	case FaultNone:
+		return "none"
+	case FaultUnavailable:
+		return "unavailable"
+	case FaultMalformed:
+		return "malformed"
+	case FaultExpired:
+		return "expired"
+	case FaultInvalidSignature:
+		return "invalid_signature"
+	}
+	return fmt.Sprintf("Fault(%d)", int(f))
+}
@@ -0,0 +1,147 @@
+package authtest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// handleLive registers the handlers of the Microsoft Live Connect endpoints.
+func (s *Server) handleLive() {
+	s.handle("POST "+liveHost+"/oauth20_connect.srf", EndpointLiveDeviceCode, s.serveDeviceCode)
+	s.handle("GET "+liveHost+"/oauth20_authorize.srf", EndpointLiveAuthorize, s.serveAuthorize)
+	s.handle("POST "+liveHost+"/oauth20_token.srf", EndpointLiveToken, s.serveLiveToken)
+}
+
+// serveDeviceCode issues a device code for device auth.
+func (s *Server) serveDeviceCode(w http.ResponseWriter, r *http.Request) {
+	if r.PostFormValue("client_id") == "" {
+		writeLiveError(w, "invalid_request", "client_id is missing")
+		return
+	}
+	code := s.issue("device_code", s.expiry(EndpointLiveDeviceCode, 15*time.Minute))
+	s.mu.Lock()
+	s.deviceCodes[code] = 0
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"device_code":      code,
+		"user_code":        "AUTHTEST",
+		"verification_uri": "https://www.microsoft.com/link",
+		"expires_in":       900,
+		"interval":         1,
+	})
+}
+
+// serveAuthorize serves the login page of the authorization code flow. It redirects to the redirect URI
+// immediately with a new authorization code, as if the user logged in.
+func (s *Server) serveAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || !redirectURI.IsAbs() {
+		http.Error(w, "authtest: invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if q.Get("client_id") == "" || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "authtest: client_id and an S256 code_challenge are required", http.StatusBadRequest)
+		return
+	}
+	code := s.issue("auth_code", time.Now().Add(5*time.Minute))
+	s.mu.Lock()
+	s.authCodes[code] = authCode{challenge: q.Get("code_challenge"), redirectURI: redirectURI.String()}
+	s.mu.Unlock()
+
+	rq := redirectURI.Query()
+	rq.Set("code", code)
+	rq.Set("state", q.Get("state"))
+	redirectURI.RawQuery = rq.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+// serveLiveToken issues Live tokens for device codes, authorization codes and refresh tokens.
+func (s *Server) serveLiveToken(w http.ResponseWriter, r *http.Request) {
+	expired := s.fault(EndpointLiveToken) == FaultExpired
+	switch r.PostFormValue("grant_type") {
+	case "urn:ietf:params:oauth:grant-type:device_code":
+		code := r.PostFormValue("device_code")
+		s.mu.Lock()
+		t, ok := s.issued[code]
+		polls := s.deviceCodes[code] + 1
+		s.deviceCodes[code] = polls
+		s.mu.Unlock()
+		switch {
+		case !ok || t.kind != "device_code":
+			writeLiveError(w, "invalid_grant", "unknown device code")
+		case expired || time.Now().After(t.expiry):
+			writeLiveError(w, "expired_token", "the device code has expired")
+		case polls <= s.conf.DeviceAuthPolls:
+			writeLiveError(w, "authorization_pending", "the user has not yet logged in")
+		default:
+			s.mu.Lock()
+			delete(s.issued, code)
+			delete(s.deviceCodes, code)
+			s.mu.Unlock()
+			writeLiveToken(w, s.liveToken())
+		}
+	case "authorization_code":
+		code := r.PostFormValue("code")
+		s.mu.Lock()
+		c, ok := s.authCodes[code]
+		delete(s.authCodes, code)
+		s.mu.Unlock()
+		sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		switch {
+		case expired || !ok || !s.valid(code, "auth_code"):
+			writeLiveError(w, "invalid_grant", "the authorization code is invalid or has expired")
+		case base64.RawURLEncoding.EncodeToString(sum[:]) != c.challenge:
+			writeLiveError(w, "invalid_grant", "the code_verifier does not match the code_challenge")
+		case r.PostFormValue("redirect_uri") != c.redirectURI:
+			writeLiveError(w, "invalid_grant", "the redirect_uri does not match")
+		default:
+			writeLiveToken(w, s.liveToken())
+		}
+	case "refresh_token":
+		if expired || !s.valid(r.PostFormValue("refresh_token"), "live_refresh") {
+			writeLiveError(w, "invalid_grant", "the refresh token is invalid or has expired")
+			return
+		}
+		writeLiveToken(w, s.liveToken())
+	default:
+		writeLiveError(w, "unsupported_grant_type", "the grant type is not supported")
+	}
+}
+
+// liveToken issues a new Live access token and refresh token.
+func (s *Server) liveToken() *oauth2.Token {
+	expiry := time.Now().Add(tokenLifetime)
+	return &oauth2.Token{
+		AccessToken:  s.issue("live", expiry),
+		TokenType:    "bearer",
+		RefreshToken: s.issue("live_refresh", time.Now().Add(90*24*time.Hour)),
+		Expiry:       expiry,
+		ExpiresIn:    int64(tokenLifetime / time.Second),
+	}
+}
+
+// writeLiveToken writes a successful response of the Live token endpoint holding the token passed.
+func writeLiveToken(w http.ResponseWriter, t *oauth2.Token) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"token_type":    t.TokenType,
+		"expires_in":    t.ExpiresIn,
+		"scope":         "service::user.auth.xboxlive.com::MBI_SSL",
+		"access_token":  t.AccessToken,
+		"refresh_token": t.RefreshToken,
+		"user_id":       "authtest",
+	})
+}
+
+// writeLiveError writes an OAuth2 error response of the Live token endpoint.
+func writeLiveError(w http.ResponseWriter, code, description string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
@@ -0,0 +1,326 @@
+package authtest
+
+import (
+	"crypto/ecdsa"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/google/uuid"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/login"
+)
+
+const (
+	// authServiceURI is the service URI and issuer of the Minecraft authorization service returned by
+	// service discovery.
+	authServiceURI = "https://" + authServiceHost
+	// playFabTitleID is the PlayFab title ID of Minecraft returned by service discovery.
+	playFabTitleID = "20CA2"
+	// multiplayerAudience is the audience of multiplayer tokens.
+	multiplayerAudience = "api://auth-minecraft-services/multiplayer"
+)
+
+// handleMinecraft registers the handlers of the Minecraft chain endpoint, service discovery, PlayFab and the
+// Minecraft authorization service.
+func (s *Server) handleMinecraft() {
+	s.handle("POST "+chainHost+"/authentication", EndpointChain, s.serveChain)
+	s.handle("GET "+discoveryHost+"/api/v1.0/discovery/{app}/builds/{version}", EndpointDiscovery, s.serveDiscovery)
+	s.handle("POST "+playFabHost+"/Client/LoginWithXbox", EndpointPlayFab, s.servePlayFabLogin)
+	s.handle("POST "+playFabHost+"/Authentication/GetEntityToken", EndpointPlayFab, s.servePlayFabEntityToken)
+	s.handle("POST "+authServiceHost+"/api/v1.0/session/start", EndpointSession, s.serveSessionStart)
+	s.handle("POST "+authServiceHost+"/api/v1.0/session/renew", EndpointSession, s.serveSessionRenew)
+	s.handle("POST "+authServiceHost+"/api/v1.0/multiplayer/session/start", EndpointMultiplayerToken, s.serveMultiplayerToken)
+	s.handle("GET "+authServiceHost+"/.well-known/openid-configuration", EndpointOpenID, s.serveOpenIDConfiguration)
+	s.handle("GET "+authServiceHost+"/.well-known/keys", EndpointOpenID, s.serveKeys)
+}
+
+// serveChain issues a Minecraft login chain certifying the identity public key in the request. The chain
+// is signed by the chain key of the Server in place of the key of Mojang.
+func (s *Server) serveChain(w http.ResponseWriter, r *http.Request) {
+	if !s.xblAuthorized(r, chainRelyingParty) {
+		http.Error(w, "authtest: chain requests require an XSTS token for "+chainRelyingParty, http.StatusUnauthorized)
+		return
+	}
+	var req struct {
+		IdentityPublicKey string `json:"identityPublicKey"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if err := login.ParsePublicKey(req.IdentityPublicKey, &ecdsa.PublicKey{}); err != nil {
+		http.Error(w, "authtest: invalid identityPublicKey: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&s.chainKey.PublicKey)
+	rootKey := base64.StdEncoding.EncodeToString(der)
+
+	key := s.chainKey
+	if s.fault(EndpointChain) == FaultInvalidSignature {
+		key, _ = s.rogue()
+	}
+	claims := s.claims(EndpointChain, "Mojang", 24*time.Hour)
+	header := map[jose.HeaderKey]any{"x5u": rootKey}
+	writeJSON(w, http.StatusOK, map[string][]string{"chain": {
+		signJWT(key, jose.ES384, header, claims, map[string]any{
+			"certificateAuthority": true,
+			"identityPublicKey":    rootKey,
+		}),
+		signJWT(key, jose.ES384, header, claims, map[string]any{
+			"identityPublicKey": req.IdentityPublicKey,
+			"randomNonce":       time.Now().UnixNano(),
+			"extraData": login.IdentityData{
+				XUID:        s.conf.XUID,
+				Identity:    identityFromXUID(s.conf.XUID),
+				DisplayName: s.conf.DisplayName,
+				TitleID:     s.conf.TitleID,
+			},
+		}),
+	}})
+}
+
+// serveDiscovery serves the result of service discovery, which points the authorization service at the
+// Server.
+func (s *Server) serveDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"result": map[string]any{
+		"serviceEnvironments": map[string]any{
+			"auth": map[string]any{"prod": map[string]string{
+				"serviceUri":        authServiceURI,
+				"issuer":            authServiceURI,
+				"playFabTitleId":    playFabTitleID,
+				"eduPlayFabTitleId": "6955F",
+			}},
+		},
+		"supportedEnvironments": map[string][]string{r.PathValue("version"): {"prod"}},
+	}})
+}
+
+// servePlayFabLogin serves a PlayFab login with an XSTS token for PlayFab.
+func (s *Server) servePlayFabLogin(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		TitleID   string `json:"TitleId"`
+		XboxToken string
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if !s.validXBLToken(req.XboxToken, playFabRelyingParty) {
+		writePlayFabError(w, http.StatusUnauthorized, "InvalidXboxLiveToken", "the Xbox Live token is invalid")
+		return
+	}
+	expiry := s.expiry(EndpointPlayFab, 24*time.Hour)
+	writeJSON(w, http.StatusOK, map[string]any{"code": http.StatusOK, "status": "OK", "data": map[string]any{
+		"SessionTicket": s.issue("ticket", expiry),
+		"PlayFabId":     s.conf.PlayFabID,
+		"NewlyCreated":  false,
+		"LastLoginTime": time.Now().Add(-time.Hour),
+		"EntityToken":   s.entityToken("title_player_account", s.titlePlayerID, expiry),
+	}})
+}
+
+// servePlayFabEntityToken exchanges a PlayFab entity token for a token of another entity of the user.
+func (s *Server) servePlayFabEntityToken(w http.ResponseWriter, r *http.Request) {
+	if !s.valid(r.Header.Get("X-EntityToken"), "entity") {
+		writePlayFabError(w, http.StatusUnauthorized, "NotAuthenticated", "the entity token is invalid")
+		return
+	}
+	var req struct {
+		Entity struct {
+			ID   string `json:"Id"`
+			Type string
+		}
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Entity.Type == "" {
+		req.Entity.Type, req.Entity.ID = "title_player_account", s.titlePlayerID
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"code": http.StatusOK, "status": "OK",
+		"data": s.entityToken(req.Entity.Type, req.Entity.ID, s.expiry(EndpointPlayFab, 24*time.Hour)),
+	})
+}
+
+// entityToken issues a PlayFab entity token for the entity passed.
+func (s *Server) entityToken(entityType, id string, expiry time.Time) map[string]any {
+	return map[string]any{
+		"Entity":          map[string]string{"Id": id, "Type": entityType},
+		"EntityToken":     s.issue("entity", expiry),
+		"TokenExpiration": expiry,
+	}
+}
+
+// writePlayFabError writes an error response in the format used by PlayFab.
+func writePlayFabError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]any{
+		"code":         status,
+		"status":       http.StatusText(status),
+		"error":        code,
+		"errorCode":    1000,
+		"errorMessage": message,
+	})
+}
+
+// serveSessionStart starts a session with the authorization service using a PlayFab session ticket.
+func (s *Server) serveSessionStart(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		User struct {
+			Token     string `json:"token"`
+			TokenType string `json:"tokenType"`
+		} `json:"user"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.User.TokenType != "PlayFab" || !s.valid(req.User.Token, "ticket") {
+		writeServiceError(w, http.StatusUnauthorized, "InvalidToken", "the PlayFab session ticket is invalid")
+		return
+	}
+	s.writeSessionToken(w)
+}
+
+// serveSessionRenew renews a session with the authorization service.
+func (s *Server) serveSessionRenew(w http.ResponseWriter, r *http.Request) {
+	if !s.valid(r.Header.Get("Authorization"), "service") {
+		writeServiceError(w, http.StatusUnauthorized, "InvalidToken", "the authorization header is invalid")
+		return
+	}
+	s.writeSessionToken(w)
+}
+
+// writeSessionToken issues a new session token of the authorization service.
+func (s *Server) writeSessionToken(w http.ResponseWriter) {
+	claims := s.claims(EndpointSession, authServiceURI+"/", tokenLifetime)
+	header := "MCToken " + signJWT(s.serviceKey(EndpointSession), jose.RS256, nil, claims, map[string]any{
+		"pmid": s.pmid,
+		"sub":  s.conf.PlayFabID,
+		"aud":  "api://auth-minecraft-services/gateway",
+	})
+	s.register(header, "service", claims.Expiry.Time())
+	writeJSON(w, http.StatusOK, map[string]any{"result": map[string]any{
+		"authorizationHeader": header,
+		"validUntil":          claims.Expiry.Time(),
+		"treatments":          []string{},
+		"configurations":      map[string]any{},
+	}})
+}
+
+// serveMultiplayerToken issues a multiplayer token for the public key in the request.
+func (s *Server) serveMultiplayerToken(w http.ResponseWriter, r *http.Request) {
+	if !s.valid(r.Header.Get("Authorization"), "service") {
+		writeServiceError(w, http.StatusUnauthorized, "InvalidToken", "the authorization header is invalid")
+		return
+	}
+	var req struct {
+		PublicKey string `json:"publicKey"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if err := login.ParsePublicKey(req.PublicKey, &ecdsa.PublicKey{}); err != nil {
+		writeServiceError(w, http.StatusBadRequest, "InvalidPublicKey", err.Error())
+		return
+	}
+	claims := s.claims(EndpointMultiplayerToken, authServiceURI+"/", tokenLifetime)
+	writeJSON(w, http.StatusOK, map[string]any{"result": map[string]any{
+		"signedToken": signJWT(s.serviceKey(EndpointMultiplayerToken), jose.RS256, nil, claims, map[string]any{
+			"aud":   multiplayerAudience,
+			"sub":   s.conf.PlayFabID,
+			"ipt":   "PlayFab",
+			"mid":   s.conf.PlayFabID,
+			"tid":   playFabTitleID,
+			"cpk":   req.PublicKey,
+			"xid":   s.conf.XUID,
+			"xname": s.conf.DisplayName,
+		}),
+		"issuedAt":   claims.IssuedAt.Time(),
+		"validUntil": claims.Expiry.Time(),
+	}})
+}
+
+// serveOpenIDConfiguration serves the OpenID configuration of the authorization service.
+func (s *Server) serveOpenIDConfiguration(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                authServiceURI + "/",
+		"jwks_uri":                              authServiceURI + "/.well-known/keys",
+		"id_token_signing_alg_values_supported": []string{string(jose.RS256)},
+		"response_types_supported":              []string{"id_token"},
+		"subject_types_supported":               []string{"public"},
+	})
+}
+
+// serveKeys serves the JWKS of the authorization service, holding the key that session and multiplayer
+// tokens are signed with.
+func (s *Server) serveKeys(w http.ResponseWriter, _ *http.Request) {
+	der, _ := x509.MarshalPKIXPublicKey(&s.oidcKey.PublicKey)
+	thumbprint := sha1.Sum(der)
+	writeJSON(w, http.StatusOK, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:                       &s.oidcKey.PublicKey,
+		KeyID:                     s.oidcKeyID,
+		Algorithm:                 string(jose.RS256),
+		Use:                       "sig",
+		CertificateThumbprintSHA1: thumbprint[:],
+	}}})
+}
+
+// writeServiceError writes an error response in the format used by the Minecraft services.
+func writeServiceError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]string{
+		"namespace": "authtest",
+		"code":      code,
+		"message":   message,
+	})
+}
+
+// serviceKey returns the key that tokens issued by the Endpoint passed are signed with.
+func (s *Server) serviceKey(e Endpoint) jose.JSONWebKey {
+	if s.fault(e) == FaultInvalidSignature {
+		_, key := s.rogue()
+		return jose.JSONWebKey{Key: key, KeyID: s.oidcKeyID}
+	}
+	return jose.JSONWebKey{Key: s.oidcKey, KeyID: s.oidcKeyID}
+}
+
+// claims returns the registered claims of a token issued by the Endpoint passed with the issuer and
+// lifetime passed. The token has already expired if the Endpoint has FaultExpired set.
+func (s *Server) claims(e Endpoint, issuer string, lifetime time.Duration) jwt.Claims {
+	expiry := s.expiry(e, lifetime)
+	return jwt.Claims{
+		Issuer:    issuer,
+		IssuedAt:  jwt.NewNumericDate(expiry.Add(-lifetime)),
+		NotBefore: jwt.NewNumericDate(expiry.Add(-lifetime - time.Minute)),
+		Expiry:    jwt.NewNumericDate(expiry),
+	}
+}
+
+// signJWT signs a JWT holding the claims passed using the key and algorithm passed, adding the extra headers
+// passed to the JWT.
+func signJWT(key any, alg jose.SignatureAlgorithm, headers map[jose.HeaderKey]any, claims ...any) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key}, (&jose.SignerOptions{ExtraHeaders: headers}).WithType("JWT"))
+	if err != nil {
+		panic("authtest: create signer: " + err.Error())
+	}
+	builder := jwt.Signed(signer)
+	for _, c := range claims {
+		builder = builder.Claims(c)
+	}
+	t, err := builder.Serialize()
+	if err != nil {
+		panic("authtest: sign JWT: " + err.Error())
+	}
+	return t
+}
+
+// identityFromXUID returns the version 3 UUID that Minecraft derives from an XUID, as a string.
+func identityFromXUID(xuid string) string {
+	var id uuid.UUID
+	sum := md5.Sum([]byte("pocket-auth-1-xuid:" + xuid))
+	copy(id[:], sum[:])
+	id[6] = (id[6] & 0x0f) | 0x30
+	id[8] = (id[8] & 0x3f) | 0x80
+	return id.String()
+}
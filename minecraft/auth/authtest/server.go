@@ -0,0 +1,337 @@
+package authtest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sandertv/gophertunnel/minecraft/auth"
+	"golang.org/x/oauth2"
+)
+
+// Config holds the identity of the user that logs in through a Server, along with options that change the
+// behaviour of the Server. Fields left empty are filled with default values by NewServer.
+type Config struct {
+	// XUID is the XUID of the user. It defaults to 2535400000000001.
+	XUID string
+	// DisplayName is the gamertag of the user. It defaults to "authtest".
+	DisplayName string
+	// PlayFabID is the ID of the PlayFab master player account of the user. It defaults to a random ID.
+	PlayFabID string
+	// TitleID is the Xbox Live title ID claimed by the tokens issued, which defaults to the title ID of
+	// Minecraft for Android.
+	TitleID string
+	// DeviceAuthPolls is the number of polls of the Live token endpoint that are answered with
+	// authorization_pending before device auth succeeds, as if the user took some time to log in.
+	DeviceAuthPolls int
+}
+
+// Server is a local stand-in for the Microsoft, Xbox Live and Minecraft services used to authenticate with
+// Minecraft: Bedrock Edition. A Server must be created using NewServer and closed using Server.Close.
+type Server struct {
+	conf Config
+	srv  *httptest.Server
+	mux  *http.ServeMux
+	base *http.Transport
+
+	// userHash is the user hash claimed by Xbox Live user and XSTS tokens.
+	userHash string
+	// chainKey is the key that Minecraft chains are signed with, in place of the key of Mojang.
+	chainKey *ecdsa.PrivateKey
+	// oidcKey is the key that session and multiplayer tokens are signed with. It is published in the JWKS
+	// of the authorization service under the ID oidcKeyID.
+	oidcKey   *rsa.PrivateKey
+	oidcKeyID string
+
+	// pmid is the player messaging ID claimed by session tokens.
+	pmid string
+	// titlePlayerID is the ID of the PlayFab title player account of the user.
+	titlePlayerID string
+
+	// rogueEC and rogueRSA are keys that are not published, used to sign tokens when FaultInvalidSignature is
+	// set. They are generated by rogue when first needed.
+	rogueOnce sync.Once
+	rogueEC   *ecdsa.PrivateKey
+	rogueRSA  *rsa.PrivateKey
+
+	mu       sync.Mutex
+	faults   [endpointCount]Fault
+	requests [endpointCount]int
+	// issued holds all tokens issued by the Server, so that requests authorised with them may be checked.
+	issued map[string]issuedToken
+	// deviceCodes maps device codes issued to the number of times the token endpoint was polled with them.
+	deviceCodes map[string]int
+	// authCodes maps authorization codes issued to the PKCE challenge and redirect URI they were issued for.
+	authCodes map[string]authCode
+}
+
+// issuedToken is a token issued by a Server, which the Server accepts until it expires.
+type issuedToken struct {
+	// kind is the kind of the token, for example "live" or "xsts:<relying party>".
+	kind   string
+	expiry time.Time
+}
+
+// authCode is an authorization code issued by the Live authorization endpoint.
+type authCode struct {
+	challenge, redirectURI string
+}
+
+// tokenLifetime is the lifetime of most tokens issued by a Server.
+const tokenLifetime = time.Hour
+
+// NewServer starts and returns a new Server for the user described in the Config passed. The caller should
+// call Close when finished, to shut it down.
+func NewServer(conf Config) *Server {
+	if conf.XUID == "" {
+		conf.XUID = "2535400000000001"
+	}
+	if conf.DisplayName == "" {
+		conf.DisplayName = "authtest"
+	}
+	if conf.PlayFabID == "" {
+		conf.PlayFabID = strings.ToUpper(randomHex(8))
+	}
+	if conf.TitleID == "" {
+		conf.TitleID = "1739947436"
+	}
+	chainKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		panic("authtest: generate chain key: " + err.Error())
+	}
+	oidcKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic("authtest: generate OpenID key: " + err.Error())
+	}
+	s := &Server{
+		conf:          conf,
+		mux:           http.NewServeMux(),
+		base:          http.DefaultTransport.(*http.Transport).Clone(),
+		userHash:      randomDigits(19),
+		chainKey:      chainKey,
+		oidcKey:       oidcKey,
+		oidcKeyID:     randomHex(16),
+		pmid:          uuid.NewString(),
+		titlePlayerID: strings.ToUpper(randomHex(8)),
+		issued:        make(map[string]issuedToken),
+		deviceCodes:   make(map[string]int),
+		authCodes:     make(map[string]authCode),
+	}
+	s.handleLive()
+	s.handleXbox()
+	s.handleMinecraft()
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close shuts down the Server and closes the idle connections of clients returned by Client.
+func (s *Server) Close() {
+	s.srv.Close()
+	s.base.CloseIdleConnections()
+}
+
+// Client returns an *http.Client that sends requests to the hosts of the services emulated by the Server to
+// the Server, and requests to any other host to their actual destination.
+func (s *Server) Client() *http.Client {
+	return &http.Client{Transport: &transport{base: s.base, addr: s.srv.Listener.Addr().String()}}
+}
+
+// Context returns a copy of the parent context.Context passed holding the HTTP client returned by Client,
+// for use with the functions in the auth package, service.Discover and other functions that read the HTTP
+// client to use from their context.
+func (s *Server) Context(parent context.Context) context.Context {
+	return auth.WithContextClient(parent, s.Client())
+}
+
+// LiveToken issues a Microsoft Live Connect token for the user, as if the user logged in.
+func (s *Server) LiveToken() *oauth2.Token {
+	return s.liveToken()
+}
+
+// TokenSource returns an oauth2.TokenSource that returns a token issued by LiveToken and refreshes it using
+// the Server when it expires. It may be used as the TokenSource of a minecraft.Dialer.
+func (s *Server) TokenSource() oauth2.TokenSource {
+	return auth.AndroidConfig.TokenSource(s.Context(context.Background()), s.LiveToken())
+}
+
+// SetFault sets the Fault that an Endpoint produces for all subsequent requests. FaultNone may be passed to
+// restore normal behaviour.
+func (s *Server) SetFault(e Endpoint, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[e] = f
+}
+
+// Requests returns the number of requests made to an Endpoint since the Server was started.
+func (s *Server) Requests(e Endpoint) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[e]
+}
+
+// serveHTTP serves a request routed to the Server by the transport of a Client.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.Host, ".playfabapi.com") {
+		// PlayFab is served under a host per title, which cannot be matched by patterns.
+		r.Host = playFabHost
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// handle registers the handler passed for the pattern passed, producing the Fault set for the Endpoint
+// passed where the handler does not do so itself.
+func (s *Server) handle(pattern string, e Endpoint, h http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[e]++
+		f := s.faults[e]
+		s.mu.Unlock()
+
+		switch f {
+		case FaultUnavailable:
+			http.Error(w, "authtest: service unavailable", http.StatusServiceUnavailable)
+			return
+		case FaultMalformed:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"authtest":`)
+			return
+		}
+		h(w, r)
+	})
+}
+
+// fault returns the Fault currently set for an Endpoint.
+func (s *Server) fault(e Endpoint) Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.faults[e]
+}
+
+// expiry returns the expiry time for a token issued by the Endpoint passed, which lies in the past if the
+// Endpoint has FaultExpired set.
+func (s *Server) expiry(e Endpoint, lifetime time.Duration) time.Time {
+	if s.fault(e) == FaultExpired {
+		return time.Now().Add(-time.Hour)
+	}
+	return time.Now().Add(lifetime)
+}
+
+// issue issues a new random token of the kind passed, which is accepted by valid until the expiry time.
+func (s *Server) issue(kind string, expiry time.Time) string {
+	return s.register(randomHex(24), kind, expiry)
+}
+
+// register registers the token passed as a token of the kind passed, which is accepted by valid until the
+// expiry time. The token is returned.
+func (s *Server) register(token, kind string, expiry time.Time) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.issued[token] = issuedToken{kind: kind, expiry: expiry}
+	return token
+}
+
+// valid checks if the token passed was issued by the Server as a token of the kind passed and has not yet
+// expired.
+func (s *Server) valid(token, kind string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.issued[token]
+	return ok && t.kind == kind && time.Now().Before(t.expiry)
+}
+
+// rogue returns the keys used to sign tokens with signatures that cannot be verified.
+func (s *Server) rogue() (*ecdsa.PrivateKey, *rsa.PrivateKey) {
+	s.rogueOnce.Do(func() {
+		s.rogueEC, _ = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		s.rogueRSA, _ = rsa.GenerateKey(rand.Reader, 2048)
+	})
+	return s.rogueEC, s.rogueRSA
+}
+
+// writeJSON writes the value passed as JSON response with the status code passed.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// readJSON decodes the JSON request body of the request passed into v. If decoding fails, a 400 Bad Request
+// response is written and false is returned.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(v); err != nil {
+		http.Error(w, "authtest: decode request body: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// randomHex returns a random hex string of n random bytes.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// randomDigits returns a random string of n decimal digits.
+func randomDigits(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	for i := range b {
+		b[i] = '0' + b[i]%10
+	}
+	return string(b)
+}
+
+// transport is the http.RoundTripper of a Client. It routes requests to the hosts served by a Server to the
+// address of the Server.
+type transport struct {
+	base http.RoundTripper
+	addr string
+}
+
+// RoundTrip sends the request passed to the Server if its host is served by it, or to its actual
+// destination otherwise.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Hostname()
+	if _, ok := hosts[host]; !ok && !strings.HasSuffix(host, ".playfabapi.com") {
+		return t.base.RoundTrip(req)
+	}
+	req2 := req.Clone(req.Context())
+	req2.URL.Scheme, req2.URL.Host, req2.Host = "http", t.addr, host
+	return t.base.RoundTrip(req2)
+}
+
+const (
+	liveHost        = "login.live.com"
+	deviceHost      = "device.auth.xboxlive.com"
+	sisuHost        = "sisu.xboxlive.com"
+	xstsHost        = "xsts.auth.xboxlive.com"
+	titleHost       = "title.mgt.xboxlive.com"
+	chainHost       = "multiplayer.minecraft.net"
+	discoveryHost   = "client.discovery.minecraft-services.net"
+	authServiceHost = "authorization.franchise.minecraft-services.net"
+	playFabHost     = "playfabapi.com"
+)
+
+// hosts holds the hosts served by a Server. Apart from these, all subdomains of playfabapi.com are served.
+var hosts = map[string]struct{}{
+	liveHost:        {},
+	deviceHost:      {},
+	sisuHost:        {},
+	xstsHost:        {},
+	titleHost:       {},
+	chainHost:       {},
+	discoveryHost:   {},
+	authServiceHost: {},
+}
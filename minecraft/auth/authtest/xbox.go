@@ -0,0 +1,199 @@
+package authtest
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/df-mc/go-xsapi/v2/xal/nsal"
+	"github.com/df-mc/go-xsapi/v2/xal/xasd"
+	"github.com/df-mc/go-xsapi/v2/xal/xast"
+	"github.com/df-mc/go-xsapi/v2/xal/xasu"
+	"github.com/df-mc/go-xsapi/v2/xal/xsts"
+)
+
+const (
+	// xboxLiveRelyingParty is the relying party of the XSTS token issued by SISU, used for most Xbox Live
+	// services.
+	xboxLiveRelyingParty = "http://xboxlive.com"
+	// chainRelyingParty is the relying party of XSTS tokens accepted by the Minecraft chain endpoint.
+	chainRelyingParty = "https://multiplayer.minecraft.net/"
+	// playFabRelyingParty is the relying party of XSTS tokens accepted by PlayFab.
+	playFabRelyingParty = "http://playfab.xboxlive.com/"
+	// realmsRelyingParty is the relying party of XSTS tokens accepted by Minecraft Realms.
+	realmsRelyingParty = "https://pocket.realms.minecraft.net/"
+)
+
+// handleXbox registers the handlers of the Xbox Live authentication and NSAL endpoints.
+func (s *Server) handleXbox() {
+	s.handle("POST "+deviceHost+"/device/authenticate", EndpointDeviceToken, s.serveDeviceToken)
+	s.handle("POST "+sisuHost+"/authorize", EndpointSISU, s.serveSISU)
+	s.handle("POST "+xstsHost+"/xsts/authorize", EndpointXSTS, s.serveXSTS)
+	s.handle("GET "+titleHost+"/titles/default/endpoints", EndpointTitleData, s.serveDefaultTitle)
+	s.handle("GET "+titleHost+"/titles/{title}/endpoints", EndpointTitleData, s.serveTitle)
+}
+
+// serveDeviceToken issues an Xbox Live device token.
+func (s *Server) serveDeviceToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RelyingParty string
+		Properties   struct {
+			ProofKey map[string]any
+		}
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if r.Header.Get("Signature") == "" || req.Properties.ProofKey == nil {
+		http.Error(w, "authtest: device token requests must be signed with a proof key", http.StatusUnauthorized)
+		return
+	}
+	expiry := s.expiry(EndpointDeviceToken, 14*24*time.Hour)
+	writeJSON(w, http.StatusOK, &xasd.Token{
+		IssueInstant: time.Now(),
+		NotAfter:     expiry,
+		Token:        s.issue("device", expiry),
+		DisplayClaims: xasd.DisplayClaims{
+			DeviceInfo: xasd.DeviceInfo{DeviceID: strings.ToUpper(randomHex(8)), DCS: "0"},
+		},
+	})
+}
+
+// serveSISU serves a SISU authorization request, issuing a title token, user token and an XSTS token for
+// the relying party http://xboxlive.com.
+func (s *Server) serveSISU(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AccessToken  string
+		DeviceToken  string
+		RelyingParty string
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if r.Header.Get("Signature") == "" {
+		http.Error(w, "authtest: SISU requests must be signed", http.StatusUnauthorized)
+		return
+	}
+	if !s.valid(strings.TrimPrefix(req.AccessToken, "t="), "live") || !s.valid(req.DeviceToken, "device") {
+		http.Error(w, "authtest: invalid access token or device token", http.StatusUnauthorized)
+		return
+	}
+	expiry := s.expiry(EndpointSISU, tokenLifetime)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"TitleToken": &xast.Token{
+			IssueInstant:  time.Now(),
+			NotAfter:      expiry,
+			Token:         s.issue("title", expiry),
+			DisplayClaims: xast.DisplayClaims{TitleInfo: xast.TitleInfo{TitleID: s.conf.TitleID}},
+		},
+		"UserToken": &xasu.Token{
+			IssueInstant:  time.Now(),
+			NotAfter:      expiry,
+			Token:         s.issue("user", expiry),
+			DisplayClaims: xasu.DisplayClaims{UserInfo: []xasu.UserInfo{{UserHash: s.userHash}}},
+		},
+		"AuthorizationToken": s.xstsToken(xboxLiveRelyingParty, expiry),
+	})
+}
+
+// serveXSTS issues an XSTS token for the relying party requested.
+func (s *Server) serveXSTS(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RelyingParty string
+		Properties   struct {
+			DeviceToken string
+			TitleToken  string
+			UserTokens  []string
+		}
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if r.Header.Get("Signature") == "" {
+		http.Error(w, "authtest: XSTS requests must be signed", http.StatusUnauthorized)
+		return
+	}
+	if len(req.Properties.UserTokens) != 1 || !s.valid(req.Properties.UserTokens[0], "user") ||
+		(req.Properties.DeviceToken != "" && !s.valid(req.Properties.DeviceToken, "device")) ||
+		(req.Properties.TitleToken != "" && !s.valid(req.Properties.TitleToken, "title")) {
+		http.Error(w, "authtest: invalid underlying tokens", http.StatusUnauthorized)
+		return
+	}
+	if req.RelyingParty == "" {
+		http.Error(w, "authtest: relying party is missing", http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.xstsToken(req.RelyingParty, s.expiry(EndpointXSTS, tokenLifetime)))
+}
+
+// xstsToken issues an XSTS token for the relying party passed. Only tokens for the relying party
+// http://xboxlive.com claim the XUID and gamertag of the user.
+func (s *Server) xstsToken(relyingParty string, expiry time.Time) *xsts.Token {
+	info := xsts.UserInfo{UserInfo: xasu.UserInfo{UserHash: s.userHash}}
+	if relyingParty == xboxLiveRelyingParty {
+		info.XUID, info.GamerTag = s.conf.XUID, s.conf.DisplayName
+	}
+	return &xsts.Token{
+		IssueInstant:  time.Now(),
+		NotAfter:      expiry,
+		Token:         s.issue("xsts:"+relyingParty, expiry),
+		DisplayClaims: xsts.DisplayClaims{UserInfo: []xsts.UserInfo{info}},
+	}
+}
+
+// xblAuthorized checks if the request passed has an Authorization header holding an XSTS token issued for
+// the relying party passed.
+func (s *Server) xblAuthorized(r *http.Request, relyingParty string) bool {
+	return s.validXBLToken(r.Header.Get("Authorization"), relyingParty)
+}
+
+// validXBLToken checks if the value passed, of the form 'XBL3.0 x=<user hash>;<token>', holds an XSTS token
+// issued for the relying party passed.
+func (s *Server) validXBLToken(v, relyingParty string) bool {
+	userHash, token, ok := strings.Cut(strings.TrimPrefix(v, "XBL3.0 x="), ";")
+	return ok && userHash == s.userHash && s.valid(token, "xsts:"+relyingParty)
+}
+
+// serveDefaultTitle serves the NSAL title data of the default title, which covers the Xbox Live services.
+func (s *Server) serveDefaultTitle(w http.ResponseWriter, _ *http.Request) {
+	policy := 0
+	writeJSON(w, http.StatusOK, &nsal.TitleData{
+		Endpoints: []nsal.Endpoint{{
+			Protocol:             "https",
+			Host:                 "*.xboxlive.com",
+			HostType:             nsal.HostTypeWildcard,
+			RelyingParty:         xboxLiveRelyingParty,
+			TokenType:            "JWT",
+			SignaturePolicyIndex: &policy,
+		}},
+		SignaturePolicies: []nsal.SignaturePolicy{{Version: 1, SupportedAlgorithms: []string{"ES256"}, MaxBodyBytes: 8192}},
+	})
+}
+
+// serveTitle serves the NSAL title data of the current title, which covers the Minecraft services. Any
+// title ID is treated as the current title.
+func (s *Server) serveTitle(w http.ResponseWriter, r *http.Request) {
+	if !s.xblAuthorized(r, xboxLiveRelyingParty) {
+		http.Error(w, "authtest: title data requests require an XSTS token for http://xboxlive.com", http.StatusUnauthorized)
+		return
+	}
+	policy := 0
+	endpoint := func(host, hostType, relyingParty string) nsal.Endpoint {
+		return nsal.Endpoint{
+			Protocol:             "https",
+			Host:                 host,
+			HostType:             hostType,
+			RelyingParty:         relyingParty,
+			TokenType:            "JWT",
+			SignaturePolicyIndex: &policy,
+		}
+	}
+	writeJSON(w, http.StatusOK, &nsal.TitleData{
+		Endpoints: []nsal.Endpoint{
+			endpoint(chainHost, nsal.HostTypeFQDN, chainRelyingParty),
+			endpoint("*.playfabapi.com", nsal.HostTypeWildcard, playFabRelyingParty),
+			endpoint("pocket.realms.minecraft.net", nsal.HostTypeFQDN, realmsRelyingParty),
+		},
+		SignaturePolicies: []nsal.SignaturePolicy{{Version: 1, SupportedAlgorithms: []string{"ES256"}, MaxBodyBytes: 8192}},
+	})
+}
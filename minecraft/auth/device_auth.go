@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/microsoft"
+)
+
+// ErrDeviceCodeExpired is returned by RequestLiveTokenDeviceAuth if the user did not log in before the device
+// code expired.
+var ErrDeviceCodeExpired = errors.New("auth: device code expired")
+
+// DeviceCode holds the information that a user needs to log in using device auth.
+type DeviceCode struct {
+	// UserCode is the code that the user must enter at the VerificationURI.
+	UserCode string
+	// VerificationURI is the URI at which the user must log in and enter the UserCode.
+	VerificationURI string
+	// VerificationURIComplete is the VerificationURI with the UserCode already filled in. It may be empty.
+	VerificationURIComplete string
+	// Expiry is the time at which the UserCode expires.
+	Expiry time.Time
+	// Interval is the interval at which the token endpoint is polled while waiting for the user to log in.
+	Interval time.Duration
+}
+
+// DeviceAuthEvent is an event in the progress of a login request using device auth.
+type DeviceAuthEvent int
+
+const (
+	// DeviceAuthPending is the event sent after every poll of the token endpoint while the user has not
+	// logged in yet.
+	DeviceAuthPending DeviceAuthEvent = iota
+	// DeviceAuthSlowDown is the event sent when the token endpoint requests polling less frequently. The
+	// polling interval is increased by five seconds.
+	DeviceAuthSlowDown
+	// DeviceAuthExpired is the event sent when the device code expires before the user logged in.
+	DeviceAuthExpired
+	// DeviceAuthSuccess is the event sent when the user logged in and a token was obtained.
+	DeviceAuthSuccess
+)
+
+// String ...
+func (e DeviceAuthEvent) String() string {
+	switch e {
+	case DeviceAuthPending:
+		return "pending"
+	case DeviceAuthSlowDown:
+		return "slow_down"
+	case DeviceAuthExpired:
+		return "expired"
+	case DeviceAuthSuccess:
+		return "success"
+	}
+	return fmt.Sprintf("DeviceAuthEvent(%d)", int(e))
+}
+
+// DeviceAuth holds the callbacks called during a login request using device auth, as done by
+// RequestLiveTokenDeviceAuth. Either of the functions may be nil.
+type DeviceAuth struct {
+	// Code is called with the DeviceCode once it is obtained. The user must be shown the code and URI in it
+	// to log in.
+	Code func(code DeviceCode)
+	// Progress is called with every DeviceAuthEvent while waiting for the user to log in. The DeviceCode
+	// passed holds the current polling interval.
+	Progress func(event DeviceAuthEvent, code DeviceCode)
+}
+
+// RequestLiveTokenDeviceAuth does a login request for Microsoft Live Connect using device auth. The device
+// code that the user must enter is passed to the Code function of the DeviceAuth, after which the token
+// endpoint is polled until the user logs in, the code expires or the context passed is cancelled.
+// Once fully authenticated, an oauth2 token is returned which may be used to login to XBOX Live.
+func RequestLiveTokenDeviceAuth(ctx context.Context, h DeviceAuth) (*oauth2.Token, error) {
+	return AndroidConfig.RequestLiveTokenDeviceAuth(ctx, h)
+}
+
+// RequestLiveTokenDeviceAuth does a login request for Microsoft Live Connect using device auth. The device
+// code that the user must enter is passed to the Code function of the DeviceAuth, after which the token
+// endpoint is polled until the user logs in, the code expires or the context passed is cancelled.
+// Once fully authenticated, an oauth2 token is returned which may be used to login to XBOX Live.
+func (conf Config) RequestLiveTokenDeviceAuth(ctx context.Context, h DeviceAuth) (*oauth2.Token, error) {
+	d, err := conf.DeviceAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("start device auth: %w", err)
+	}
+	code := DeviceCode{
+		UserCode:                d.UserCode,
+		VerificationURI:         d.VerificationURI,
+		VerificationURIComplete: d.VerificationURIComplete,
+		Expiry:                  d.Expiry,
+		Interval:                time.Duration(d.Interval) * time.Second,
+	}
+	if code.Interval <= 0 {
+		code.Interval = 5 * time.Second
+	}
+	if h.Code != nil {
+		h.Code(code)
+	}
+	progress := func(e DeviceAuthEvent) {
+		if h.Progress != nil {
+			h.Progress(e, code)
+		}
+	}
+
+	timer := time.NewTimer(code.Interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("poll device token: %w", ctx.Err())
+		case <-timer.C:
+		}
+		t, status, err := conf.pollDeviceToken(ctx, d.DeviceCode)
+		if err != nil {
+			return nil, fmt.Errorf("poll device token: %w", err)
+		}
+		switch status {
+		case "":
+			progress(DeviceAuthSuccess)
+			return t, nil
+		case "authorization_pending":
+			if !code.Expiry.IsZero() && time.Now().After(code.Expiry) {
+				progress(DeviceAuthExpired)
+				return nil, ErrDeviceCodeExpired
+			}
+			progress(DeviceAuthPending)
+		case "slow_down":
+			code.Interval += 5 * time.Second
+			progress(DeviceAuthSlowDown)
+		case "expired_token":
+			progress(DeviceAuthExpired)
+			return nil, ErrDeviceCodeExpired
+		}
+		timer.Reset(code.Interval)
+	}
+}
+
+// pollDeviceToken polls the token endpoint for the token of the device code passed. If the user has not yet
+// logged in, the error code returned by the endpoint is returned as status, which is either
+// authorization_pending, slow_down or expired_token. Other errors returned by the endpoint are returned as
+// an *oauth2.RetrieveError.
+func (conf Config) pollDeviceToken(ctx context.Context, deviceCode string) (t *oauth2.Token, status string, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, microsoft.LiveConnectEndpoint.TokenURL, strings.NewReader(url.Values{
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"device_code": {deviceCode},
+		"client_id":   {conf.ClientID},
+	}.Encode()))
+	if err != nil {
+		return nil, "", fmt.Errorf("make request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", conf.UserAgent)
+	resp, err := ContextClient(ctx).Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, "", fmt.Errorf("read response body: %w", err)
+	}
+
+	var data struct {
+		AccessToken      string `json:"access_token"`
+		TokenType        string `json:"token_type"`
+		RefreshToken     string `json:"refresh_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		ErrorCode        string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+		ErrorURI         string `json:"error_uri"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, "", fmt.Errorf("decode response body: %w", err)
+	}
+	switch data.ErrorCode {
+	case "authorization_pending", "slow_down", "expired_token":
+		return nil, data.ErrorCode, nil
+	}
+	if resp.StatusCode != http.StatusOK || data.ErrorCode != "" {
+		return nil, "", &oauth2.RetrieveError{
+			Response:         resp,
+			Body:             body,
+			ErrorCode:        data.ErrorCode,
+			ErrorDescription: data.ErrorDescription,
+			ErrorURI:         data.ErrorURI,
+		}
+	}
+	if data.AccessToken == "" {
+		return nil, "", errors.New("server response missing access_token")
+	}
+	t = &oauth2.Token{
+		AccessToken:  data.AccessToken,
+		TokenType:    data.TokenType,
+		RefreshToken: data.RefreshToken,
+		ExpiresIn:    data.ExpiresIn,
+	}
+	if data.ExpiresIn > 0 {
+		t.Expiry = time.Now().Add(time.Duration(data.ExpiresIn) * time.Second)
+	}
+	var raw map[string]any
+	_ = json.Unmarshal(body, &raw)
+	return t.WithExtra(raw), "", nil
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// FileTokenStore is a TokenStore that stores every key in its own file in a directory, encrypted using
+// AES-GCM with a key supplied by the user. Files are replaced atomically when saved and are locked while
+// they are read or written, so that multiple processes may share the same directory.
+type FileTokenStore struct {
+	dir  string
+	aead cipher.AEAD
+	// mu serialises the access to files within the process, as file locks are held per process on some
+	// platforms.
+	mu sync.Mutex
+}
+
+// fileStoreMagic is the header of every file written by a FileTokenStore, followed by the nonce and the
+// encrypted data.
+var fileStoreMagic = []byte("GTTS\x01")
+
+// validStoreKey matches the keys that may be used in a FileTokenStore.
+var validStoreKey = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// NewFileTokenStore returns a FileTokenStore that stores tokens in the directory passed, creating it if it
+// does not exist. The key passed is used to encrypt the tokens and must be 16, 24 or 32 bytes long, to use
+// AES-128, AES-192 or AES-256 respectively. The same key must be passed to read the tokens back.
+func NewFileTokenStore(dir string, key []byte) (*FileTokenStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("create token directory: %w", err)
+	}
+	return &FileTokenStore{dir: dir, aead: aead}, nil
+}
+
+// Load loads and decrypts the data stored under the key passed. ErrTokenNotFound is returned if no data was
+// stored under the key.
+func (s *FileTokenStore) Load(key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := lockFile(path+".lock", false)
+	if err != nil {
+		return nil, fmt.Errorf("lock token file: %w", err)
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrTokenNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("read token file: %w", err)
+	}
+	if !bytes.HasPrefix(data, fileStoreMagic) || len(data) < len(fileStoreMagic)+s.aead.NonceSize() {
+		return nil, fmt.Errorf("read token file %v: not a token file", path)
+	}
+	data = data[len(fileStoreMagic):]
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt token file %v: wrong key or corrupted file", path)
+	}
+	return plaintext, nil
+}
+
+// Save encrypts the data passed and stores it under the key passed, replacing the data previously stored.
+// The data is written to a temporary file first, which then replaces the old file, so that the old data
+// remains intact if writing fails.
+func (s *FileTokenStore) Save(key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("generate nonce: %w", err)
+	}
+	buf := append(append(append([]byte(nil), fileStoreMagic...), nonce...), s.aead.Seal(nil, nonce, data, []byte(key))...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := lockFile(path+".lock", true)
+	if err != nil {
+		return fmt.Errorf("lock token file: %w", err)
+	}
+	defer unlock()
+
+	f, err := os.CreateTemp(s.dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create token file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(buf); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write token file: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("sync token file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close token file: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("replace token file: %w", err)
+	}
+	return nil
+}
+
+// path returns the path of the file that data stored under a key is stored in.
+func (s *FileTokenStore) path(key string) (string, error) {
+	if !validStoreKey.MatchString(key) {
+		return "", fmt.Errorf("invalid token key %q: must only contain letters, digits, '_', '.' and '-'", key)
+	}
+	return filepath.Join(s.dir, key+".token"), nil
+}
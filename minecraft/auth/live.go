@@ -136,17 +136,14 @@ func RequestLiveTokenContext(ctx context.Context, w io.Writer) (*oauth2.Token, e
 // Once fully authenticated, an oauth2 token is returned which may be used to login to XBOX Live.
 // The context is used to control the deadline for polling the OAuth2 device authorization endpoint.
 func (conf Config) RequestLiveTokenContext(ctx context.Context, w io.Writer) (*oauth2.Token, error) {
-	d, err := conf.DeviceAuth(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("start device auth: %w", err)
-	}
-
-	_, _ = fmt.Fprintf(w, "Authenticate at %v using the code %v.\n", d.VerificationURI, d.UserCode)
-
-	token, err := conf.DeviceAccessToken(ctx, d)
-	if err != nil {
-		return nil, fmt.Errorf("poll device token: %w", err)
-	}
-	_, _ = w.Write([]byte("Authentication successful.\n"))
-	return token, nil
+	return conf.RequestLiveTokenDeviceAuth(ctx, DeviceAuth{
+		Code: func(code DeviceCode) {
+			_, _ = fmt.Fprintf(w, "Authenticate at %v using the code %v.\n", code.VerificationURI, code.UserCode)
+		},
+		Progress: func(event DeviceAuthEvent, _ DeviceCode) {
+			if event == DeviceAuthSuccess {
+				_, _ = w.Write([]byte("Authentication successful.\n"))
+			}
+		},
+	})
 }
@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package auth
+
+// lockFile does nothing on platforms without file locking. Files are then only protected from concurrent
+// access within the same process.
+func lockFile(string, bool) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/df-mc/go-xsapi/v2/xal/sisu"
+	"github.com/df-mc/go-xsapi/v2/xal/xasd"
+	"golang.org/x/oauth2"
+)
+
+// ErrTokenNotFound is returned by a TokenStore if no data is stored under the key passed to Load.
+var ErrTokenNotFound = errors.New("auth: token not found")
+
+// TokenStore stores tokens so that they may be reused after a restart. Data is stored under a key, which
+// allows a single TokenStore to hold the tokens of multiple accounts. An implementation that stores tokens
+// in encrypted files is provided by FileTokenStore.
+type TokenStore interface {
+	// Load loads the data stored under the key passed. If no data is stored under the key,
+	// ErrTokenNotFound is returned.
+	Load(key string) ([]byte, error)
+	// Save stores the data passed under the key passed, replacing any data previously stored under it.
+	Save(key string, data []byte) error
+}
+
+// LoadToken loads an oauth2.Token stored under the key passed in a TokenStore. ErrTokenNotFound is returned
+// if no token was stored under the key.
+func LoadToken(store TokenStore, key string) (*oauth2.Token, error) {
+	data, err := store.Load(key)
+	if err != nil {
+		return nil, err
+	}
+	t := new(oauth2.Token)
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("decode token: %w", err)
+	}
+	return t, nil
+}
+
+// SaveToken stores the oauth2.Token passed under the key passed in a TokenStore.
+func SaveToken(store TokenStore, key string, t *oauth2.Token) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("encode token: %w", err)
+	}
+	return store.Save(key, data)
+}
+
+// SaveTokenSource returns an oauth2.TokenSource that returns the tokens of the oauth2.TokenSource passed and
+// stores every token in the TokenStore under the key passed when it is refreshed. If the token could not be
+// saved, Token returns the error.
+func SaveTokenSource(store TokenStore, key string, src oauth2.TokenSource) oauth2.TokenSource {
+	return &storeTokenSource{store: store, key: key, src: src}
+}
+
+// StoreTokenSource returns an oauth2.TokenSource that loads the token stored under the key passed in the
+// TokenStore and refreshes it when it expires, storing every refreshed token back under the same key. If no
+// token is stored yet, or the token stored can no longer be refreshed, device auth is used to request a
+// new one, printing the auth URL and code to the io.Writer passed.
+func StoreTokenSource(store TokenStore, key string, w io.Writer) (oauth2.TokenSource, error) {
+	return AndroidConfig.StoreTokenSource(store, key, w)
+}
+
+// StoreTokenSource returns an oauth2.TokenSource that loads the token stored under the key passed in the
+// TokenStore and refreshes it when it expires, storing every refreshed token back under the same key. If no
+// token is stored yet, or the token stored can no longer be refreshed, device auth is used to request a
+// new one, printing the auth URL and code to the io.Writer passed.
+func (conf Config) StoreTokenSource(store TokenStore, key string, w io.Writer) (oauth2.TokenSource, error) {
+	t, err := LoadToken(store, key)
+	if err != nil && !errors.Is(err, ErrTokenNotFound) {
+		return nil, fmt.Errorf("load token: %w", err)
+	}
+	src := &storeTokenSource{store: store, key: key, src: conf.RefreshTokenSourceWriter(t, w)}
+	if t != nil {
+		src.access, src.refresh = t.AccessToken, t.RefreshToken
+	}
+	return src, nil
+}
+
+// storeTokenSource implements the oauth2.TokenSource interface. It saves every token returned by src that
+// differs from the token last saved to a TokenStore.
+type storeTokenSource struct {
+	store TokenStore
+	key   string
+	src   oauth2.TokenSource
+
+	mu              sync.Mutex
+	access, refresh string
+}
+
+// Token returns a token from the underlying oauth2.TokenSource and stores it if it was refreshed.
+func (src *storeTokenSource) Token() (*oauth2.Token, error) {
+	t, err := src.src.Token()
+	if err != nil {
+		return nil, err
+	}
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	if t.AccessToken == src.access && t.RefreshToken == src.refresh {
+		return t, nil
+	}
+	if err := SaveToken(src.store, src.key, t); err != nil {
+		return nil, fmt.Errorf("save token: %w", err)
+	}
+	src.access, src.refresh = t.AccessToken, t.RefreshToken
+	return t, nil
+}
+
+// storedTokenCache is the form in which an XBLTokenCache is stored in a TokenStore.
+type storedTokenCache struct {
+	// ProofKey is the ASN.1 DER encoded EC private key that the device token is bound to.
+	ProofKey []byte
+	// DeviceToken is the device token of the cache.
+	DeviceToken *xasd.Token
+	// Snapshot holds the tokens of the SISU session of the cache, if it had one.
+	Snapshot *sisu.Snapshot `json:",omitempty"`
+}
+
+// Save stores the device token and proof key of the XBLTokenCache, along with the tokens of its SISU
+// session, under the key passed in a TokenStore. The cache may be restored using LoadTokenCache. If the
+// cache has no device token yet, one is requested using the context.Context passed.
+func (cache *XBLTokenCache) Save(ctx context.Context, store TokenStore, key string) error {
+	device := cache.Device()
+	t, err := device.DeviceToken(ctx)
+	if err != nil {
+		return fmt.Errorf("request device token: %w", err)
+	}
+	proofKey, err := x509.MarshalECPrivateKey(device.ProofKey())
+	if err != nil {
+		return fmt.Errorf("encode proof key: %w", err)
+	}
+	stored := storedTokenCache{ProofKey: proofKey, DeviceToken: t}
+	if s := cache.Session(); s != nil {
+		stored.Snapshot = s.Snapshot()
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("encode token cache: %w", err)
+	}
+	return store.Save(key, data)
+}
+
+// LoadTokenCache loads an XBLTokenCache stored under the key passed in a TokenStore using
+// XBLTokenCache.Save. If no cache was stored under the key, a new XBLTokenCache is returned, as
+// returned by Config.NewTokenCache.
+func LoadTokenCache(store TokenStore, key string) (*XBLTokenCache, error) {
+	return AndroidConfig.LoadTokenCache(store, key)
+}
+
+// LoadTokenCache loads an XBLTokenCache stored under the key passed in a TokenStore using
+// XBLTokenCache.Save. If no cache was stored under the key, a new XBLTokenCache is returned, as
+// returned by Config.NewTokenCache.
+func (conf Config) LoadTokenCache(store TokenStore, key string) (*XBLTokenCache, error) {
+	data, err := store.Load(key)
+	if errors.Is(err, ErrTokenNotFound) {
+		return conf.NewTokenCache(), nil
+	} else if err != nil {
+		return nil, err
+	}
+	var stored storedTokenCache
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("decode token cache: %w", err)
+	}
+	proofKey, err := x509.ParseECPrivateKey(stored.ProofKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode proof key: %w", err)
+	}
+	return &XBLTokenCache{
+		conf:     conf,
+		device:   xasd.ReuseTokenSource(conf.Config.Config, stored.DeviceToken, proofKey),
+		snapshot: stored.Snapshot,
+	}, nil
+}
@@ -46,6 +46,9 @@ type XBLTokenCache struct {
 	conf Config
 	// device is only present if the [XBLTokenCache] was created from [Config.NewTokenCache].
 	device xasd.TokenSource
+	// snapshot is the snapshot of a stored SISU session that the session is created from. It is only
+	// present if the [XBLTokenCache] was loaded using [Config.LoadTokenCache].
+	snapshot *sisu.Snapshot
 	// session is the SISU session cached by the [XBLTokenCache].
 	session *sisu.Session
 	// sessionMu guards session from concurrent read/write access.
@@ -80,6 +83,7 @@ func ContextSession(ctx context.Context, src oauth2.TokenSource) *sisu.Session {
 		if cache.session == nil {
 			cache.session = cache.conf.New(src, &sisu.SessionConfig{
 				DeviceTokenSource: cache.device,
+				Snapshot:          cache.snapshot,
 				HTTPClient:        ContextClient(ctx),
 			})
 		}
@@ -290,6 +294,7 @@ func (conf Config) RequestXBLToken(ctx context.Context, liveToken *oauth2.Token,
 		if cache.session == nil {
 			cache.session = conf.New(conf.TokenSource(context.WithoutCancel(ctx), liveToken), &sisu.SessionConfig{
 				DeviceTokenSource: cache.device,
+				Snapshot:          cache.snapshot,
 				HTTPClient:        ContextClient(ctx),
 			})
 		}
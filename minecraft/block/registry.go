@@ -0,0 +1,129 @@
+// Package block implements a registry of block states, which maps the name and properties of a block to the
+// network ID used for it in packets and sub-chunks, and the other way around.
+//
+// The game identifies blocks over network in one of two ways, depending on the UseBlockNetworkIDHashes field
+// of the GameData of a connection: Either by the index of the block state in the palette of all states sorted
+// by the hash of their names, or by a hash of the name and properties of the state. A Registry supports
+// both.
+//
+// The canonical block states of the game version implemented by the protocol package are embedded in the
+// package: DefaultRegistry creates a Registry using them, while NewRegistry accepts the states of any game
+// version.
+package block
+
+import (
+	"hash/fnv"
+	"slices"
+
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// Registry holds every block state known to a connection and maps them to network IDs, both ways. A Registry
+// is not modified after it is created, so it is safe for concurrent use.
+type Registry struct {
+	hashes bool
+	// states holds every block state ordered the way the game orders its block palette.
+	states []State
+	// indices maps the network hash of every state to its index in states.
+	indices map[uint32]uint32
+}
+
+// NewRegistry creates a Registry of the vanilla block states passed, which are generally obtained using
+// VanillaStates or ReadStates, and the custom blocks passed, which are found in the StartGame packet. If hashes is true,
+// network IDs are the hashes returned by NetworkHash, otherwise network IDs are indices into the block
+// palette, which holds all states stable-sorted by the 64-bit FNV-1 hash of their names.
+func NewRegistry(vanilla []State, custom []protocol.BlockEntry, hashes bool) *Registry {
+	states := slices.Clone(vanilla)
+	for _, entry := range custom {
+		states = append(states, customStates(entry)...)
+	}
+	nameHashes := make(map[string]uint64)
+	for _, s := range states {
+		if _, ok := nameHashes[s.Name]; !ok {
+			h := fnv.New64()
+			_, _ = h.Write([]byte(s.Name))
+			nameHashes[s.Name] = h.Sum64()
+		}
+	}
+	slices.SortStableFunc(states, func(a, b State) int {
+		ha, hb := nameHashes[a.Name], nameHashes[b.Name]
+		switch {
+		case ha < hb:
+			return -1
+		case ha > hb:
+			return 1
+		}
+		return 0
+	})
+
+	r := &Registry{hashes: hashes, states: states, indices: make(map[uint32]uint32, len(states))}
+	for i, s := range states {
+		h := NetworkHash(s.Name, s.Properties)
+		if _, ok := r.indices[h]; !ok {
+			r.indices[h] = uint32(i)
+		}
+	}
+	return r
+}
+
+// NewRegistryFromGameData creates a Registry of the vanilla block states passed and the custom blocks in the
+// GameData passed, using network ID hashes if the GameData specifies so. It is generally called with the
+// GameData of a minecraft.Conn after it has spawned.
+func NewRegistryFromGameData(vanilla []State, data minecraft.GameData) *Registry {
+	return NewRegistry(vanilla, data.CustomBlocks, data.UseBlockNetworkIDHashes)
+}
+
+// UsesHashes returns true if the network IDs of the Registry are hashes of block states rather than indices
+// into the block palette.
+func (r *Registry) UsesHashes() bool {
+	return r.hashes
+}
+
+// Len returns the number of block states in the Registry.
+func (r *Registry) Len() int {
+	return len(r.states)
+}
+
+// States returns all block states in the Registry in the order of the block palette. The slice returned must
+// not be modified.
+func (r *Registry) States() []State {
+	return r.states
+}
+
+// NetworkID returns the network ID of the block state with the name and properties passed. Property values
+// may also be passed as bool or int values, which are converted to the types used in block state NBT. False
+// is returned if the Registry does not hold the state.
+func (r *Registry) NetworkID(name string, properties map[string]any) (uint32, bool) {
+	h := NetworkHash(name, properties)
+	index, ok := r.indices[h]
+	if !ok || r.states[index].Name != name {
+		return 0, false
+	}
+	if r.hashes {
+		return h, true
+	}
+	return index, true
+}
+
+// State returns the block state with the network ID passed, such as one found in a packet.UpdateBlock or a
+// sub-chunk. False is returned if no state has the network ID.
+func (r *Registry) State(networkID uint32) (State, bool) {
+	index := networkID
+	if r.hashes {
+		var ok bool
+		if index, ok = r.indices[networkID]; !ok {
+			return State{}, false
+		}
+	}
+	if index >= uint32(len(r.states)) {
+		return State{}, false
+	}
+	return r.states[index], true
+}
+
+// Air returns the network ID of minecraft:air, which is the block used for empty positions in sub-chunks.
+func (r *Registry) Air() uint32 {
+	id, _ := r.NetworkID("minecraft:air", nil)
+	return id
+}
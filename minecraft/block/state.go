@@ -0,0 +1,179 @@
+package block
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// State is a single block state: A block name combined with a value for every property of that block, such
+// as minecraft:stone_stairs with upside_down_bit=1 and weirdo_direction=2.
+type State struct {
+	// Name is the name of the block, such as 'minecraft:stone_stairs'.
+	Name string `nbt:"name"`
+	// Properties holds the value of every property of the block. Values are of the types found in the block
+	// state NBT: byte for boolean properties, int32 for integer properties and string for enum properties.
+	Properties map[string]any `nbt:"states"`
+	// Version is the block state version the state was defined in. It is not part of the identity of the
+	// state.
+	Version int32 `nbt:"version"`
+}
+
+// String ...
+func (s State) String() string {
+	if len(s.Properties) == 0 {
+		return s.Name
+	}
+	buf := bytes.NewBufferString(s.Name)
+	buf.WriteByte('[')
+	for i, k := range slices.Sorted(maps.Keys(s.Properties)) {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		_, _ = fmt.Fprintf(buf, "%v=%v", k, s.Properties[k])
+	}
+	buf.WriteByte(']')
+	return buf.String()
+}
+
+// ReadStates reads a list of block states from r in the format of the canonical block state list of the
+// game: A sequence of compound tags encoded with nbt.NetworkLittleEndian, each holding the name, states and
+// version of one block state. The states are returned in the order they were read.
+func ReadStates(r io.Reader) ([]State, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read block states: %w", err)
+	}
+	var states []State
+	buf := bytes.NewBuffer(data)
+	for buf.Len() > 0 {
+		// A new decoder is used for every state, so that the limit on the number of bytes read by a single
+		// decoder does not apply to the list as a whole.
+		var s State
+		if err := nbt.NewDecoderWithEncoding(buf, nbt.NetworkLittleEndian).Decode(&s); err != nil {
+			return nil, fmt.Errorf("read block state %v: %w", len(states), err)
+		}
+		if s.Properties == nil {
+			s.Properties = map[string]any{}
+		}
+		states = append(states, s)
+	}
+	return states, nil
+}
+
+// unknownHash is the network hash of the minecraft:unknown block, which the game does not derive from its
+// name and properties.
+const unknownHash = 0xfffffffe
+
+// NetworkHash returns the hash of a block state used as its network ID when the UseBlockNetworkIDHashes
+// field of the GameData of a connection is true. It is the 32-bit FNV-1a hash of the little endian NBT
+// encoding of a compound holding the name and properties of the state, with keys in sorted order.
+func NetworkHash(name string, properties map[string]any) uint32 {
+	if name == "minecraft:unknown" {
+		return unknownHash
+	}
+	h := fnv.New32a()
+	w := nbt.NewTokenWriter(h, nbt.LittleEndian)
+	_ = w.WriteToken(nbt.Token{Kind: nbt.TokenCompound})
+	_ = w.WriteToken(nbt.Token{Kind: nbt.TokenValue, Type: nbt.TagString, Name: "name", Value: name})
+	_ = w.WriteToken(nbt.Token{Kind: nbt.TokenCompound, Name: "states"})
+	for _, k := range slices.Sorted(maps.Keys(properties)) {
+		v := propertyValue(properties[k])
+		t, _ := tagOf(v)
+		_ = w.WriteToken(nbt.Token{Kind: nbt.TokenValue, Type: t, Name: k, Value: v})
+	}
+	_ = w.WriteToken(nbt.Token{Kind: nbt.TokenEnd})
+	_ = w.WriteToken(nbt.Token{Kind: nbt.TokenEnd})
+	return h.Sum32()
+}
+
+// propertyValue converts Go values commonly used for block properties to the types used in block state NBT.
+// bool values become a byte, other integers become an int32.
+func propertyValue(v any) any {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return byte(1)
+		}
+		return byte(0)
+	case int:
+		return int32(v)
+	case int8:
+		return byte(v)
+	case int16:
+		return int32(v)
+	case int64:
+		return int32(v)
+	case uint8:
+		return v
+	case uint16:
+		return int32(v)
+	case uint32:
+		return int32(v)
+	}
+	return v
+}
+
+// tagOf returns the tag type that a block property value is encoded as.
+func tagOf(v any) (nbt.TagType, bool) {
+	switch v.(type) {
+	case byte:
+		return nbt.TagByte, true
+	case int32:
+		return nbt.TagInt, true
+	case string:
+		return nbt.TagString, true
+	}
+	return nbt.TagEnd, false
+}
+
+// customStates returns every state of a custom block sent in the StartGame packet. A state is produced for
+// every combination of the values of the properties of the block, in the order the properties are listed,
+// with the last property changing fastest.
+func customStates(entry protocol.BlockEntry) []State {
+	type property struct {
+		name   string
+		values []any
+	}
+	var properties []property
+	list, _ := entry.Properties["properties"].([]any)
+	for _, p := range list {
+		m, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := m["name"].(string)
+		values, _ := m["enum"].([]any)
+		if name == "" || len(values) == 0 {
+			continue
+		}
+		properties = append(properties, property{name: name, values: values})
+	}
+
+	var states []State
+	indices := make([]int, len(properties))
+	for {
+		s := State{Name: entry.Name, Properties: make(map[string]any, len(properties))}
+		for i, p := range properties {
+			s.Properties[p.name] = propertyValue(p.values[indices[i]])
+		}
+		states = append(states, s)
+
+		i := len(indices) - 1
+		for ; i >= 0; i-- {
+			if indices[i]++; indices[i] < len(properties[i].values) {
+				break
+			}
+			indices[i] = 0
+		}
+		if i < 0 {
+			return states
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package block
+
+import (
+	"embed"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// The canonical block states are taken from the BedrockData release tagged for protocol.CurrentVersion, so that
+// running go generate always fetches the states of the game version implemented, rather than those of the
+// latest release. Update the game version below along with protocol.CurrentVersion.
+//
+//go:generate sh -c "tag=$DOLLAR(git ls-remote --tags --refs https://github.com/pmmp/BedrockData | sed -n 's|.*refs/tags/\\(.*+bedrock-1\\.26\\.45\\)$DOLLAR|\\1|p' | sort -V | tail -n 1) && test -n \"${DOLLAR}tag\" && curl -fsSL -o data/canonical_block_states.nbt https://raw.githubusercontent.com/pmmp/BedrockData/${DOLLAR}tag/canonical_block_states.nbt"
+
+// vanillaData holds the data directory, which holds the canonical block states of the game version that the
+// protocol package implements.
+//
+//go:embed data
+var vanillaData embed.FS
+
+// vanillaStates reads the canonical block states embedded in the package once.
+var vanillaStates = sync.OnceValues(func() ([]State, error) {
+	f, err := vanillaData.Open("data/canonical_block_states.nbt")
+	if err != nil {
+		return nil, fmt.Errorf("open vanilla block states: %w (run go generate in the block package)", err)
+	}
+	defer f.Close()
+	return ReadStates(f)
+})
+
+// VanillaStates returns the canonical block states of the game version implemented by the protocol package,
+// which are embedded in the package. The states are returned in the order of the canonical block state list
+// and may be passed to NewRegistry.
+func VanillaStates() ([]State, error) {
+	states, err := vanillaStates()
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(states), nil
+}
+
+// DefaultRegistry creates a Registry of the vanilla block states returned by VanillaStates and the custom
+// blocks passed, as described in NewRegistry. NewRegistry may be used instead to create a Registry of block
+// states of a different game version.
+func DefaultRegistry(custom []protocol.BlockEntry, hashes bool) (*Registry, error) {
+	vanilla, err := vanillaStates()
+	if err != nil {
+		return nil, err
+	}
+	return NewRegistry(vanilla, custom, hashes), nil
+}
+
+// DefaultRegistryFromGameData creates a Registry of the vanilla block states returned by VanillaStates and
+// the custom blocks in the GameData passed, as described in NewRegistryFromGameData.
+func DefaultRegistryFromGameData(data minecraft.GameData) (*Registry, error) {
+	return DefaultRegistry(data.CustomBlocks, data.UseBlockNetworkIDHashes)
+}
@@ -0,0 +1,180 @@
+// Package command implements a high level API to define commands, compile them to a packet.AvailableCommands
+// and parse the command lines found in a packet.CommandRequest against them.
+//
+// Commands are defined using a Command, which holds one or more overloads, each of which is a list of
+// parameters. A parameter is either of one of the basic argument types, such as protocol.CommandArgTypeInt,
+// or holds an Enum. Compile turns a list of commands into a packet.AvailableCommands, deduplicating the enum
+// values, enums, suffixes and chained subcommands shared between them. Parse matches a command line against
+// the overloads of those commands and returns the values of the arguments.
+package command
+
+import (
+	"strings"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// Command is the definition of a single command.
+type Command struct {
+	// Name is the name of the command, such as 'give'. It must be lowercase, as the client crashes if the name
+	// of a command contains uppercase letters.
+	Name string
+	// Description is the description of the command shown in the /help list and when the command is typed.
+	Description string
+	// Aliases holds other names the command may be executed with.
+	Aliases []string
+	// PermissionLevel is the permission level required to execute the command. It is one of the
+	// protocol.CommandPermissionLevel constants.
+	PermissionLevel byte
+	// Flags is the combination of command flags sent to the client. It may generally be left empty.
+	Flags uint16
+	// Overloads holds the different ways in which the command may be executed. A command without overloads
+	// can be executed without arguments.
+	Overloads []Overload
+	// ChainedSubcommands holds the subcommands of the command that are followed by another command, such as
+	// the subcommands of /execute.
+	ChainedSubcommands []ChainedSubcommand
+}
+
+// Overload is a single usage of a command.
+type Overload struct {
+	// Chaining specifies if the overload uses chained subcommands.
+	Chaining bool
+	// Parameters holds the parameters of the overload in the order they are entered. Optional parameters
+	// must come after all mandatory parameters.
+	Parameters []Parameter
+}
+
+// Parameter is a single parameter of an Overload.
+type Parameter struct {
+	// Name is the name of the parameter, shown in the usage of the command as <Name: Type>.
+	Name string
+	// Type is the argument type of the parameter. It is one of the protocol.CommandArgType constants and is
+	// ignored if Enum is set.
+	Type uint32
+	// Enum is the enum the argument must be a value of. If set, Type is ignored.
+	Enum *Enum
+	// Suffix is a suffix that must follow the value of an integer parameter, such as 'L' in the /xp command.
+	Suffix string
+	// Optional specifies if the parameter may be left out.
+	Optional bool
+	// Options is a combination of the protocol.ParamOption constants.
+	Options byte
+}
+
+// Enum is a set of values that an argument may take.
+type Enum struct {
+	// Type is the name of the enum, shown in the usage of a command if the enum is collapsed. Enums with the
+	// same type must have the same values and the same Dynamic field.
+	Type string
+	// Values holds the values of the enum.
+	Values []string
+	// Dynamic specifies if the enum is a soft enum, of which the values may be changed at runtime using a
+	// packet.UpdateSoftEnum. Arguments for dynamic enums are not checked against Values when parsing.
+	Dynamic bool
+}
+
+// ChainedSubcommand is a subcommand that is followed by another command, such as 'as' in /execute.
+type ChainedSubcommand struct {
+	// Name is the name of the chained subcommand.
+	Name string
+	// Values holds the values of the chained subcommand.
+	Values []ChainedSubcommandValue
+}
+
+// ChainedSubcommandValue is a single value of a ChainedSubcommand.
+type ChainedSubcommandValue struct {
+	// Name is the name of the value.
+	Name string
+	// Type is the argument type of the value. It is one of the protocol.CommandArgType constants.
+	Type uint32
+}
+
+// Usage returns the usage of every overload of the command, such as '/give <player: target> <itemName:
+// Item> [amount: int]'.
+func (c Command) Usage() []string {
+	if len(c.Overloads) == 0 {
+		return []string{"/" + c.Name}
+	}
+	usages := make([]string, len(c.Overloads))
+	for i, o := range c.Overloads {
+		usages[i] = c.overloadUsage(o)
+	}
+	return usages
+}
+
+// overloadUsage returns the usage of a single overload of the command.
+func (c Command) overloadUsage(o Overload) string {
+	var b strings.Builder
+	b.WriteString("/" + c.Name)
+	for _, p := range o.Parameters {
+		b.WriteByte(' ')
+		open, closing := "<", ">"
+		if p.Optional {
+			open, closing = "[", "]"
+		}
+		if p.Enum != nil && !p.Enum.Dynamic && p.Options&protocol.ParamOptionCollapseEnum == 0 && len(p.Enum.Values) <= 10 {
+			b.WriteString(open + strings.Join(p.Enum.Values, "|") + closing)
+			continue
+		}
+		b.WriteString(open + p.Name + ": " + p.typeName() + closing)
+	}
+	return b.String()
+}
+
+// typeName returns the name of the type of the parameter as shown in the usage of a command.
+func (p Parameter) typeName() string {
+	if p.Enum != nil {
+		return p.Enum.Type
+	}
+	switch p.Type {
+	case protocol.CommandArgTypeInt:
+		if p.Suffix != "" {
+			return "int" + p.Suffix
+		}
+		return "int"
+	case protocol.CommandArgTypeWildcardInt:
+		return "int|*"
+	case protocol.CommandArgTypeFloat:
+		return "float"
+	case protocol.CommandArgTypeTarget, protocol.CommandArgTypeStandaloneTarget, protocol.CommandArgTypeNonIDTarget:
+		return "target"
+	case protocol.CommandArgTypeWildcardTarget:
+		return "target|*"
+	case protocol.CommandArgTypeFilepath:
+		return "filepath"
+	case protocol.CommandArgTypeString:
+		return "string"
+	case protocol.CommandArgTypeBlockPosition, protocol.CommandArgTypePosition:
+		return "x y z"
+	case protocol.CommandArgTypeMessage:
+		return "message"
+	case protocol.CommandArgTypeRawText:
+		return "text"
+	case protocol.CommandArgTypeJSON:
+		return "json"
+	case protocol.CommandArgTypeBlockStates:
+		return "block states"
+	case protocol.CommandArgTypeCommand:
+		return "command"
+	case protocol.CommandArgTypeOperator:
+		return "operator"
+	case protocol.CommandArgTypeCompareOperator:
+		return "compare operator"
+	}
+	return "value"
+}
+
+// matches checks if the command may be executed using the name passed, which is either its name or one of its
+// aliases.
+func (c Command) matches(name string) bool {
+	if strings.EqualFold(c.Name, name) {
+		return true
+	}
+	for _, alias := range c.Aliases {
+		if strings.EqualFold(alias, name) {
+			return true
+		}
+	}
+	return false
+}
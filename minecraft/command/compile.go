@@ -0,0 +1,219 @@
+package command
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Compile compiles the commands passed into a packet.AvailableCommands that may be sent to a client. Enum
+// values, enums, suffixes and chained subcommands shared by multiple commands are only added to the packet
+// once. An error is returned if a command is invalid, for example if two commands have the same name or if
+// two enums with the same type have different values.
+func Compile(commands []Command) (*packet.AvailableCommands, error) {
+	c := &compiler{
+		pk:            &packet.AvailableCommands{},
+		values:        make(map[string]uint32),
+		suffixes:      make(map[string]uint32),
+		enums:         make(map[string]uint32),
+		dynamicEnums:  make(map[string]uint32),
+		chainedValues: make(map[string]uint32),
+		chained:       make(map[string]uint32),
+	}
+	names := make(map[string]struct{}, len(commands))
+	for _, cmd := range commands {
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			if _, ok := names[name]; ok {
+				return nil, fmt.Errorf("compile command %v: name %v is used by multiple commands", cmd.Name, name)
+			}
+			names[name] = struct{}{}
+		}
+		compiled, err := c.command(cmd)
+		if err != nil {
+			return nil, fmt.Errorf("compile command %v: %w", cmd.Name, err)
+		}
+		c.pk.Commands = append(c.pk.Commands, compiled)
+	}
+	return c.pk, nil
+}
+
+// compiler holds the state of a call to Compile. Its maps hold the index of every value, suffix, enum and
+// chained subcommand added to the packet, so that they are only added once.
+type compiler struct {
+	pk *packet.AvailableCommands
+
+	values        map[string]uint32
+	suffixes      map[string]uint32
+	enums         map[string]uint32
+	dynamicEnums  map[string]uint32
+	chainedValues map[string]uint32
+	chained       map[string]uint32
+}
+
+// command compiles a single command.
+func (c *compiler) command(cmd Command) (protocol.Command, error) {
+	if cmd.Name == "" || cmd.Name != strings.ToLower(cmd.Name) || strings.ContainsAny(cmd.Name, " \t") {
+		return protocol.Command{}, fmt.Errorf("name must be a non-empty lowercase word")
+	}
+	compiled := protocol.Command{
+		Name:            cmd.Name,
+		Description:     cmd.Description,
+		Flags:           cmd.Flags,
+		PermissionLevel: cmd.PermissionLevel,
+		AliasesOffset:   math.MaxUint32,
+	}
+	if len(cmd.Aliases) > 0 {
+		index, err := c.enum(Enum{Type: cmd.Name + "Aliases", Values: append([]string{cmd.Name}, cmd.Aliases...)})
+		if err != nil {
+			return compiled, err
+		}
+		compiled.AliasesOffset = index
+	}
+	for i, o := range cmd.Overloads {
+		overload, err := c.overload(o)
+		if err != nil {
+			return compiled, fmt.Errorf("overload %v: %w", i, err)
+		}
+		compiled.Overloads = append(compiled.Overloads, overload)
+	}
+	for _, sub := range cmd.ChainedSubcommands {
+		index, err := c.chainedSubcommand(sub)
+		if err != nil {
+			return compiled, err
+		}
+		compiled.ChainedSubcommandOffsets = append(compiled.ChainedSubcommandOffsets, index)
+	}
+	return compiled, nil
+}
+
+// overload compiles a single overload of a command.
+func (c *compiler) overload(o Overload) (protocol.CommandOverload, error) {
+	compiled := protocol.CommandOverload{Chaining: o.Chaining, Parameters: make([]protocol.CommandParameter, len(o.Parameters))}
+	optional := false
+	for i, p := range o.Parameters {
+		if optional && !p.Optional {
+			return compiled, fmt.Errorf("parameter %v: mandatory parameter after optional parameter", p.Name)
+		}
+		optional = p.Optional
+
+		t := protocol.CommandArgValid | p.Type
+		switch {
+		case p.Enum != nil && p.Enum.Dynamic:
+			index, err := c.dynamicEnum(*p.Enum)
+			if err != nil {
+				return compiled, fmt.Errorf("parameter %v: %w", p.Name, err)
+			}
+			t = protocol.CommandArgSoftEnum | protocol.CommandArgValid | index
+		case p.Enum != nil:
+			index, err := c.enum(*p.Enum)
+			if err != nil {
+				return compiled, fmt.Errorf("parameter %v: %w", p.Name, err)
+			}
+			t = protocol.CommandArgEnum | protocol.CommandArgValid | index
+		case p.Suffix != "":
+			t = protocol.CommandArgSuffixed | c.suffix(p.Suffix)
+		case p.Type == 0:
+			return compiled, fmt.Errorf("parameter %v: no type or enum set", p.Name)
+		}
+		compiled.Parameters[i] = protocol.CommandParameter{Name: p.Name, Type: t, Optional: p.Optional, Options: p.Options}
+	}
+	return compiled, nil
+}
+
+// enum adds an enum and its values to the packet if it was not yet added and returns its index.
+func (c *compiler) enum(e Enum) (uint32, error) {
+	if e.Type == "" || len(e.Values) == 0 {
+		return 0, fmt.Errorf("enum must have a type and at least one value")
+	}
+	if index, ok := c.enums[e.Type]; ok {
+		existing := c.pk.Enums[index]
+		if len(existing.ValueIndices) != len(e.Values) || !slices.EqualFunc(existing.ValueIndices, e.Values, func(i uint32, v string) bool {
+			return c.pk.EnumValues[i] == v
+		}) {
+			return 0, fmt.Errorf("enum %v is defined with different values", e.Type)
+		}
+		return index, nil
+	}
+	if _, ok := c.dynamicEnums[e.Type]; ok {
+		return 0, fmt.Errorf("enum %v is defined as both dynamic and not dynamic", e.Type)
+	}
+	enum := protocol.CommandEnum{Type: e.Type, ValueIndices: make([]uint32, len(e.Values))}
+	for i, v := range e.Values {
+		enum.ValueIndices[i] = c.value(v)
+	}
+	index := uint32(len(c.pk.Enums))
+	c.pk.Enums = append(c.pk.Enums, enum)
+	c.enums[e.Type] = index
+	return index, nil
+}
+
+// dynamicEnum adds a soft enum to the packet if it was not yet added and returns its index.
+func (c *compiler) dynamicEnum(e Enum) (uint32, error) {
+	if e.Type == "" {
+		return 0, fmt.Errorf("enum must have a type")
+	}
+	if index, ok := c.dynamicEnums[e.Type]; ok {
+		if !slices.Equal(c.pk.DynamicEnums[index].Values, e.Values) {
+			return 0, fmt.Errorf("enum %v is defined with different values", e.Type)
+		}
+		return index, nil
+	}
+	if _, ok := c.enums[e.Type]; ok {
+		return 0, fmt.Errorf("enum %v is defined as both dynamic and not dynamic", e.Type)
+	}
+	index := uint32(len(c.pk.DynamicEnums))
+	c.pk.DynamicEnums = append(c.pk.DynamicEnums, protocol.DynamicEnum{Type: e.Type, Values: slices.Clone(e.Values)})
+	c.dynamicEnums[e.Type] = index
+	return index, nil
+}
+
+// chainedSubcommand adds a chained subcommand and the names of its values to the packet if it was not yet
+// added and returns its index.
+func (c *compiler) chainedSubcommand(sub ChainedSubcommand) (uint32, error) {
+	compiled := protocol.ChainedSubcommand{Name: sub.Name, Values: make([]protocol.ChainedSubcommandValue, len(sub.Values))}
+	for i, v := range sub.Values {
+		index, ok := c.chainedValues[v.Name]
+		if !ok {
+			index = uint32(len(c.pk.ChainedSubcommandValues))
+			c.pk.ChainedSubcommandValues = append(c.pk.ChainedSubcommandValues, v.Name)
+			c.chainedValues[v.Name] = index
+		}
+		compiled.Values[i] = protocol.ChainedSubcommandValue{Index: index, Value: v.Type}
+	}
+	if index, ok := c.chained[sub.Name]; ok {
+		if !slices.Equal(c.pk.ChainedSubcommands[index].Values, compiled.Values) {
+			return 0, fmt.Errorf("chained subcommand %v is defined with different values", sub.Name)
+		}
+		return index, nil
+	}
+	index := uint32(len(c.pk.ChainedSubcommands))
+	c.pk.ChainedSubcommands = append(c.pk.ChainedSubcommands, compiled)
+	c.chained[sub.Name] = index
+	return index, nil
+}
+
+// value adds an enum value to the packet if it was not yet added and returns its index.
+func (c *compiler) value(v string) uint32 {
+	if index, ok := c.values[v]; ok {
+		return index
+	}
+	index := uint32(len(c.pk.EnumValues))
+	c.pk.EnumValues = append(c.pk.EnumValues, v)
+	c.values[v] = index
+	return index
+}
+
+// suffix adds a suffix to the packet if it was not yet added and returns its index.
+func (c *compiler) suffix(s string) uint32 {
+	if index, ok := c.suffixes[s]; ok {
+		return index
+	}
+	index := uint32(len(c.pk.Suffixes))
+	c.pk.Suffixes = append(c.pk.Suffixes, s)
+	c.suffixes[s] = index
+	return index
+}
@@ -0,0 +1,329 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Invocation is a command line matched against the overloads of a command.
+type Invocation struct {
+	// Command is the command that was executed.
+	Command Command
+	// Label is the name the command was executed with, which is either its name or one of its aliases.
+	Label string
+	// Overload is the index of the overload of the command that the arguments matched.
+	Overload int
+	// Args holds the value of every argument entered, by the name of its parameter. Optional parameters that
+	// were left out are not present. The type of each value depends on the parameter:
+	//
+	//	Enum, soft enum, string, target and file path parameters: string
+	//	Integer parameters, including those with a suffix: int32, or the string "*" for wildcard integers
+	//	Float parameters: float32
+	//	Position and block position parameters: Position
+	//	Message, raw text and command parameters: string holding the rest of the command line
+	//	JSON parameters: json.RawMessage
+	Args map[string]any
+}
+
+// Position is the value of a position argument, such as '~ ~1 ~' or '^ ^ ^2'.
+type Position struct {
+	X, Y, Z Coordinate
+}
+
+// Coordinate is a single coordinate of a Position.
+type Coordinate struct {
+	// Value is the value of the coordinate. For relative and local coordinates, it is the offset from the
+	// position of the source of the command.
+	Value float64
+	// Relative specifies if the coordinate was entered relative to the source using '~'.
+	Relative bool
+	// Local specifies if the coordinate was entered relative to the source and its rotation using '^'.
+	Local bool
+}
+
+// UnknownCommandError is returned by Parse if no command has the name entered.
+type UnknownCommandError struct {
+	Name string
+}
+
+// Error ...
+func (err UnknownCommandError) Error() string {
+	return fmt.Sprintf("unknown command: %v", err.Name)
+}
+
+// UsageError is returned by Parse if the arguments entered do not match any of the overloads of a command.
+// Err describes the problem with the arguments for the overload that matched the most arguments.
+type UsageError struct {
+	Command string
+	Usage   []string
+	Err     error
+}
+
+// Error ...
+func (err UsageError) Error() string {
+	return fmt.Sprintf("%v: %v (usage: %v)", err.Command, err.Err, strings.Join(err.Usage, ", "))
+}
+
+// Unwrap ...
+func (err UsageError) Unwrap() error {
+	return err.Err
+}
+
+// ParseCommandRequest parses the command line of the packet.CommandRequest passed against the commands
+// passed. See Parse for details.
+func ParseCommandRequest(commands []Command, pk *packet.CommandRequest) (Invocation, error) {
+	return Parse(commands, pk.CommandLine)
+}
+
+// Parse matches a command line, such as '/give @s diamond 5', against the commands passed. The overloads of
+// the command are tried in order and the first overload that all arguments match is used. An
+// UnknownCommandError is returned if no command has the name entered and a UsageError if the arguments match
+// none of the overloads.
+func Parse(commands []Command, line string) (Invocation, error) {
+	r := &lineReader{line: strings.TrimPrefix(strings.TrimSpace(line), "/")}
+	label := r.word()
+	var cmd Command
+	found := false
+	for _, c := range commands {
+		if c.matches(label) {
+			cmd, found = c, true
+			break
+		}
+	}
+	if !found {
+		return Invocation{}, UnknownCommandError{Name: label}
+	}
+	if len(cmd.Overloads) == 0 {
+		if r.skipSpace(); !r.done() {
+			return Invocation{}, UsageError{Command: cmd.Name, Usage: cmd.Usage(), Err: fmt.Errorf("too many arguments: %v", r.rest())}
+		}
+		return Invocation{Command: cmd, Label: label, Args: map[string]any{}}, nil
+	}
+
+	var (
+		bestErr     error
+		bestMatched = -1
+	)
+	for i, o := range cmd.Overloads {
+		overloadReader := *r
+		args, matched, err := matchOverload(o, &overloadReader)
+		if err == nil {
+			return Invocation{Command: cmd, Label: label, Overload: i, Args: args}, nil
+		}
+		if matched > bestMatched {
+			bestErr, bestMatched = err, matched
+		}
+	}
+	return Invocation{}, UsageError{Command: cmd.Name, Usage: cmd.Usage(), Err: bestErr}
+}
+
+// matchOverload parses the arguments in r against the parameters of an overload. It returns the number of
+// arguments that matched, so that the most relevant error may be reported if no overload matches.
+func matchOverload(o Overload, r *lineReader) (map[string]any, int, error) {
+	args := make(map[string]any, len(o.Parameters))
+	for i, p := range o.Parameters {
+		if r.skipSpace(); r.done() {
+			if p.Optional {
+				return args, i, nil
+			}
+			return nil, i, fmt.Errorf("missing argument %v", p.Name)
+		}
+		v, err := parseArg(p, r)
+		if err != nil {
+			return nil, i, fmt.Errorf("invalid argument %v: %w", p.Name, err)
+		}
+		args[p.Name] = v
+	}
+	if r.skipSpace(); !r.done() {
+		return nil, len(o.Parameters), fmt.Errorf("too many arguments: %v", r.rest())
+	}
+	return args, len(o.Parameters), nil
+}
+
+// parseArg parses the argument of a single parameter from r.
+func parseArg(p Parameter, r *lineReader) (any, error) {
+	if p.Enum != nil {
+		w := r.word()
+		if p.Enum.Dynamic {
+			return w, nil
+		}
+		for _, v := range p.Enum.Values {
+			if strings.EqualFold(v, w) {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not one of %v", w, strings.Join(p.Enum.Values, ", "))
+	}
+	if p.Suffix != "" {
+		w := r.word()
+		if len(w) < len(p.Suffix) || !strings.EqualFold(w[len(w)-len(p.Suffix):], p.Suffix) {
+			return nil, fmt.Errorf("%q does not end with %v", w, p.Suffix)
+		}
+		return parseInt(w[:len(w)-len(p.Suffix)])
+	}
+
+	switch p.Type {
+	case protocol.CommandArgTypeInt:
+		return parseInt(r.word())
+	case protocol.CommandArgTypeWildcardInt:
+		if w := r.word(); w != "*" {
+			return parseInt(w)
+		}
+		return "*", nil
+	case protocol.CommandArgTypeFloat:
+		w := r.word()
+		f, err := strconv.ParseFloat(w, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", w)
+		}
+		return float32(f), nil
+	case protocol.CommandArgTypePosition, protocol.CommandArgTypeBlockPosition:
+		return parsePosition(r)
+	case protocol.CommandArgTypeMessage, protocol.CommandArgTypeRawText, protocol.CommandArgTypeCommand, protocol.CommandArgTypeChainedCommand:
+		return r.rest(), nil
+	case protocol.CommandArgTypeJSON:
+		// Only a single JSON value is read, so that arguments after it may still be parsed.
+		dec := json.NewDecoder(strings.NewReader(r.line[r.off:]))
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		r.off += int(dec.InputOffset())
+		if !r.done() && !unicode.IsSpace(rune(r.line[r.off])) {
+			return nil, fmt.Errorf("invalid JSON: unexpected %q after value", r.line[r.off])
+		}
+		return json.RawMessage(bytes.Clone(v)), nil
+	}
+	return r.word(), nil
+}
+
+// parseInt parses a 32-bit integer argument.
+func parseInt(s string) (int32, error) {
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an integer", s)
+	}
+	return int32(n), nil
+}
+
+// parsePosition parses the three coordinates of a position from r. Coordinates may be separated by spaces
+// or, if they start with '~' or '^', written without spaces, such as in '~~1~'.
+func parsePosition(r *lineReader) (Position, error) {
+	var coords []Coordinate
+	for len(coords) < 3 {
+		if r.skipSpace(); r.done() {
+			return Position{}, fmt.Errorf("expected 3 coordinates, got %v", len(coords))
+		}
+		w := r.word()
+		for w != "" {
+			end := strings.IndexAny(w[1:], "~^") + 1
+			if end == 0 {
+				end = len(w)
+			}
+			c, err := parseCoordinate(w[:end])
+			if err != nil {
+				return Position{}, err
+			}
+			coords, w = append(coords, c), w[end:]
+		}
+	}
+	if len(coords) > 3 {
+		return Position{}, fmt.Errorf("expected 3 coordinates, got %v", len(coords))
+	}
+	if coords[0].Local != coords[1].Local || coords[1].Local != coords[2].Local {
+		return Position{}, fmt.Errorf("local coordinates cannot be mixed with world coordinates")
+	}
+	return Position{X: coords[0], Y: coords[1], Z: coords[2]}, nil
+}
+
+// parseCoordinate parses a single coordinate, such as '5', '~', '~-2.5' or '^1'.
+func parseCoordinate(s string) (Coordinate, error) {
+	var c Coordinate
+	switch {
+	case strings.HasPrefix(s, "~"):
+		c.Relative, s = true, s[1:]
+	case strings.HasPrefix(s, "^"):
+		c.Local, s = true, s[1:]
+	}
+	if s == "" {
+		if !c.Relative && !c.Local {
+			return c, fmt.Errorf("empty coordinate")
+		}
+		return c, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return c, fmt.Errorf("%q is not a valid coordinate", s)
+	}
+	c.Value = v
+	return c, nil
+}
+
+// lineReader reads the arguments of a command line one by one.
+type lineReader struct {
+	line string
+	off  int
+}
+
+// skipSpace skips whitespace at the current offset.
+func (r *lineReader) skipSpace() {
+	for r.off < len(r.line) && unicode.IsSpace(rune(r.line[r.off])) {
+		r.off++
+	}
+}
+
+// done checks if the whole command line was read.
+func (r *lineReader) done() bool {
+	return r.off >= len(r.line)
+}
+
+// rest returns the rest of the command line and moves to its end.
+func (r *lineReader) rest() string {
+	s := strings.TrimRightFunc(r.line[r.off:], unicode.IsSpace)
+	r.off = len(r.line)
+	return s
+}
+
+// word reads a single word. A word ends at whitespace, unless that whitespace is between brackets, such as
+// in the target selector '@e[type=cow, c=1]', or in double quotes. A word entirely in double quotes is
+// returned without the quotes, with the escape sequences \" and \\ resolved.
+func (r *lineReader) word() string {
+	if r.off < len(r.line) && r.line[r.off] == '"' {
+		var b strings.Builder
+		for r.off++; r.off < len(r.line); r.off++ {
+			switch ch := r.line[r.off]; {
+			case ch == '\\' && r.off+1 < len(r.line):
+				r.off++
+				b.WriteByte(r.line[r.off])
+			case ch == '"':
+				r.off++
+				return b.String()
+			default:
+				b.WriteByte(ch)
+			}
+		}
+		return b.String()
+	}
+	start, depth, quoted := r.off, 0, false
+	for ; r.off < len(r.line); r.off++ {
+		switch ch := r.line[r.off]; {
+		case ch == '"':
+			quoted = !quoted
+		case quoted:
+		case ch == '[' || ch == '{':
+			depth++
+		case (ch == ']' || ch == '}') && depth > 0:
+			depth--
+		case depth == 0 && unicode.IsSpace(rune(ch)):
+			return r.line[start:r.off]
+		}
+	}
+	return r.line[start:]
+}
@@ -0,0 +1,35 @@
+package command
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+func TestParseJSON(t *testing.T) {
+	commands := []Command{{
+		Name: "tellraw",
+		Overloads: []Overload{{Parameters: []Parameter{
+			{Name: "json", Type: protocol.CommandArgTypeJSON},
+			{Name: "n", Type: protocol.CommandArgTypeInt, Optional: true},
+		}}},
+	}}
+
+	inv, err := Parse(commands, `/tellraw {"a": [1, 2]} 5`)
+	if err != nil {
+		t.Fatalf("parse JSON followed by argument: %v", err)
+	}
+	if got := string(inv.Args["json"].(json.RawMessage)); got != `{"a": [1, 2]}` {
+		t.Errorf("json argument: got %v, want %v", got, `{"a": [1, 2]}`)
+	}
+	if got := inv.Args["n"]; got != int32(5) {
+		t.Errorf("n argument: got %v, want 5", got)
+	}
+
+	for _, line := range []string{`/tellraw {"a":1} 5 6`, `/tellraw {"a":1} garbage`, `/tellraw {"a":1}x`, `/tellraw {"a":`} {
+		if _, err := Parse(commands, line); err == nil {
+			t.Errorf("parse %q: expected error", line)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package selector
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl64"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// Entity is an entity that a Selector may target. It is implemented by users over their own entity model.
+// Entities may additionally implement ScoreHolder, Player and ItemHolder to be matched by the arguments that
+// depend on these.
+type Entity interface {
+	// Type returns the identifier of the entity type, such as 'minecraft:cow' or 'minecraft:player'.
+	Type() string
+	// Name returns the name of the entity: The name of a player or the name tag of other entities.
+	Name() string
+	// Position returns the position of the entity.
+	Position() mgl64.Vec3
+	// Rotation returns the pitch and yaw of the entity in degrees.
+	Rotation() (pitch, yaw float64)
+	// Tags returns the tags added to the entity using /tag.
+	Tags() []string
+	// Families returns the type families of the entity, such as 'monster' or 'player'.
+	Families() []string
+}
+
+// ScoreHolder is an Entity that has scoreboard scores. Entities not implementing ScoreHolder never match the
+// scores argument.
+type ScoreHolder interface {
+	// Score returns the score of the entity for the objective passed, or false if it has no score for it.
+	Score(objective string) (int32, bool)
+}
+
+// Player is an Entity that is a player. Entities not implementing Player never match the m, l, lm and
+// haspermission arguments.
+type Player interface {
+	// GameMode returns the game mode of the player, which is one of the packet.GameType constants.
+	GameMode() int32
+	// Level returns the experience level of the player.
+	Level() int32
+	// Permission returns if the permission passed, such as 'camera' or 'movement', is enabled for the player.
+	Permission(name string) bool
+}
+
+// ItemHolder is an Entity that has items. Entities not implementing ItemHolder never match the hasitem
+// argument.
+type ItemHolder interface {
+	// CountItems returns the total count of the items held that match the Item, Data, Location and Slot
+	// fields of the ItemMatch passed. Unset fields match any item.
+	CountItems(m ItemMatch) int32
+}
+
+// Source is the source of a command that a Selector is evaluated for.
+type Source struct {
+	// Entity is the entity that executed the command. It is nil if the command was not executed by an
+	// entity, for example if it was executed by a command block or the server console.
+	Entity Entity
+	// Position is the position the command was executed at. It is only used if Entity is nil.
+	Position mgl64.Vec3
+	// Initiator is the player interacting with an NPC, which is targeted by @initiator.
+	Initiator Entity
+}
+
+// position returns the position of the source.
+func (src Source) position() mgl64.Vec3 {
+	if src.Entity != nil {
+		return src.Entity.Position()
+	}
+	return src.Position
+}
+
+// Evaluate resolves the entities targeted by the selector, executed by the source passed, from the entities
+// passed, which should hold all entities in the world of the source, including players. @p, @n and @r
+// target at most one entity unless the c argument is set. Entities are returned sorted by distance if the
+// selector targets the nearest or furthest entities.
+func (s *Selector) Evaluate(src Source, entities []Entity) []Entity {
+	if s.PlayerName != "" {
+		for _, e := range entities {
+			if isPlayer(e) && strings.EqualFold(e.Name(), s.PlayerName) {
+				return []Entity{e}
+			}
+		}
+		return nil
+	}
+
+	origin := src.position()
+	for i, c := range [3]protocol.Optional[Coordinate]{s.X, s.Y, s.Z} {
+		if v, ok := c.Value(); ok {
+			if v.Relative {
+				origin[i] += v.Value
+			} else {
+				origin[i] = v.Value
+			}
+		}
+	}
+
+	var candidates []Entity
+	switch s.Variable {
+	case VariableSelf:
+		candidates = nonNil(src.Entity)
+	case VariableInitiator:
+		candidates = nonNil(src.Initiator)
+	case VariableNearestPlayer, VariableAllPlayers:
+		candidates = slices.DeleteFunc(slices.Clone(entities), func(e Entity) bool { return !isPlayer(e) })
+	case VariableRandomPlayer:
+		candidates = slices.Clone(entities)
+		if !slices.ContainsFunc(s.Types, func(m Match) bool { return !m.Negated }) {
+			candidates = slices.DeleteFunc(candidates, func(e Entity) bool { return !isPlayer(e) })
+		}
+	default:
+		candidates = slices.Clone(entities)
+	}
+	candidates = slices.DeleteFunc(candidates, func(e Entity) bool { return !s.matches(e, origin) })
+
+	count, limited := s.Count.Value()
+	switch s.Variable {
+	case VariableNearestPlayer, VariableNearestEntity:
+		if !limited {
+			count, limited = 1, true
+		}
+		sortByDistance(candidates, origin, count < 0)
+	case VariableRandomPlayer:
+		if !limited {
+			count, limited = 1, true
+		}
+		rand.Shuffle(len(candidates), func(i, j int) {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		})
+	default:
+		if limited {
+			sortByDistance(candidates, origin, count < 0)
+		}
+	}
+	if limited {
+		if count < 0 {
+			count = -count
+		}
+		if int(count) < len(candidates) {
+			candidates = candidates[:count]
+		}
+	}
+	return candidates
+}
+
+// matches checks if an entity matches all arguments of the selector, using the origin passed for the
+// arguments that depend on the position of the entity.
+func (s *Selector) matches(e Entity, origin mgl64.Vec3) bool {
+	pos := e.Position()
+	if s.Distance.Set() && !s.Distance.Contains(pos.Sub(origin).Len()) {
+		return false
+	}
+	dx, hasDX := s.DX.Value()
+	dy, hasDY := s.DY.Value()
+	dz, hasDZ := s.DZ.Value()
+	if hasDX || hasDY || hasDZ {
+		for i, d := range [3]float64{dx, dy, dz} {
+			lower, upper := origin[i], origin[i]+d
+			if lower > upper {
+				lower, upper = upper, lower
+			}
+			if pos[i] < lower || pos[i] > upper+1 {
+				return false
+			}
+		}
+	}
+	pitch, yaw := e.Rotation()
+	if !s.XRotation.Contains(pitch) || !s.YRotation.Contains(normaliseYaw(yaw)) {
+		return false
+	}
+
+	for _, m := range s.Types {
+		if (normaliseType(e.Type()) == normaliseType(m.Value)) == m.Negated {
+			return false
+		}
+	}
+	for _, m := range s.Names {
+		if (e.Name() == m.Value) == m.Negated {
+			return false
+		}
+	}
+	tags := e.Tags()
+	for _, m := range s.Tags {
+		if m.Value == "" {
+			if (len(tags) == 0) == m.Negated {
+				return false
+			}
+		} else if slices.Contains(tags, m.Value) == m.Negated {
+			return false
+		}
+	}
+	families := e.Families()
+	for _, m := range s.Families {
+		if slices.Contains(families, m.Value) == m.Negated {
+			return false
+		}
+	}
+
+	if len(s.Scores) > 0 {
+		holder, ok := e.(ScoreHolder)
+		if !ok {
+			return false
+		}
+		for _, m := range s.Scores {
+			score, ok := holder.Score(m.Objective)
+			if !ok || m.Range.Contains(score) == m.Negated {
+				return false
+			}
+		}
+	}
+	if len(s.GameModes) > 0 || s.Level.Set() || len(s.Permissions) > 0 {
+		p, ok := e.(Player)
+		if !ok {
+			return false
+		}
+		for _, m := range s.GameModes {
+			if (p.GameMode() == m.GameMode) == m.Negated {
+				return false
+			}
+		}
+		if !s.Level.Contains(p.Level()) {
+			return false
+		}
+		for name, enabled := range s.Permissions {
+			if p.Permission(name) != enabled {
+				return false
+			}
+		}
+	}
+	if len(s.Items) > 0 {
+		holder, ok := e.(ItemHolder)
+		if !ok {
+			return false
+		}
+		for _, m := range s.Items {
+			n := holder.CountItems(m)
+			if m.Quantity.Set() {
+				if m.Quantity.Contains(n) == m.QuantityNegated {
+					return false
+				}
+			} else if n == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// sortByDistance sorts entities by their distance to the origin passed, nearest first or, if reverse is
+// true, furthest first.
+func sortByDistance(entities []Entity, origin mgl64.Vec3, reverse bool) {
+	slices.SortStableFunc(entities, func(a, b Entity) int {
+		da, db := a.Position().Sub(origin).LenSqr(), b.Position().Sub(origin).LenSqr()
+		if reverse {
+			da, db = db, da
+		}
+		switch {
+		case da < db:
+			return -1
+		case da > db:
+			return 1
+		}
+		return 0
+	})
+}
+
+// isPlayer checks if an entity is a player.
+func isPlayer(e Entity) bool {
+	return normaliseType(e.Type()) == "minecraft:player"
+}
+
+// normaliseType adds the minecraft namespace to an entity type if it has no namespace.
+func normaliseType(t string) string {
+	t = strings.ToLower(t)
+	if !strings.Contains(t, ":") {
+		return "minecraft:" + t
+	}
+	return t
+}
+
+// normaliseYaw returns the yaw passed in the range [-180, 180).
+func normaliseYaw(yaw float64) float64 {
+	yaw = math.Mod(yaw+180, 360)
+	if yaw < 0 {
+		yaw += 360
+	}
+	return yaw - 180
+}
+
+// nonNil returns a slice holding e, or an empty slice if e is nil.
+func nonNil(e Entity) []Entity {
+	if e == nil {
+		return nil
+	}
+	return []Entity{e}
+}
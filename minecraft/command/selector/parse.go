@@ -0,0 +1,519 @@
+package selector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// SyntaxError is returned by Parse if a selector is not valid.
+type SyntaxError struct {
+	// Off is the offset in the selector at which the error was found.
+	Off int
+	Msg string
+}
+
+// Error ...
+func (err SyntaxError) Error() string {
+	return fmt.Sprintf("selector: syntax error at offset %v: %v", err.Off, err.Msg)
+}
+
+// Parse parses a target selector, such as '@e[type=cow,r=10]'. If s does not start with '@', it is parsed as
+// the name of a player, optionally in double quotes. A SyntaxError is returned if the selector is not valid,
+// for example if it has unknown arguments or if an argument that may only be present once is repeated.
+func Parse(s string) (*Selector, error) {
+	p := &parser{s: s}
+	sel, err := p.selector()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.off != len(p.s) {
+		return nil, p.errorf("unexpected %q after selector", p.s[p.off:])
+	}
+	return sel, nil
+}
+
+// parser holds the state of a call to Parse.
+type parser struct {
+	s   string
+	off int
+	// seen holds the single-valued arguments already parsed.
+	seen map[string]bool
+}
+
+// selector parses a complete selector.
+func (p *parser) selector() (*Selector, error) {
+	p.skipSpace()
+	if !strings.HasPrefix(p.s[p.off:], "@") {
+		name, err := p.value(true)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			return nil, p.errorf("expected selector or player name")
+		}
+		return &Selector{PlayerName: name}, nil
+	}
+	p.off++
+	start := p.off
+	for p.off < len(p.s) && isVariableChar(p.s[p.off]) {
+		p.off++
+	}
+	variable, ok := variables[p.s[start:p.off]]
+	if !ok {
+		return nil, SyntaxError{Off: start, Msg: fmt.Sprintf("unknown selector variable @%v", p.s[start:p.off])}
+	}
+	sel := &Selector{Variable: variable}
+	if p.skipSpace(); !p.consume('[') {
+		return sel, nil
+	}
+	p.seen = make(map[string]bool)
+	if p.skipSpace(); p.consume(']') {
+		return sel, nil
+	}
+	for {
+		if err := p.argument(sel); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.consume(']') {
+			break
+		}
+		if !p.consume(',') {
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+	return sel, p.validate(sel)
+}
+
+// argument parses a single key=value argument and stores it in sel.
+func (p *parser) argument(sel *Selector) error {
+	p.skipSpace()
+	keyOff := p.off
+	key := p.key()
+	if p.skipSpace(); !p.consume('=') {
+		return p.errorf("expected '=' after argument %q", key)
+	}
+	p.skipSpace()
+
+	switch key {
+	case "x", "y", "z", "r", "rm", "dx", "dy", "dz", "rx", "rxm", "ry", "rym", "l", "lm", "c", "haspermission", "scores", "hasitem":
+		if p.seen[key] {
+			return SyntaxError{Off: keyOff, Msg: fmt.Sprintf("argument %v may only be present once", key)}
+		}
+		p.seen[key] = true
+	}
+	switch key {
+	case "x", "y", "z":
+		c, err := p.coordinate()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "x":
+			sel.X = protocol.Option(c)
+		case "y":
+			sel.Y = protocol.Option(c)
+		case "z":
+			sel.Z = protocol.Option(c)
+		}
+	case "dx", "dy", "dz":
+		v, err := p.float()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "dx":
+			sel.DX = protocol.Option(v)
+		case "dy":
+			sel.DY = protocol.Option(v)
+		case "dz":
+			sel.DZ = protocol.Option(v)
+		}
+	case "r", "rx", "ry":
+		v, err := p.float()
+		if err != nil {
+			return err
+		}
+		r := &sel.Distance
+		if key == "rx" {
+			r = &sel.XRotation
+		} else if key == "ry" {
+			r = &sel.YRotation
+		}
+		r.Max, r.HasMax = v, true
+	case "rm", "rxm", "rym":
+		v, err := p.float()
+		if err != nil {
+			return err
+		}
+		r := &sel.Distance
+		if key == "rxm" {
+			r = &sel.XRotation
+		} else if key == "rym" {
+			r = &sel.YRotation
+		}
+		r.Min, r.HasMin = v, true
+	case "l", "lm":
+		v, err := p.int()
+		if err != nil {
+			return err
+		}
+		if key == "l" {
+			sel.Level.Max, sel.Level.HasMax = v, true
+		} else {
+			sel.Level.Min, sel.Level.HasMin = v, true
+		}
+	case "c":
+		v, err := p.int()
+		if err != nil {
+			return err
+		}
+		sel.Count = protocol.Option(v)
+	case "type", "name", "tag", "family":
+		m, err := p.match()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "type":
+			sel.Types = append(sel.Types, m)
+		case "name":
+			sel.Names = append(sel.Names, m)
+		case "tag":
+			sel.Tags = append(sel.Tags, m)
+		case "family":
+			sel.Families = append(sel.Families, m)
+		}
+	case "m":
+		off := p.off
+		m, err := p.match()
+		if err != nil {
+			return err
+		}
+		mode, ok := gameModes[strings.ToLower(m.Value)]
+		if !ok {
+			return SyntaxError{Off: off, Msg: fmt.Sprintf("unknown game mode %q", m.Value)}
+		}
+		sel.GameModes = append(sel.GameModes, GameModeMatch{GameMode: mode, Negated: m.Negated})
+	case "scores":
+		return p.object(func(key string) error {
+			negated := p.consume('!')
+			r, err := intRange(p)
+			if err != nil {
+				return err
+			}
+			sel.Scores = append(sel.Scores, ScoreMatch{Objective: key, Range: r, Negated: negated})
+			return nil
+		})
+	case "haspermission":
+		sel.Permissions = make(map[string]bool)
+		return p.object(func(key string) error {
+			off := p.off
+			state, err := p.value(false)
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(state) {
+			case "enabled":
+				sel.Permissions[key] = true
+			case "disabled":
+				sel.Permissions[key] = false
+			default:
+				return SyntaxError{Off: off, Msg: fmt.Sprintf("permission state must be enabled or disabled, got %q", state)}
+			}
+			return nil
+		})
+	case "hasitem":
+		if !p.consume('[') {
+			m, err := p.item()
+			if err != nil {
+				return err
+			}
+			sel.Items = append(sel.Items, m)
+			return nil
+		}
+		for {
+			p.skipSpace()
+			m, err := p.item()
+			if err != nil {
+				return err
+			}
+			sel.Items = append(sel.Items, m)
+			if p.skipSpace(); p.consume(']') {
+				return nil
+			}
+			if !p.consume(',') {
+				return p.errorf("expected ',' or ']' in hasitem list")
+			}
+		}
+	default:
+		return SyntaxError{Off: keyOff, Msg: fmt.Sprintf("unknown argument %q", key)}
+	}
+	return nil
+}
+
+// item parses a single hasitem condition in braces.
+func (p *parser) item() (ItemMatch, error) {
+	var m ItemMatch
+	off := p.off
+	err := p.object(func(key string) error {
+		var err error
+		switch key {
+		case "item":
+			m.Item, err = p.value(false)
+		case "data":
+			var v int32
+			v, err = p.int()
+			m.Data = protocol.Option(v)
+		case "quantity":
+			m.QuantityNegated = p.consume('!')
+			m.Quantity, err = intRange(p)
+		case "location":
+			m.Location, err = p.value(false)
+		case "slot":
+			m.Slot, err = intRange(p)
+		default:
+			return p.errorf("unknown hasitem argument %q", key)
+		}
+		return err
+	})
+	if err == nil && m.Item == "" {
+		return m, SyntaxError{Off: off, Msg: "hasitem condition must have an item"}
+	}
+	return m, err
+}
+
+// object parses a list of key=value pairs in braces, calling f for every key with the parser positioned at
+// its value.
+func (p *parser) object(f func(key string) error) error {
+	if !p.consume('{') {
+		return p.errorf("expected '{'")
+	}
+	if p.skipSpace(); p.consume('}') {
+		return nil
+	}
+	for {
+		p.skipSpace()
+		key, err := p.value(false)
+		if err != nil {
+			return err
+		}
+		if p.skipSpace(); !p.consume('=') {
+			return p.errorf("expected '=' after %q", key)
+		}
+		p.skipSpace()
+		if err := f(key); err != nil {
+			return err
+		}
+		if p.skipSpace(); p.consume('}') {
+			return nil
+		}
+		if !p.consume(',') {
+			return p.errorf("expected ',' or '}'")
+		}
+	}
+}
+
+// match parses a value that may be negated with '!'.
+func (p *parser) match() (Match, error) {
+	negated := p.consume('!')
+	p.skipSpace()
+	v, err := p.value(false)
+	return Match{Value: v, Negated: negated}, err
+}
+
+// coordinate parses the value of the x, y or z argument.
+func (p *parser) coordinate() (Coordinate, error) {
+	var c Coordinate
+	if c.Relative = p.consume('~'); c.Relative {
+		if s := p.peekValue(); s == "" {
+			return c, nil
+		}
+	}
+	v, err := p.float()
+	c.Value = v
+	return c, err
+}
+
+// float parses a floating point number.
+func (p *parser) float() (float64, error) {
+	off := p.off
+	s, err := p.value(false)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, SyntaxError{Off: off, Msg: fmt.Sprintf("%q is not a number", s)}
+	}
+	return v, nil
+}
+
+// int parses a 32-bit integer.
+func (p *parser) int() (int32, error) {
+	off := p.off
+	s, err := p.value(false)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return 0, SyntaxError{Off: off, Msg: fmt.Sprintf("%q is not an integer", s)}
+	}
+	return int32(v), nil
+}
+
+// intRange parses a range of integers, such as '1..5', '1..', '..5' or '3'.
+func intRange(p *parser) (Range[int32], error) {
+	off := p.off
+	s, err := p.value(false)
+	if err != nil {
+		return Range[int32]{}, err
+	}
+	var r Range[int32]
+	lower, upper, isRange := strings.Cut(s, "..")
+	if !isRange {
+		upper = lower
+	}
+	if lower == "" && upper == "" {
+		return r, SyntaxError{Off: off, Msg: fmt.Sprintf("%q is not a valid range", s)}
+	}
+	for _, bound := range []struct {
+		s   string
+		v   *int32
+		has *bool
+	}{{lower, &r.Min, &r.HasMin}, {upper, &r.Max, &r.HasMax}} {
+		if bound.s == "" {
+			continue
+		}
+		v, err := strconv.ParseInt(bound.s, 10, 32)
+		if err != nil {
+			return r, SyntaxError{Off: off, Msg: fmt.Sprintf("%q is not a valid range", s)}
+		}
+		*bound.v, *bound.has = int32(v), true
+	}
+	if r.HasMin && r.HasMax && r.Min > r.Max {
+		return r, SyntaxError{Off: off, Msg: fmt.Sprintf("minimum of range %q is greater than its maximum", s)}
+	}
+	return r, nil
+}
+
+// key parses the key of an argument.
+func (p *parser) key() string {
+	start := p.off
+	for p.off < len(p.s) && (isVariableChar(p.s[p.off]) || p.s[p.off] == '_') {
+		p.off++
+	}
+	return p.s[start:p.off]
+}
+
+// value parses a value, which is either in double quotes or ends at one of the characters that separate
+// arguments. If topLevel is true, the value is a player name outside of brackets and ends at whitespace.
+func (p *parser) value(topLevel bool) (string, error) {
+	if p.consume('"') {
+		var b strings.Builder
+		for p.off < len(p.s) {
+			ch := p.s[p.off]
+			p.off++
+			switch {
+			case ch == '\\' && p.off < len(p.s):
+				b.WriteByte(p.s[p.off])
+				p.off++
+			case ch == '"':
+				return b.String(), nil
+			default:
+				b.WriteByte(ch)
+			}
+		}
+		return "", p.errorf("unterminated quoted value")
+	}
+	start := p.off
+	for p.off < len(p.s) {
+		ch := p.s[p.off]
+		if strings.IndexByte(",=]}[{", ch) != -1 || (topLevel && ch == ' ') {
+			break
+		}
+		p.off++
+	}
+	return strings.TrimSpace(p.s[start:p.off]), nil
+}
+
+// peekValue returns the value at the current offset without consuming it.
+func (p *parser) peekValue() string {
+	off := p.off
+	v, _ := p.value(false)
+	p.off = off
+	return v
+}
+
+// validate checks the combination of arguments in a selector.
+func (p *parser) validate(sel *Selector) error {
+	if sel.Distance.HasMin && sel.Distance.Min < 0 || sel.Distance.HasMax && sel.Distance.Max < 0 {
+		return p.errorf("distance must not be negative")
+	}
+	if sel.Distance.HasMin && sel.Distance.HasMax && sel.Distance.Min > sel.Distance.Max {
+		return p.errorf("rm must not be greater than r")
+	}
+	if sel.Level.HasMin && sel.Level.Min < 0 || sel.Level.HasMax && sel.Level.Max < 0 {
+		return p.errorf("level must not be negative")
+	}
+	if sel.Level.HasMin && sel.Level.HasMax && sel.Level.Min > sel.Level.Max {
+		return p.errorf("lm must not be greater than l")
+	}
+	if c, ok := sel.Count.Value(); ok && c == 0 {
+		return p.errorf("c must not be 0")
+	}
+	for _, list := range []struct {
+		name    string
+		matches []Match
+	}{{"type", sel.Types}, {"name", sel.Names}} {
+		positive := 0
+		for _, m := range list.matches {
+			if !m.Negated {
+				positive++
+			}
+		}
+		if positive > 1 {
+			return p.errorf("argument %v may only be present once unless negated", list.name)
+		}
+	}
+	positive := 0
+	for _, m := range sel.GameModes {
+		if !m.Negated {
+			positive++
+		}
+	}
+	if positive > 1 {
+		return p.errorf("argument m may only be present once unless negated")
+	}
+	return nil
+}
+
+// consume consumes the byte passed if it is at the current offset.
+func (p *parser) consume(ch byte) bool {
+	if p.off < len(p.s) && p.s[p.off] == ch {
+		p.off++
+		return true
+	}
+	return false
+}
+
+// skipSpace skips whitespace at the current offset.
+func (p *parser) skipSpace() {
+	for p.off < len(p.s) && (p.s[p.off] == ' ' || p.s[p.off] == '\t') {
+		p.off++
+	}
+}
+
+// errorf returns a SyntaxError at the current offset.
+func (p *parser) errorf(format string, a ...any) error {
+	return SyntaxError{Off: p.off, Msg: fmt.Sprintf(format, a...)}
+}
+
+// isVariableChar checks if ch may be part of a selector variable or argument key.
+func isVariableChar(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
@@ -0,0 +1,353 @@
+// Package selector implements parsing and evaluation of target selectors, such as
+// '@a[r=10,tag=!admin,scores={kills=1..}]', as entered for target arguments of commands.
+//
+// Parse turns a selector into a Selector, which holds every argument of the selector in a typed form.
+// Selector.Evaluate resolves the entities a Selector targets from a list of entities implementing the Entity
+// interface, so that servers and proxies may resolve targets in the same way as the game does, regardless of
+// the way they represent entities.
+package selector
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Variable is the variable of a selector, such as @a or @e.
+type Variable uint8
+
+const (
+	// VariableNearestPlayer is @p, which targets the nearest player.
+	VariableNearestPlayer Variable = iota
+	// VariableRandomPlayer is @r, which targets a random player.
+	VariableRandomPlayer
+	// VariableAllPlayers is @a, which targets all players.
+	VariableAllPlayers
+	// VariableAllEntities is @e, which targets all entities.
+	VariableAllEntities
+	// VariableSelf is @s, which targets the source of the command.
+	VariableSelf
+	// VariableInitiator is @initiator, which targets the player interacting with an NPC.
+	VariableInitiator
+	// VariableNearestEntity is @n, which targets the nearest entity.
+	VariableNearestEntity
+)
+
+// variables maps the names of all variables to their Variable.
+var variables = map[string]Variable{
+	"p":         VariableNearestPlayer,
+	"r":         VariableRandomPlayer,
+	"a":         VariableAllPlayers,
+	"e":         VariableAllEntities,
+	"s":         VariableSelf,
+	"initiator": VariableInitiator,
+	"n":         VariableNearestEntity,
+}
+
+// String ...
+func (v Variable) String() string {
+	for name, variable := range variables {
+		if variable == v {
+			return "@" + name
+		}
+	}
+	return fmt.Sprintf("Variable(%v)", uint8(v))
+}
+
+// Selector is a parsed target selector. The zero values of its fields mean the argument was not present.
+type Selector struct {
+	// Variable is the variable of the selector.
+	Variable Variable
+	// PlayerName is set if a player name was entered instead of a selector. In that case, it targets the
+	// online player with that name and all other fields are unset.
+	PlayerName string
+
+	// X, Y and Z are the x, y and z arguments, which replace the coordinates of the position of the source
+	// used for the distance and volume arguments.
+	X, Y, Z protocol.Optional[Coordinate]
+	// Distance holds the rm and r arguments: The minimum and maximum distance from the position.
+	Distance Range[float64]
+	// DX, DY and DZ are the dx, dy and dz arguments, which select the entities in the box between the
+	// position and the position with these offsets added.
+	DX, DY, DZ protocol.Optional[float64]
+	// XRotation holds the rxm and rx arguments: The minimum and maximum pitch.
+	XRotation Range[float64]
+	// YRotation holds the rym and ry arguments: The minimum and maximum yaw.
+	YRotation Range[float64]
+	// Level holds the lm and l arguments: The minimum and maximum experience level.
+	Level Range[int32]
+	// Count is the c argument, which limits the number of entities targeted. A negative count targets the
+	// furthest entities first.
+	Count protocol.Optional[int32]
+
+	// Types holds the type arguments, such as 'type=cow' or 'type=!minecraft:player'.
+	Types []Match
+	// Names holds the name arguments.
+	Names []Match
+	// Tags holds the tag arguments. An empty non-negated value matches entities without tags and an empty
+	// negated value matches entities with any tag.
+	Tags []Match
+	// Families holds the family arguments, such as 'family=monster'.
+	Families []Match
+	// GameModes holds the m arguments. Their values are one of the packet.GameType constants.
+	GameModes []GameModeMatch
+	// Scores holds the conditions in the scores argument.
+	Scores []ScoreMatch
+	// Items holds the conditions in the hasitem argument.
+	Items []ItemMatch
+	// Permissions holds the haspermission argument, mapping permissions such as 'movement' to whether they
+	// must be enabled.
+	Permissions map[string]bool
+}
+
+// Coordinate is a coordinate in the x, y or z argument of a selector.
+type Coordinate struct {
+	// Value is the value of the coordinate, or the offset from the position of the source if Relative is
+	// true.
+	Value float64
+	// Relative specifies if the coordinate was entered relative to the source using '~'.
+	Relative bool
+}
+
+// Range is an inclusive range of values, such as '1..5' or '3..'. A bound is only checked if it is set.
+type Range[T int32 | float64] struct {
+	Min, Max       T
+	HasMin, HasMax bool
+}
+
+// Contains checks if the value passed is within the range.
+func (r Range[T]) Contains(v T) bool {
+	return (!r.HasMin || v >= r.Min) && (!r.HasMax || v <= r.Max)
+}
+
+// Set checks if at least one bound of the range is set.
+func (r Range[T]) Set() bool {
+	return r.HasMin || r.HasMax
+}
+
+// String ...
+func (r Range[T]) String() string {
+	switch {
+	case r.HasMin && r.HasMax && r.Min == r.Max:
+		return formatNumber(r.Min)
+	case r.HasMin && r.HasMax:
+		return formatNumber(r.Min) + ".." + formatNumber(r.Max)
+	case r.HasMin:
+		return formatNumber(r.Min) + ".."
+	case r.HasMax:
+		return ".." + formatNumber(r.Max)
+	}
+	return ""
+}
+
+// Match is a value that a property of an entity must or, if Negated is true, must not have.
+type Match struct {
+	Value   string
+	Negated bool
+}
+
+// String ...
+func (m Match) String() string {
+	if m.Negated {
+		return "!" + quote(m.Value)
+	}
+	return quote(m.Value)
+}
+
+// GameModeMatch is a game mode that an entity must or, if Negated is true, must not have.
+type GameModeMatch struct {
+	// GameMode is one of the packet.GameType constants.
+	GameMode int32
+	Negated  bool
+}
+
+// ScoreMatch is a condition on the score of an entity for an objective.
+type ScoreMatch struct {
+	Objective string
+	Range     Range[int32]
+	// Negated specifies that the score must not be in Range.
+	Negated bool
+}
+
+// ItemMatch is a condition on the items an entity has, as found in the hasitem argument.
+type ItemMatch struct {
+	// Item is the name of the item, such as 'minecraft:diamond' or 'diamond'.
+	Item string
+	// Data is the metadata value the item must have.
+	Data protocol.Optional[int32]
+	// Quantity is the range that the total count of matching items must be in. If not set, at least one
+	// item must match.
+	Quantity Range[int32]
+	// QuantityNegated specifies that the count of matching items must not be in Quantity.
+	QuantityNegated bool
+	// Location is the equipment slot type the item must be in, such as 'slot.weapon.mainhand'.
+	Location string
+	// Slot is the range of slots within Location that the item must be in.
+	Slot Range[int32]
+}
+
+// gameModes maps the accepted values of the m argument to a packet.GameType constant.
+var gameModes = map[string]int32{
+	"0": packet.GameTypeSurvival, "s": packet.GameTypeSurvival, "survival": packet.GameTypeSurvival,
+	"1": packet.GameTypeCreative, "c": packet.GameTypeCreative, "creative": packet.GameTypeCreative,
+	"2": packet.GameTypeAdventure, "a": packet.GameTypeAdventure, "adventure": packet.GameTypeAdventure,
+	"5": packet.GameTypeDefault, "d": packet.GameTypeDefault, "default": packet.GameTypeDefault,
+	"6": packet.GameTypeSpectator, "spectator": packet.GameTypeSpectator,
+}
+
+// gameModeNames holds the name of every game mode written by Selector.String.
+var gameModeNames = map[int32]string{
+	packet.GameTypeSurvival:  "survival",
+	packet.GameTypeCreative:  "creative",
+	packet.GameTypeAdventure: "adventure",
+	packet.GameTypeDefault:   "default",
+	packet.GameTypeSpectator: "spectator",
+}
+
+// String returns the selector in the form it is entered in a command. Parsing the string returned results
+// in a Selector equal to s.
+func (s *Selector) String() string {
+	if s.PlayerName != "" {
+		return quote(s.PlayerName)
+	}
+	var args []string
+	add := func(key, value string) {
+		args = append(args, key+"="+value)
+	}
+	for _, c := range []struct {
+		key string
+		v   protocol.Optional[Coordinate]
+	}{{"x", s.X}, {"y", s.Y}, {"z", s.Z}} {
+		if v, ok := c.v.Value(); ok {
+			str := formatNumber(v.Value)
+			if v.Relative {
+				str = "~" + str
+				if v.Value == 0 {
+					str = "~"
+				}
+			}
+			add(c.key, str)
+		}
+	}
+	addRange(add, "rm", "r", s.Distance)
+	for _, d := range []struct {
+		key string
+		v   protocol.Optional[float64]
+	}{{"dx", s.DX}, {"dy", s.DY}, {"dz", s.DZ}} {
+		if v, ok := d.v.Value(); ok {
+			add(d.key, formatNumber(v))
+		}
+	}
+	addRange(add, "rxm", "rx", s.XRotation)
+	addRange(add, "rym", "ry", s.YRotation)
+	addRange(add, "lm", "l", s.Level)
+	if c, ok := s.Count.Value(); ok {
+		add("c", strconv.Itoa(int(c)))
+	}
+	for _, m := range s.Types {
+		add("type", m.String())
+	}
+	for _, m := range s.Names {
+		add("name", m.String())
+	}
+	for _, m := range s.Tags {
+		add("tag", m.String())
+	}
+	for _, m := range s.Families {
+		add("family", m.String())
+	}
+	for _, m := range s.GameModes {
+		name := gameModeNames[m.GameMode]
+		if m.Negated {
+			name = "!" + name
+		}
+		add("m", name)
+	}
+	if len(s.Scores) > 0 {
+		scores := make([]string, len(s.Scores))
+		for i, m := range s.Scores {
+			r := m.Range.String()
+			if m.Negated {
+				r = "!" + r
+			}
+			scores[i] = quote(m.Objective) + "=" + r
+		}
+		add("scores", "{"+strings.Join(scores, ",")+"}")
+	}
+	if len(s.Items) > 0 {
+		items := make([]string, len(s.Items))
+		for i, m := range s.Items {
+			items[i] = m.String()
+		}
+		if len(items) == 1 {
+			add("hasitem", items[0])
+		} else {
+			add("hasitem", "["+strings.Join(items, ",")+"]")
+		}
+	}
+	if len(s.Permissions) > 0 {
+		var permissions []string
+		for _, name := range slices.Sorted(maps.Keys(s.Permissions)) {
+			state := "disabled"
+			if s.Permissions[name] {
+				state = "enabled"
+			}
+			permissions = append(permissions, name+"="+state)
+		}
+		add("haspermission", "{"+strings.Join(permissions, ",")+"}")
+	}
+	if len(args) == 0 {
+		return s.Variable.String()
+	}
+	return s.Variable.String() + "[" + strings.Join(args, ",") + "]"
+}
+
+// String ...
+func (m ItemMatch) String() string {
+	args := []string{"item=" + quote(m.Item)}
+	if data, ok := m.Data.Value(); ok {
+		args = append(args, "data="+strconv.Itoa(int(data)))
+	}
+	if m.Quantity.Set() {
+		q := m.Quantity.String()
+		if m.QuantityNegated {
+			q = "!" + q
+		}
+		args = append(args, "quantity="+q)
+	}
+	if m.Location != "" {
+		args = append(args, "location="+m.Location)
+	}
+	if m.Slot.Set() {
+		args = append(args, "slot="+m.Slot.String())
+	}
+	return "{" + strings.Join(args, ",") + "}"
+}
+
+// addRange adds the arguments for the minimum and maximum of a range, if set.
+func addRange[T int32 | float64](add func(key, value string), minKey, maxKey string, r Range[T]) {
+	if r.HasMin {
+		add(minKey, formatNumber(r.Min))
+	}
+	if r.HasMax {
+		add(maxKey, formatNumber(r.Max))
+	}
+}
+
+// formatNumber formats a number in the shortest form that parses back to the same value.
+func formatNumber[T int32 | float64](v T) string {
+	return strconv.FormatFloat(float64(v), 'f', -1, 64)
+}
+
+// quote returns s in double quotes if it cannot be written in a selector without them.
+func quote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " ,=[]{}\"!") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -176,6 +176,12 @@ type Conn struct {
 	// ignoredResourcePacks is a slice of resource packs that are not being downloaded due to the downloadResourcePack
 	// func returning false for the specific pack.
 	ignoredResourcePacks []exemptedResourcePack
+	// downloadResourcePackProgress is an optional function passed to a Dial() call. If set, it is called with
+	// the number of bytes downloaded every time a chunk of a resource pack is received.
+	downloadResourcePackProgress func(id uuid.UUID, version string, downloaded, total uint64)
+	// resourcePackDownloadWindow is the number of chunk requests a Dialer keeps in flight for every resource
+	// pack it downloads.
+	resourcePackDownloadWindow int
 	// resourcePackCache, if set, stores resource packs downloaded by a Dialer for reuse on later logins.
 	resourcePackCache ResourcePackCache
 	// resourcePackDelivery controls how a Listener connection sends resource pack data.
@@ -999,20 +1005,21 @@ func (conn *Conn) handleResourcePacksInfo(pk *packet.ResourcePacksInfo) error {
 			Version: pack.Version,
 			Size:    pack.Size,
 		}
+		var cachedPack *resource.Pack
 		if conn.resourcePackCache != nil {
-			cachedPack, err := conn.resourcePackCache.Load(conn.ctx, cacheKey)
+			var err error
+			cachedPack, err = conn.resourcePackCache.Load(conn.ctx, cacheKey)
 			switch {
 			case err != nil:
 				conn.log.Warn("handle ResourcePacksInfo: failed to load resource pack from cache", "UUID", pack.UUID, "version", pack.Version, "err", err)
 			case cachedPack != nil && !cacheKey.Matches(cachedPack):
 				conn.log.Warn("handle ResourcePacksInfo: cached resource pack did not match advertised pack", "UUID", pack.UUID, "version", pack.Version, "cached_UUID", cachedPack.UUID(), "cached_version", cachedPack.Version(), "cached_size", cachedPack.Len())
-			case cachedPack != nil:
-				conn.resourcePacks = append(conn.resourcePacks, cachedPack.WithContentKey(pack.ContentKey))
-				conn.packQueue.packAmount--
-				continue
+				cachedPack = nil
 			}
 		}
 
+		// A cached pack is still requested: Only the ResourcePackDataInfo packet holds the checksum that the
+		// cached pack must match to be used instead of downloading it.
 		// This UUID_Version is a hack Mojang put in place.
 		packsToDownload = append(packsToDownload, id+"_"+pack.Version)
 		conn.packQueue.downloadingPacks[id] = downloadingPack{
@@ -1021,6 +1028,7 @@ func (conn *Conn) handleResourcePacksInfo(pk *packet.ResourcePacksInfo) error {
 			newFrag:    make(chan []byte),
 			contentKey: pack.ContentKey,
 			cacheKey:   cacheKey,
+			cached:     cachedPack,
 		}
 	}
 
@@ -1231,62 +1239,245 @@ func (conn *Conn) handleResourcePackDataInfo(pk *packet.ResourcePackDataInfo) er
 		conn.log.Warn("handle ResourcePackDataInfo: pack had a different size in ResourcePacksInfo than in ResourcePackDataInfo", "UUID", id)
 		pack.size = pk.Size
 	}
+	// Any pack held because it matched a cached pack can be skipped now: The server sent the data info of
+	// another pack without waiting for the chunks of the held pack to be requested.
+	conn.packMu.Lock()
+	held := conn.packQueue.cachedPacks
+	conn.packQueue.cachedPacks = nil
+	if len(held) > 0 {
+		conn.packQueue.parallel = true
+	}
+	parallel := conn.packQueue.parallel
+	conn.packMu.Unlock()
+	for _, c := range held {
+		conn.addResourcePack(c.pack.cached.WithContentKey(c.pack.contentKey))
+	}
+
+	if pack.cached != nil {
+		if checksum := pack.cached.Checksum(); !bytes.Equal(checksum[:], pk.Hash) {
+			// The server changed the content of the pack without changing its version: Evict the stale entry
+			// and download the pack again.
+			conn.log.Warn("handle ResourcePackDataInfo: cached resource pack did not match advertised checksum", "UUID", id)
+			if cache, ok := conn.resourcePackCache.(EvictingResourcePackCache); ok {
+				if err := cache.Delete(conn.ctx, pack.cacheKey); err != nil {
+					conn.log.Warn("handle ResourcePackDataInfo: failed to delete resource pack from cache", "UUID", id, "err", err)
+				}
+			}
+			pack.cached = nil
+		} else if parallel {
+			delete(conn.packQueue.downloadingPacks, id)
+			conn.addResourcePack(pack.cached.WithContentKey(pack.contentKey))
+			return nil
+		}
+	}
+	if pk.DataChunkSize == 0 {
+		return fmt.Errorf("handle ResourcePackDataInfo: chunk size of pack (UUID=%v) was 0", id)
+	}
 
 	// Remove the resource pack from the downloading packs and add it to the awaiting packets.
 	delete(conn.packQueue.downloadingPacks, id)
 	conn.packQueue.awaitingPacks[id] = &pack
 
 	pack.chunkSize = pk.DataChunkSize
+	pack.checksum = pk.Hash
+	if pack.cached != nil {
+		// Servers that send one ResourcePackDataInfo at a time wait for the pack to be downloaded before
+		// sending the next, so the cached pack is only used once another data info arrives. If none arrives
+		// in time, the pack is downloaded after all.
+		conn.packMu.Lock()
+		conn.packQueue.cachedPacks = append(conn.packQueue.cachedPacks, cachedResourcePack{rawID: pk.UUID, id: id, pack: &pack})
+		if len(conn.packQueue.cachedPacks) == 1 {
+			time.AfterFunc(resourcePackSkipTimeout, conn.downloadCachedResourcePacks)
+		}
+		conn.packMu.Unlock()
+		return nil
+	}
+	conn.resumeResourcePack(&pack)
+
+	go conn.downloadResourcePackChunks(pk.UUID, id, &pack)
+	return nil
+}
+
+// resourcePackSkipTimeout is the time a Dialer waits for another ResourcePackDataInfo before downloading a
+// resource pack that it has cached.
+const resourcePackSkipTimeout = time.Second / 2
+
+// downloadCachedResourcePacks downloads the packs held because they matched a cached pack, if the server did
+// not send another ResourcePackDataInfo while they were held. Servers that only send the next data info once
+// all chunks of a pack are requested otherwise never complete the download.
+func (conn *Conn) downloadCachedResourcePacks() {
+	conn.packMu.Lock()
+	held := conn.packQueue.cachedPacks
+	conn.packQueue.cachedPacks = nil
+	conn.packMu.Unlock()
+
+	if conn.ctx.Err() != nil {
+		return
+	}
+	for _, c := range held {
+		c.pack.cached = nil
+		go conn.downloadResourcePackChunks(c.rawID, c.id, c.pack)
+	}
+}
 
+// resumeResourcePack fills the buffer of pack with the chunks downloaded on an earlier connection, if the
+// Conn has a ResumableResourcePackCache with a partial download of the pack.
+func (conn *Conn) resumeResourcePack(pack *downloadingPack) {
+	cache, ok := conn.resourcePackCache.(ResumableResourcePackCache)
+	if !ok {
+		return
+	}
+	data, err := cache.LoadPartial(conn.ctx, pack.cacheKey, pack.checksum)
+	if err != nil {
+		conn.log.Warn("download resource pack: failed to load partial download from cache", "UUID", pack.cacheKey.UUID, "err", err)
+		return
+	}
+	// Only complete chunks can be resumed: The chunk size may also have changed since the data was stored.
+	completed := uint64(len(data)) / uint64(pack.chunkSize)
+	if completed*uint64(pack.chunkSize) > pack.size {
+		return
+	}
+	pack.buf.Write(data[:completed*uint64(pack.chunkSize)])
+	pack.expectedIndex = uint32(completed)
+}
+
+// downloadResourcePackChunks requests the chunks of pack from the server, keeping up to the download window
+// of chunk requests in flight, and adds the pack to the Conn once all of its chunks are received. A download
+// resumed from a partial download that turns out to be invalid is restarted from the first chunk.
+func (conn *Conn) downloadResourcePackChunks(rawID, id string, pack *downloadingPack) {
 	// The client calculates the chunk count by itself: You could in theory send a chunk count of 0 even
 	// though there's data, and the client will still download normally.
-	chunkCount := int32(pk.Size / uint64(pk.DataChunkSize))
-	if pk.Size%uint64(pk.DataChunkSize) != 0 {
+	chunkCount := uint32(pack.size / uint64(pack.chunkSize))
+	if pack.size%uint64(pack.chunkSize) != 0 {
 		chunkCount++
 	}
+	cache, resumable := conn.resourcePackCache.(ResumableResourcePackCache)
+	storePartial := resumable
 
-	idCopy := pk.UUID
-	go func() {
-		for i := int32(0); i < chunkCount; i++ {
-			_ = conn.WritePacket(&packet.ResourcePackChunkRequest{
-				UUID:       idCopy,
-				ChunkIndex: i,
-			})
-			select {
-			case <-conn.ctx.Done():
+	for {
+		resumed := pack.expectedIndex != 0
+		storePartial = conn.requestResourcePackChunks(rawID, id, pack, chunkCount, storePartial)
+		if conn.ctx.Err() != nil {
+			return
+		}
+		if resumable {
+			conn.deletePartialResourcePack(cache, pack, id)
+		}
+		newPack, err := conn.readResourcePack(pack)
+		if err == nil {
+			checksum := newPack.Checksum()
+			valid := bytes.Equal(checksum[:], pack.checksum)
+			if valid || !resumed {
+				conn.addResourcePack(newPack)
+				if !valid {
+					// The pack is still usable for this connection, but storing it would make the cache hand
+					// out a pack the server never advertised.
+					conn.log.Warn("download resource pack: checksum did not match ResourcePackDataInfo, not caching pack", "UUID", id)
+					return
+				}
+				conn.storeResourcePack(pack.cacheKey, newPack)
 				return
-			case frag := <-pack.newFrag:
-				// Write the fragment to the full buffer of the downloading resource pack.
-				_, _ = pack.buf.Write(frag)
 			}
+			err = fmt.Errorf("checksum did not match ResourcePackDataInfo")
 		}
-		conn.packMu.Lock()
-		if pack.buf.Len() != int(pack.size) {
-			conn.log.Error(fmt.Sprintf("download resource pack: incorrect resource pack size: expected %v, got %v", pack.size, pack.buf.Len()), "UUID", id)
-			conn.packMu.Unlock()
-			return
-		}
-		// First parse the resource pack from the total byte buffer we obtained.
-		newPack, err := resource.Read(pack.buf)
-		if err != nil {
-			conn.log.Error("download resource pack: invalid full resource pack data: "+err.Error(), "UUID", id)
-			conn.packMu.Unlock()
+		if !resumed {
+			conn.log.Error("download resource pack: "+err.Error(), "UUID", id)
 			return
 		}
-		newPack = newPack.WithContentKey(pack.contentKey)
-		conn.packQueue.packAmount--
-		// Finally we add the resource to the resource packs slice.
-		conn.resourcePacks = append(conn.resourcePacks, newPack)
-		packAmount := conn.packQueue.packAmount
+		// The chunks resumed from the cache were corrupted: Download the pack again from the first chunk.
+		conn.log.Warn("download resource pack: resumed download was invalid, downloading again: "+err.Error(), "UUID", id)
+		pack.buf.Reset()
+		conn.packMu.Lock()
+		pack.expectedIndex = 0
 		conn.packMu.Unlock()
+	}
+}
+
+// requestResourcePackChunks requests the chunks of pack not yet received, keeping up to the download window
+// of chunk requests in flight, until all of them are received or the Conn is closed. If resumable is true,
+// received chunks are stored as partial download in the ResumableResourcePackCache of the Conn.
+// requestResourcePackChunks returns false if storing a chunk failed, after which no more chunks are stored.
+func (conn *Conn) requestResourcePackChunks(rawID, id string, pack *downloadingPack, chunkCount uint32, resumable bool) bool {
+	window := uint32(max(conn.resourcePackDownloadWindow, 1))
+	cache, _ := conn.resourcePackCache.(ResumableResourcePackCache)
 
-		if packAmount == 0 {
-			conn.expect(packet.IDResourcePackStack)
-			_ = conn.WritePacket(&packet.ResourcePackClientResponse{Response: packet.PackResponseAllPacksDownloaded})
+	start := pack.expectedIndex
+	received, requested := start, start
+	conn.resourcePackProgress(pack, uint64(pack.buf.Len()))
+	for received < chunkCount {
+		for ; requested < chunkCount && requested-received < window; requested++ {
+			_ = conn.WritePacket(&packet.ResourcePackChunkRequest{
+				UUID:       rawID,
+				ChunkIndex: int32(requested),
+			})
 		}
-		conn.storeResourcePack(pack.cacheKey, newPack)
-	}()
-	return nil
+		select {
+		case <-conn.ctx.Done():
+			if resumable && start != 0 && received == start {
+				// The server may have closed the connection because it does not support resuming a download
+				// from a chunk other than the first: Start over on the next login.
+				conn.deletePartialResourcePack(cache, pack, id)
+			}
+			return resumable
+		case frag := <-pack.newFrag:
+			offset := uint64(pack.buf.Len())
+			// Write the fragment to the full buffer of the downloading resource pack.
+			_, _ = pack.buf.Write(frag)
+			received++
+			if resumable && received < chunkCount {
+				if err := cache.StorePartial(conn.ctx, pack.cacheKey, pack.checksum, offset, frag); err != nil {
+					conn.log.Warn("download resource pack: failed to store partial download in cache", "UUID", id, "err", err)
+					resumable = false
+				}
+			}
+			conn.resourcePackProgress(pack, uint64(pack.buf.Len()))
+		}
+	}
+	return resumable
+}
+
+// readResourcePack reads the resource pack from the data downloaded for pack. An error is returned if the
+// data is incomplete or invalid.
+func (conn *Conn) readResourcePack(pack *downloadingPack) (*resource.Pack, error) {
+	if pack.buf.Len() != int(pack.size) {
+		return nil, fmt.Errorf("incorrect resource pack size: expected %v, got %v", pack.size, pack.buf.Len())
+	}
+	newPack, err := resource.Read(bytes.NewReader(pack.buf.Bytes()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid full resource pack data: %w", err)
+	}
+	return newPack.WithContentKey(pack.contentKey), nil
+}
+
+// deletePartialResourcePack deletes the partial download of pack from the cache passed. The partial download
+// is also deleted if the Conn is already closed.
+func (conn *Conn) deletePartialResourcePack(cache ResumableResourcePackCache, pack *downloadingPack, id string) {
+	if err := cache.DeletePartial(context.WithoutCancel(conn.ctx), pack.cacheKey); err != nil {
+		conn.log.Warn("download resource pack: failed to delete partial download from cache", "UUID", id, "err", err)
+	}
+}
+
+// addResourcePack adds a pack downloaded from the server or loaded from the cache to the resource packs of
+// the Conn. Once all packs are added, the server is notified that all packs were downloaded.
+func (conn *Conn) addResourcePack(pack *resource.Pack) {
+	conn.packMu.Lock()
+	conn.packQueue.packAmount--
+	conn.resourcePacks = append(conn.resourcePacks, pack)
+	packAmount := conn.packQueue.packAmount
+	conn.packMu.Unlock()
+
+	if packAmount == 0 {
+		conn.expect(packet.IDResourcePackStack)
+		_ = conn.WritePacket(&packet.ResourcePackClientResponse{Response: packet.PackResponseAllPacksDownloaded})
+	}
+}
+
+// resourcePackProgress calls the resource pack progress function of the Conn, if set, with the number of
+// bytes of pack downloaded so far.
+func (conn *Conn) resourcePackProgress(pack *downloadingPack, downloaded uint64) {
+	if conn.downloadResourcePackProgress != nil {
+		conn.downloadResourcePackProgress(pack.cacheKey.UUID, pack.cacheKey.Version, downloaded, pack.size)
+	}
 }
 
 // handleResourcePackChunkData handles a resource pack chunk data packet, which holds a fragment of a resource
@@ -1299,17 +1490,26 @@ func (conn *Conn) handleResourcePackChunkData(pk *packet.ResourcePackChunkData)
 		// download a resource pack.
 		return fmt.Errorf("chunk data for resource pack that was not being downloaded")
 	}
-	lastData := pack.buf.Len()+int(pack.chunkSize) >= int(pack.size)
+	// The download goroutine resets the expected index when it restarts an invalid resumed download.
+	conn.packMu.Lock()
+	expectedIndex := pack.expectedIndex
+	if pk.ChunkIndex == expectedIndex {
+		pack.expectedIndex++
+	}
+	conn.packMu.Unlock()
+	if pk.ChunkIndex != expectedIndex {
+		return fmt.Errorf("expected chunk index %v, got %v", expectedIndex, pk.ChunkIndex)
+	}
+	lastData := uint64(pk.ChunkIndex+1)*uint64(pack.chunkSize) >= pack.size
 	if !lastData && uint32(len(pk.Data)) != pack.chunkSize {
 		// The chunk data didn't have the full size and wasn't the last data to be sent for the resource pack,
 		// meaning we got too little data.
 		return fmt.Errorf("expected chunk size %v, got %v", pack.chunkSize, len(pk.Data))
 	}
-	if pk.ChunkIndex != pack.expectedIndex {
-		return fmt.Errorf("expected chunk index %v, got %v", pack.expectedIndex, pk.ChunkIndex)
+	select {
+	case <-conn.ctx.Done():
+	case pack.newFrag <- pk.Data:
 	}
-	pack.expectedIndex++
-	pack.newFrag <- pk.Data
 	return nil
 }
 
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/df-mc/go-nethernet"
 	"github.com/df-mc/go-playfab/v2"
 	"github.com/df-mc/go-xsapi/v2"
 	"github.com/go-jose/go-jose/v4"
@@ -54,13 +55,15 @@ type Dialer struct {
 	// IdentityData is the identity data used to login to the server with. It includes the username, UUID and
 	// XUID of the player.
 	// The IdentityData object is obtained using Minecraft auth if Email and Password are set. If not, the
-	// object provided here is used, or a default one if left empty.
+	// object provided here is used, or a default one if left empty. When logging in using only a
+	// MultiplayerTokenSource, it is obtained from the claims of the multiplayer token.
 	IdentityData login.IdentityData
 
 	// TokenSource is the source for Microsoft Live Connect tokens. If set to a non-nil oauth2.TokenSource,
 	// this field is used to obtain tokens which in turn are used to authenticate to XBOX Live.
 	// The minecraft/auth package provides an oauth2.TokenSource implementation (auth.tokenSource) to use
 	// device auth to login.
+	// If TokenSource implements ChainSource, it is also used to request the Minecraft authentication chain.
 	// If TokenSource is nil, the connection will not use authentication.
 	TokenSource oauth2.TokenSource
 
@@ -79,6 +82,27 @@ type Dialer struct {
 	// or [Dialer.TokenSource] to request the legacy Minecraft chain used to populate trusted identity data.
 	PlayFabClient *playfab.Client
 
+	// MultiplayerTokenSource supplies the multiplayer token used to log in when [Dialer.EnableLegacyAuth] is
+	// false, such as a [service.IssuerTokenSource] of a self-hosted [service.Issuer]. If nil,
+	// [Dialer.TokenSource] is used if it implements MultiplayerTokenSource, and a token is requested from the
+	// authorization service of Minecraft otherwise.
+	//
+	// If MultiplayerTokenSource is set while [Dialer.TokenSource] and [Dialer.XBLClient] are nil, the dialer
+	// logs in using only the multiplayer token, without logging in to Xbox Live.
+	MultiplayerTokenSource MultiplayerTokenSource
+
+	// RealmSignaling is the signaling used by [Dialer.DialRealmID] and [Dialer.DialRealmInvite] to connect
+	// to realms that are hosted over NetherNet (NETHERNET) instead of RakNet. It must exchange signals through
+	// the signaling service of Minecraft, authenticated as the same user as the Dialer. If nil, dialing such
+	// realms fails. Realms hosted over NetherNet with JSON-RPC signaling (NETHERNET_JSONRPC) cannot be
+	// dialed, regardless of RealmSignaling.
+	RealmSignaling nethernet.Signaling
+
+	// ServiceCache, if non-nil, is used to discover the authorization service of Minecraft when requesting
+	// multiplayer tokens, so that service discovery does not require a request for every process. It may be
+	// shared with the [ListenConfig.ServiceCache] of a Listener.
+	ServiceCache *service.Cache
+
 	// PacketFunc is called whenever a packet is read from or written to the connection returned when using
 	// Dialer.Dial(). It includes packets that are otherwise covered in the connection sequence, such as the
 	// Login packet. The function is called with the header of the packet and its raw payload, the address
@@ -90,8 +114,18 @@ type Dialer struct {
 	// and version of the resource pack, the number of the current pack being downloaded, and the total amount of packs.
 	// The boolean returned determines if the pack will be downloaded or not.
 	DownloadResourcePack func(id uuid.UUID, version string, current, total int) bool
+	// DownloadResourcePackProgress is called every time a chunk of a texture or behaviour pack is received when
+	// using Dialer.Dial(). The function is called with the UUID and version of the resource pack, the number of
+	// bytes of the pack downloaded so far, including bytes resumed from an earlier download, and the total size
+	// of the pack. Packs are downloaded concurrently, so the function may be called from multiple goroutines.
+	DownloadResourcePackProgress func(id uuid.UUID, version string, downloaded, total uint64)
+	// ResourcePackDownloadWindow is the number of ResourcePackChunkRequests kept in flight for every resource
+	// pack being downloaded. Chunks of all packs the server sent a ResourcePackDataInfo for are requested
+	// concurrently. Values below 1 request one chunk at a time.
+	ResourcePackDownloadWindow int
 	// ResourcePackCache, if set, reuses resource packs downloaded on earlier logins. Misses and errors
-	// fall back to a normal download.
+	// fall back to a normal download. If the cache also implements ResumableResourcePackCache, downloads
+	// interrupted by a disconnect resume from the last completed chunk on the next login.
 	ResourcePackCache ResourcePackCache
 
 	// DisconnectOnUnknownPackets specifies if the connection should disconnect if packets received are not present
@@ -229,8 +263,8 @@ func (d Dialer) DialContextNetwork(ctx context.Context, network Network, address
 			}
 			defer d.XBLClient.Close()
 		}
-		if !d.EnableLegacyAuth {
-			e, err := authEnv(ctx)
+		if !d.EnableLegacyAuth && d.MultiplayerTokenSource == nil {
+			e, err := authEnv(ctx, d.ServiceCache)
 			if err != nil {
 				return nil, &net.OpError{Op: "dial", Net: "minecraft", Err: fmt.Errorf("request authorization environment: %w", err)}
 			}
@@ -262,7 +296,11 @@ func (d Dialer) DialContextNetwork(ctx context.Context, network Network, address
 				return nil, &net.OpError{Op: "dial", Net: "minecraft", Err: err}
 			}
 		}
-		chainData, err = auth.RequestMinecraftChain(ctx, d.XBLClient, key)
+		if c, ok := d.TokenSource.(ChainSource); ok {
+			chainData, err = c.MinecraftChain(ctx, key)
+		} else {
+			chainData, err = auth.RequestMinecraftChain(ctx, d.XBLClient, key)
+		}
 		if err != nil {
 			return nil, &net.OpError{Op: "dial", Net: "minecraft", Err: fmt.Errorf("request Minecraft auth chain: %w", err)}
 		}
@@ -272,6 +310,20 @@ func (d Dialer) DialContextNetwork(ctx context.Context, network Network, address
 		}
 		d.IdentityData = identityData
 	}
+	if d.MultiplayerTokenSource != nil && !d.EnableLegacyAuth {
+		token, err = d.MultiplayerTokenSource.MultiplayerToken(ctx, &key.PublicKey)
+		if err != nil {
+			return nil, &net.OpError{Op: "dial", Net: "minecraft", Err: err}
+		}
+		if chainData == "" {
+			// Without a chain, the identity of the player is only held by the claims of the token.
+			identityData, err := login.ParseTokenIdentityData(token)
+			if err != nil {
+				return nil, &net.OpError{Op: "dial", Net: "minecraft", Err: err}
+			}
+			d.IdentityData = identityData
+		}
+	}
 
 	var pong []byte
 	if pong, err = network.PingContext(ctx, address); err == nil {
@@ -294,6 +346,8 @@ func (d Dialer) DialContextNetwork(ctx context.Context, network Network, address
 	conn.clientData = d.ClientData
 	conn.packetFunc = d.PacketFunc
 	conn.downloadResourcePack = d.DownloadResourcePack
+	conn.downloadResourcePackProgress = d.DownloadResourcePackProgress
+	conn.resourcePackDownloadWindow = d.ResourcePackDownloadWindow
 	conn.resourcePackCache = d.ResourcePackCache
 	conn.cacheEnabled = d.EnableClientCache
 	conn.disconnectOnInvalidPacket = d.DisconnectOnInvalidPackets
@@ -0,0 +1,84 @@
+package minecraft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"github.com/sandertv/gophertunnel/minecraft/internal"
+	"github.com/sandertv/gophertunnel/minecraft/realms"
+)
+
+// ErrRealmNetworkUnsupported is returned by Dialer.DialRealmInvite and Dialer.DialRealmID if a realm is
+// hosted using a network protocol that the Dialer cannot connect over. It is wrapped in a net.OpError.
+var ErrRealmNetworkUnsupported = errors.New("realm network protocol not supported")
+
+// DialRealmInvite dials a Minecraft connection to the realm with the invite code passed, using the
+// realms.Client passed to look it up. It otherwise behaves like Dialer.DialRealmID.
+func (d Dialer) DialRealmInvite(ctx context.Context, client *realms.Client, code string) (*Conn, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if client == nil {
+		return nil, &net.OpError{Op: "dial", Net: "minecraft", Err: errors.New("dial realm: realms client is nil")}
+	}
+	r, err := client.Realm(ctx, code)
+	if err != nil {
+		return nil, &net.OpError{Op: "dial", Net: "minecraft", Err: fmt.Errorf("look up realm %q: %w", code, err)}
+	}
+	return d.DialRealmID(ctx, client, r.ID)
+}
+
+// DialRealmID dials a Minecraft connection to the realm with the ID passed, as found in realms.Realm, using
+// the realms.Client passed. If the realm is offline, DialRealmID waits for it to start until the context
+// passed is cancelled.
+//
+// The network used is selected based on the network protocol of the realm. Only realms hosted over RakNet
+// are joined without further configuration. Realms hosted over NetherNet (NETHERNET) are only joined if
+// [Dialer.RealmSignaling] is set. Realms hosted over NetherNet with JSON-RPC signaling (NETHERNET_JSONRPC)
+// are not supported. For realms that cannot be joined, DialRealmID returns an error wrapping
+// ErrRealmNetworkUnsupported.
+func (d Dialer) DialRealmID(ctx context.Context, client *realms.Client, id int) (*Conn, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if d.ErrorLog == nil {
+		d.ErrorLog = slog.New(internal.DiscardHandler{})
+	}
+	if client == nil {
+		return nil, &net.OpError{Op: "dial", Net: "minecraft", Err: errors.New("dial realm: realms client is nil")}
+	}
+	// RealmAddress keeps polling the realm while it is starting, until it is ready to be joined.
+	address, err := client.RealmAddress(ctx, id)
+	if err != nil {
+		return nil, &net.OpError{Op: "dial", Net: "minecraft", Err: fmt.Errorf("request address of realm %v: %w", id, err)}
+	}
+	n, err := d.realmNetwork(address.NetworkProtocol)
+	if err != nil {
+		return nil, &net.OpError{Op: "dial", Net: "minecraft", Err: fmt.Errorf("dial realm %v: %w", id, err)}
+	}
+	return d.DialContextNetwork(ctx, n, address.Address)
+}
+
+// realmNetwork returns the Network used to dial a realm hosted using the network protocol passed.
+func (d Dialer) realmNetwork(protocol realms.NetworkProtocol) (Network, error) {
+	switch realms.ParseNetworkProtocol(string(protocol)) {
+	case realms.NetworkProtocolDefault, "":
+		n, ok := networkByID("raknet", d.ErrorLog)
+		if !ok {
+			return nil, errors.New("no network under id raknet")
+		}
+		return n, nil
+	case realms.NetworkProtocolNetherNet:
+		if d.RealmSignaling == nil {
+			return nil, fmt.Errorf("%w: realm is hosted over %v, but Dialer.RealmSignaling is nil", ErrRealmNetworkUnsupported, protocol)
+		}
+		return NetherNet{Signaling: d.RealmSignaling, Log: d.ErrorLog}, nil
+	default:
+		// NETHERNET_JSONRPC realms exchange signals using a JSON-RPC protocol that RealmSignaling does not
+		// implement.
+		return nil, fmt.Errorf("%w: %q", ErrRealmNetworkUnsupported, protocol)
+	}
+}
@@ -1,10 +1,14 @@
 package minecraft_test
 
 import (
+	"context"
 	"fmt"
+	"time"
+
 	"github.com/sandertv/gophertunnel/minecraft"
 	"github.com/sandertv/gophertunnel/minecraft/auth"
 	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+	"github.com/sandertv/gophertunnel/minecraft/realms"
 )
 
 func ExampleDial() {
@@ -52,3 +56,24 @@ func ExampleDial() {
 		}
 	}
 }
+
+func ExampleDialer_DialRealmInvite() {
+	// Create a realms.Client and a minecraft.Dialer that authenticate as the same user.
+	client := realms.NewClient(auth.TokenSource, nil)
+	dialer := minecraft.Dialer{
+		TokenSource: auth.TokenSource,
+	}
+	// Join the realm with the invite code passed. If the realm is offline, DialRealmInvite waits for it to
+	// start until the context is cancelled.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	conn, err := dialer.DialRealmInvite(ctx, client, "AbCdEfGhIjK")
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	if err := conn.DoSpawn(); err != nil {
+		panic(err)
+	}
+}
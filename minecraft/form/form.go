@@ -0,0 +1,276 @@
+// Package form implements typed builders for the forms sent in a packet.ModalFormRequest and decoding of the
+// packet.ModalFormResponse sent back by the client.
+//
+// Three kinds of forms exist: A Menu, which has a list of buttons, a Modal, which has two buttons, and a
+// Custom form, which has a list of elements such as inputs, toggles and sliders. Each of them is turned into
+// a packet using NewRequest and has a ParseResponse method that decodes the response of the client into
+// typed values, validating them against the form.
+package form
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Form is a form that may be sent to a client: A Menu, Modal or Custom.
+type Form interface {
+	json.Marshaler
+	form()
+}
+
+// NewRequest returns a packet.ModalFormRequest that opens the form passed with the form ID passed. The same
+// form ID is sent back by the client in its packet.ModalFormResponse.
+func NewRequest(id uint32, f Form) (*packet.ModalFormRequest, error) {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, fmt.Errorf("encode form: %w", err)
+	}
+	return &packet.ModalFormRequest{FormID: id, FormData: data}, nil
+}
+
+const (
+	// ImageTypePath is the type of an Image that points to a texture in a resource pack, such as
+	// 'textures/items/diamond'.
+	ImageTypePath = "path"
+	// ImageTypeURL is the type of an Image that points to an image on the web.
+	ImageTypeURL = "url"
+)
+
+// Image is an image shown on a button of a Menu.
+type Image struct {
+	// Type is the type of the image: ImageTypePath or ImageTypeURL.
+	Type string `json:"type"`
+	// Data is the path or URL of the image.
+	Data string `json:"data"`
+}
+
+// Button is a button of a Menu.
+type Button struct {
+	// Text is the text shown on the button.
+	Text string `json:"text"`
+	// Image is the image shown on the button. It is optional.
+	Image *Image `json:"image,omitempty"`
+}
+
+// Menu is a form with a title, body text and a list of buttons, of which the player may click one.
+type Menu struct {
+	Title   string
+	Content string
+	Buttons []Button
+}
+
+// MarshalJSON ...
+func (m Menu) MarshalJSON() ([]byte, error) {
+	buttons := m.Buttons
+	if buttons == nil {
+		buttons = []Button{}
+	}
+	return json.Marshal(struct {
+		Type    string   `json:"type"`
+		Title   string   `json:"title"`
+		Content string   `json:"content"`
+		Buttons []Button `json:"buttons"`
+	}{Type: "form", Title: m.Title, Content: m.Content, Buttons: buttons})
+}
+
+// Modal is a form with a title, body text and two buttons, such as 'Yes' and 'No'.
+type Modal struct {
+	Title   string
+	Content string
+	// Button1 is the text of the first button. A response of true means this button was clicked.
+	Button1 string
+	// Button2 is the text of the second button. A response of false means this button was clicked.
+	Button2 string
+}
+
+// MarshalJSON ...
+func (m Modal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type    string `json:"type"`
+		Title   string `json:"title"`
+		Content string `json:"content"`
+		Button1 string `json:"button1"`
+		Button2 string `json:"button2"`
+	}{Type: "modal", Title: m.Title, Content: m.Content, Button1: m.Button1, Button2: m.Button2})
+}
+
+// Custom is a form with a title and a list of elements, such as inputs, toggles and sliders, which the player
+// fills out and submits.
+type Custom struct {
+	Title string
+	// Elements holds the elements of the form in the order they are shown.
+	Elements []Element
+	// Submit is the text of the submit button. If empty, the client shows its default text.
+	Submit string
+}
+
+// MarshalJSON ...
+func (c Custom) MarshalJSON() ([]byte, error) {
+	elements := c.Elements
+	if elements == nil {
+		elements = []Element{}
+	}
+	return json.Marshal(struct {
+		Type     string    `json:"type"`
+		Title    string    `json:"title"`
+		Elements []Element `json:"content"`
+		Submit   string    `json:"submit,omitempty"`
+	}{Type: "custom_form", Title: c.Title, Elements: elements, Submit: c.Submit})
+}
+
+func (Menu) form()   {}
+func (Modal) form()  {}
+func (Custom) form() {}
+
+// Element is an element of a Custom form: A Label, Header, Divider, Input, Toggle, Slider, Dropdown or
+// StepSlider.
+type Element interface {
+	json.Marshaler
+	// parse decodes the value submitted for the element, validating it against the element.
+	parse(data json.RawMessage) (any, error)
+}
+
+// Label is an element of text. Its value in a response is always nil.
+type Label struct {
+	Text string
+}
+
+// Header is an element of large text, used as the heading of a section of the form. Its value in a response
+// is always nil.
+type Header struct {
+	Text string
+}
+
+// Divider is an element that draws a horizontal line between other elements. Its value in a response is
+// always nil.
+type Divider struct{}
+
+// Input is a text field. Its value in a response is a string.
+type Input struct {
+	Text string
+	// Placeholder is the text shown in the field when it is empty.
+	Placeholder string
+	// Default is the text initially in the field.
+	Default string
+	// Tooltip is text shown when hovering over the element. It is optional.
+	Tooltip string
+}
+
+// Toggle is a switch that is either on or off. Its value in a response is a bool.
+type Toggle struct {
+	Text    string
+	Default bool
+	// Tooltip is text shown when hovering over the element. It is optional.
+	Tooltip string
+}
+
+// Slider is a slider to pick a number between a minimum and a maximum. Its value in a response is a float64.
+type Slider struct {
+	Text     string
+	Min, Max float64
+	// Step is the difference between two values the slider may be set to. If zero, 1 is used.
+	Step    float64
+	Default float64
+	// Tooltip is text shown when hovering over the element. It is optional.
+	Tooltip string
+}
+
+// Dropdown is a list of options of which one may be selected. Its value in a response is an int: The index
+// of the selected option.
+type Dropdown struct {
+	Text    string
+	Options []string
+	// Default is the index of the option initially selected.
+	Default int
+	// Tooltip is text shown when hovering over the element. It is optional.
+	Tooltip string
+}
+
+// StepSlider is a slider to pick one of a list of options. Its value in a response is an int: The index of
+// the selected option.
+type StepSlider struct {
+	Text    string
+	Options []string
+	// Default is the index of the option initially selected.
+	Default int
+	// Tooltip is text shown when hovering over the element. It is optional.
+	Tooltip string
+}
+
+// MarshalJSON ...
+func (l Label) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": "label", "text": l.Text})
+}
+
+// MarshalJSON ...
+func (h Header) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": "header", "text": h.Text})
+}
+
+// MarshalJSON ...
+func (Divider) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"type": "divider", "text": ""})
+}
+
+// MarshalJSON ...
+func (i Input) MarshalJSON() ([]byte, error) {
+	return json.Marshal(withTooltip(map[string]any{"type": "input", "text": i.Text, "placeholder": i.Placeholder, "default": i.Default}, i.Tooltip))
+}
+
+// MarshalJSON ...
+func (t Toggle) MarshalJSON() ([]byte, error) {
+	return json.Marshal(withTooltip(map[string]any{"type": "toggle", "text": t.Text, "default": t.Default}, t.Tooltip))
+}
+
+// MarshalJSON ...
+func (s Slider) MarshalJSON() ([]byte, error) {
+	if s.Min > s.Max || s.Default < s.Min || s.Default > s.Max {
+		return nil, fmt.Errorf("slider %q: default %v must be in range %v-%v", s.Text, s.Default, s.Min, s.Max)
+	}
+	return json.Marshal(withTooltip(map[string]any{"type": "slider", "text": s.Text, "min": s.Min, "max": s.Max, "step": s.step(), "default": s.Default}, s.Tooltip))
+}
+
+// MarshalJSON ...
+func (d Dropdown) MarshalJSON() ([]byte, error) {
+	if err := validateOptions(d.Text, d.Options, d.Default); err != nil {
+		return nil, err
+	}
+	return json.Marshal(withTooltip(map[string]any{"type": "dropdown", "text": d.Text, "options": d.Options, "default": d.Default}, d.Tooltip))
+}
+
+// MarshalJSON ...
+func (s StepSlider) MarshalJSON() ([]byte, error) {
+	if err := validateOptions(s.Text, s.Options, s.Default); err != nil {
+		return nil, err
+	}
+	return json.Marshal(withTooltip(map[string]any{"type": "step_slider", "text": s.Text, "steps": s.Options, "default": s.Default}, s.Tooltip))
+}
+
+// step returns the step of the slider, which is 1 if not set.
+func (s Slider) step() float64 {
+	if s.Step <= 0 {
+		return 1
+	}
+	return s.Step
+}
+
+// withTooltip adds a tooltip to the JSON object of an element if it is not empty.
+func withTooltip(m map[string]any, tooltip string) map[string]any {
+	if tooltip != "" {
+		m["tooltip"] = tooltip
+	}
+	return m
+}
+
+// validateOptions checks if the options of a Dropdown or StepSlider are valid for the default passed.
+func validateOptions(text string, options []string, def int) error {
+	if len(options) == 0 {
+		return fmt.Errorf("element %q must have at least one option", text)
+	}
+	if def < 0 || def >= len(options) {
+		return fmt.Errorf("element %q: default %v is not a valid option index", text, def)
+	}
+	return nil
+}
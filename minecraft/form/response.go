@@ -0,0 +1,192 @@
+package form
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// CancelledError is returned when parsing the response to a form that the player closed or that the client
+// could not show.
+type CancelledError struct {
+	// Reason is the reason the form was cancelled. It is one of the packet.ModalFormCancelReason constants.
+	Reason uint8
+}
+
+// Error ...
+func (err CancelledError) Error() string {
+	if err.Reason == packet.ModalFormCancelReasonUserBusy {
+		return "form cancelled: player is busy"
+	}
+	return "form cancelled: closed by player"
+}
+
+// InvalidResponseError is returned when parsing a response that does not match the form it is for. A client
+// should never send such a response, so it generally means the client is modified.
+type InvalidResponseError struct {
+	Msg string
+}
+
+// Error ...
+func (err InvalidResponseError) Error() string {
+	return "invalid form response: " + err.Msg
+}
+
+// ParseResponse parses the response to a Menu and returns the index of the button clicked.
+func (m Menu) ParseResponse(pk *packet.ModalFormResponse) (int, error) {
+	data, err := responseData(pk)
+	if err != nil {
+		return 0, err
+	}
+	index, err := parseIndex(data, len(m.Buttons))
+	if err != nil {
+		return 0, InvalidResponseError{Msg: "button " + err.Error()}
+	}
+	return index, nil
+}
+
+// ParseResponse parses the response to a Modal and returns true if Button1 was clicked and false if Button2
+// was clicked.
+func (m Modal) ParseResponse(pk *packet.ModalFormResponse) (bool, error) {
+	data, err := responseData(pk)
+	if err != nil {
+		return false, err
+	}
+	var clicked bool
+	if err := json.Unmarshal(data, &clicked); err != nil {
+		return false, InvalidResponseError{Msg: fmt.Sprintf("expected bool, got %s", data)}
+	}
+	return clicked, nil
+}
+
+// ParseResponse parses the response to a Custom form and returns the value submitted for every element, in
+// the same order as the elements. The type of each value depends on its element and is documented on the
+// element type. The values are validated against their elements, for example to ensure the value of a
+// Slider is within its range.
+func (c Custom) ParseResponse(pk *packet.ModalFormResponse) ([]any, error) {
+	data, err := responseData(pk)
+	if err != nil {
+		return nil, err
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, InvalidResponseError{Msg: fmt.Sprintf("expected array, got %s", data)}
+	}
+
+	elements := c.Elements
+	if len(raw) != len(elements) {
+		// Some versions of the client leave out the values of elements that do not take input.
+		elements = nil
+		for _, e := range c.Elements {
+			if !isStatic(e) {
+				elements = append(elements, e)
+			}
+		}
+		if len(raw) != len(elements) {
+			return nil, InvalidResponseError{Msg: fmt.Sprintf("expected %v values, got %v", len(c.Elements), len(raw))}
+		}
+	}
+	values := make([]any, 0, len(c.Elements))
+	for _, e := range c.Elements {
+		if len(elements) != len(c.Elements) && isStatic(e) {
+			values = append(values, nil)
+			continue
+		}
+		v, err := e.parse(raw[0])
+		if err != nil {
+			return nil, InvalidResponseError{Msg: fmt.Sprintf("element %v: %v", len(values), err)}
+		}
+		values, raw = append(values, v), raw[1:]
+	}
+	return values, nil
+}
+
+// responseData returns the response data of a packet.ModalFormResponse, or a CancelledError if the form was
+// cancelled.
+func responseData(pk *packet.ModalFormResponse) (json.RawMessage, error) {
+	data, ok := pk.ResponseData.Value()
+	if data = bytes.TrimSpace(data); !ok || len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		reason, _ := pk.CancelReason.Value()
+		return nil, CancelledError{Reason: reason}
+	}
+	return data, nil
+}
+
+// isStatic checks if an element takes no input from the player.
+func isStatic(e Element) bool {
+	switch e.(type) {
+	case Label, Header, Divider, *Label, *Header, *Divider:
+		return true
+	}
+	return false
+}
+
+// parseIndex parses an index into a list of n options.
+func parseIndex(data json.RawMessage, n int) (int, error) {
+	var f float64
+	if err := json.Unmarshal(data, &f); err != nil || f != math.Trunc(f) {
+		return 0, fmt.Errorf("expected integer index, got %s", data)
+	}
+	if f < 0 || f >= float64(n) {
+		return 0, fmt.Errorf("index %v out of range for %v options", f, n)
+	}
+	return int(f), nil
+}
+
+// parse ...
+func (Label) parse(json.RawMessage) (any, error) {
+	return nil, nil
+}
+
+// parse ...
+func (Header) parse(json.RawMessage) (any, error) {
+	return nil, nil
+}
+
+// parse ...
+func (Divider) parse(json.RawMessage) (any, error) {
+	return nil, nil
+}
+
+// parse ...
+func (i Input) parse(data json.RawMessage) (any, error) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("expected string, got %s", data)
+	}
+	return s, nil
+}
+
+// parse ...
+func (t Toggle) parse(data json.RawMessage) (any, error) {
+	var b bool
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("expected bool, got %s", data)
+	}
+	return b, nil
+}
+
+// parse ...
+func (s Slider) parse(data json.RawMessage) (any, error) {
+	var f float64
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("expected number, got %s", data)
+	}
+	if f < s.Min || f > s.Max {
+		return nil, fmt.Errorf("value %v out of range %v-%v", f, s.Min, s.Max)
+	}
+	return f, nil
+}
+
+// parse ...
+func (d Dropdown) parse(data json.RawMessage) (any, error) {
+	return parseIndex(data, len(d.Options))
+}
+
+// parse ...
+func (s StepSlider) parse(data json.RawMessage) (any, error) {
+	return parseIndex(data, len(s.Options))
+}
@@ -0,0 +1,116 @@
+// Package inventory implements a server-side engine for the item stack requests that clients send when server
+// authoritative inventories are enabled.
+//
+// A Container holds the item stacks of an inventory window along with their stack network IDs. Containers
+// are registered with a Processor under the container IDs the client uses to refer to them. Typically, the
+// player inventory is registered under protocol.ContainerHotBar, protocol.ContainerInventory and
+// protocol.ContainerCombinedHotBarAndInventory, and the UI inventory (protocol.WindowIDUI) under
+// protocol.ContainerCursor and the crafting input containers. The Processor validates the actions of every
+// request against the containers, applies them if all of them are valid and produces the
+// packet.ItemStackResponse to send back. If any action is invalid, none of the actions of the request are
+// applied.
+package inventory
+
+import (
+	"fmt"
+	"slices"
+	"sync/atomic"
+
+	"github.com/sandertv/gophertunnel/minecraft/item"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Container is a list of slots holding item stacks, such as the main inventory of a player or the contents
+// of a chest. Every non-empty slot holds a stack with a stack network ID unique to that stack.
+// A Container is not safe for concurrent use. It should only be changed on the goroutine that processes the
+// item stack requests of the client it belongs to.
+type Container struct {
+	windowID uint32
+	slots    []protocol.ItemInstance
+}
+
+// NewContainer returns an empty Container with the size passed, which is shown to the client in the window
+// with the ID passed, such as protocol.WindowIDInventory.
+func NewContainer(windowID uint32, size int) *Container {
+	return &Container{windowID: windowID, slots: make([]protocol.ItemInstance, size)}
+}
+
+// WindowID returns the ID of the window that the Container is shown in.
+func (c *Container) WindowID() uint32 {
+	return c.windowID
+}
+
+// Size returns the amount of slots in the Container.
+func (c *Container) Size() int {
+	return len(c.slots)
+}
+
+// Slot returns the item in the slot passed. An empty item is returned if the slot is out of range.
+func (c *Container) Slot(slot int) protocol.ItemInstance {
+	if slot < 0 || slot >= len(c.slots) {
+		return protocol.ItemInstance{}
+	}
+	return c.slots[slot]
+}
+
+// SetSlot sets the item stack in the slot passed, assigning it a new stack network ID. An error is returned
+// if the slot is out of range. Clients are not informed of the change: InventorySlot returns the packet that
+// does so.
+func (c *Container) SetSlot(slot int, stack protocol.ItemStack) error {
+	if slot < 0 || slot >= len(c.slots) {
+		return fmt.Errorf("slot %v out of range for container of size %v", slot, len(c.slots))
+	}
+	c.slots[slot] = newInstance(stack)
+	return nil
+}
+
+// Contents returns a copy of the items in all slots of the Container.
+func (c *Container) Contents() []protocol.ItemInstance {
+	return slices.Clone(c.slots)
+}
+
+// InventorySlot returns a packet.InventorySlot that updates the slot passed client-side to the item
+// currently in it. The FullContainerName passed is the name the client knows the Container by.
+func (c *Container) InventorySlot(name protocol.FullContainerName, slot int) *packet.InventorySlot {
+	return &packet.InventorySlot{
+		WindowID:  c.windowID,
+		Slot:      uint32(slot),
+		Container: protocol.Option(name),
+		NewItem:   c.Slot(slot),
+	}
+}
+
+// InventoryContent returns a packet.InventoryContent that updates all slots of the Container client-side.
+// The FullContainerName passed is the name the client knows the Container by.
+func (c *Container) InventoryContent(name protocol.FullContainerName) *packet.InventoryContent {
+	return &packet.InventoryContent{WindowID: c.windowID, Content: c.Contents(), Container: name}
+}
+
+// stackNetworkID is the last stack network ID assigned to a stack. IDs are unique across all Containers, so
+// that stacks may be moved between Containers freely.
+var stackNetworkID atomic.Int32
+
+// newInstance returns an ItemInstance holding the stack passed with a new stack network ID, or an empty
+// ItemInstance if the stack is empty.
+func newInstance(stack protocol.ItemStack) protocol.ItemInstance {
+	if item.Empty(stack) {
+		return protocol.ItemInstance{}
+	}
+	return protocol.ItemInstance{StackNetworkID: nextStackNetworkID(), Stack: stack}
+}
+
+// nextStackNetworkID returns a new stack network ID. Once the IDs run out, they start over at 1 rather than
+// wrapping around to negative IDs, which refer to stacks by the ID of the client request that created them.
+func nextStackNetworkID() int32 {
+	for {
+		id := stackNetworkID.Load()
+		next := id + 1
+		if next <= 0 {
+			next = 1
+		}
+		if stackNetworkID.CompareAndSwap(id, next) {
+			return next
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Config holds the functions a Processor calls for the actions that depend on the server beyond the contents
+// of the containers. Actions of which the function is nil are rejected.
+type Config struct {
+	// MaxStackSize returns the maximum count of the item stack passed in a single slot. If nil, 64 is used
+	// for all items.
+	MaxStackSize func(stack protocol.ItemStack) int
+	// Creative returns whether the player is in creative mode, which is required to destroy items and to take
+	// items out of the creative inventory.
+	Creative func() bool
+	// CreativeItem returns the item of the creative inventory with the network ID passed, as sent in the
+	// packet.CreativeContent.
+	CreativeItem func(networkID uint32) (protocol.ItemStack, bool)
+	// Craft returns the results of the crafting action passed, which is one of the CraftRecipe, AutoCraftRecipe,
+	// CraftRecipeOptional, CraftGrindstoneRecipe or CraftLoomRecipe actions, using the items consumed by the
+	// request. The results must account for the number of crafts in the action. If the items consumed do not
+	// satisfy the recipe, an error should be returned. If this error is a RequestError, its Status is sent to
+	// the client, otherwise protocol.ItemStackResponseStatusInvalidCraftRequest is.
+	Craft func(action protocol.StackRequestAction, consumed []protocol.ItemStack) ([]protocol.ItemStack, error)
+	// Drop is called with every item stack dropped by the player once the request that dropped it is
+	// accepted. Randomly is the Randomly field of the action.
+	Drop func(stack protocol.ItemStack, randomly bool)
+}
+
+// RequestError is the error returned when an item stack request is rejected.
+type RequestError struct {
+	// RequestID is the ID of the request rejected.
+	RequestID int32
+	// Action is the index of the action in the request that was rejected, or -1 if the request was rejected
+	// after all actions were applied.
+	Action int
+	// Status is the status sent to the client in the response. It is one of the
+	// protocol.ItemStackResponseStatus constants.
+	Status uint8
+	Msg    string
+}
+
+// Error ...
+func (err RequestError) Error() string {
+	if err.Action < 0 {
+		return fmt.Sprintf("item stack request %v rejected: %v", err.RequestID, err.Msg)
+	}
+	return fmt.Sprintf("item stack request %v rejected at action %v: %v", err.RequestID, err.Action, err.Msg)
+}
+
+// Processor processes the item stack requests of a single client against the Containers registered with it.
+// A Processor is not safe for concurrent use.
+type Processor struct {
+	conf       Config
+	containers map[containerKey]*Container
+	// changedBy holds the ID of the last request that changed each slot. The client refers to the stack in a
+	// slot by this ID as long as it has not received the response to that request.
+	changedBy map[slot]int32
+}
+
+// NewProcessor returns a Processor without Containers that uses the Config passed.
+func NewProcessor(conf Config) *Processor {
+	return &Processor{conf: conf, containers: make(map[containerKey]*Container), changedBy: make(map[slot]int32)}
+}
+
+// Register registers a Container under the names passed, so that the actions in requests referring to
+// these names are applied to it. A name that was registered before is replaced.
+func (p *Processor) Register(c *Container, names ...protocol.FullContainerName) {
+	for _, name := range names {
+		p.containers[keyOf(name)] = c
+	}
+}
+
+// Unregister removes the Containers registered under the names passed, such as when the client closes the
+// window they are in. Actions that refer to these names are rejected afterwards.
+func (p *Processor) Unregister(names ...protocol.FullContainerName) {
+	for _, name := range names {
+		key := keyOf(name)
+		c, ok := p.containers[key]
+		if !ok {
+			continue
+		}
+		delete(p.containers, key)
+		for s := range p.changedBy {
+			if s.c == c {
+				delete(p.changedBy, s)
+			}
+		}
+	}
+}
+
+// Container returns the Container registered under the name passed.
+func (p *Processor) Container(name protocol.FullContainerName) (*Container, bool) {
+	c, ok := p.containers[keyOf(name)]
+	return c, ok
+}
+
+// Handle processes all requests in the packet.ItemStackRequest passed and returns the packets to send back:
+// A packet.ItemStackResponse holding a response to every request, followed by a packet.InventorySlot for
+// every slot referred to by a rejected request, so that the client is updated to the state of the server if
+// it assumed a different state. The error returned joins the errors of all rejected requests.
+func (p *Processor) Handle(pk *packet.ItemStackRequest) ([]packet.Packet, error) {
+	resp := &packet.ItemStackResponse{Responses: make([]protocol.ItemStackResponse, 0, len(pk.Requests))}
+	pks := []packet.Packet{resp}
+	var errs []error
+	for _, req := range pk.Requests {
+		r, err := p.Process(req)
+		resp.Responses = append(resp.Responses, r)
+		if err != nil {
+			errs = append(errs, err)
+			pks = append(pks, p.resync(req)...)
+		}
+	}
+	return pks, errors.Join(errs...)
+}
+
+// Process processes a single item stack request and returns the response to send to the client. If the
+// request is rejected, the response has an error status, none of its actions are applied and a RequestError
+// is returned.
+func (p *Processor) Process(req protocol.ItemStackRequest) (protocol.ItemStackResponse, error) {
+	tx := newTransaction(p, req.RequestID)
+	for i, a := range req.Actions {
+		if err := tx.apply(a); err != nil {
+			err.RequestID, err.Action = req.RequestID, i
+			return protocol.ItemStackResponse{Status: err.Status, RequestID: req.RequestID}, *err
+		}
+	}
+	if err := tx.finish(); err != nil {
+		err.RequestID, err.Action = req.RequestID, -1
+		return protocol.ItemStackResponse{Status: err.Status, RequestID: req.RequestID}, *err
+	}
+	tx.commit()
+	return protocol.ItemStackResponse{
+		Status:        protocol.ItemStackResponseStatusOK,
+		RequestID:     req.RequestID,
+		ContainerInfo: tx.containerInfo(),
+	}, nil
+}
+
+// resync returns a packet.InventorySlot for every slot in a registered Container that the request passed
+// refers to.
+func (p *Processor) resync(req protocol.ItemStackRequest) []packet.Packet {
+	var pks []packet.Packet
+	seen := make(map[slot]bool)
+	for _, a := range req.Actions {
+		for _, info := range slotInfos(a) {
+			c, ok := p.Container(info.Container)
+			if !ok || int(info.Slot) >= c.Size() || seen[slot{c, int(info.Slot)}] {
+				continue
+			}
+			seen[slot{c, int(info.Slot)}] = true
+			pks = append(pks, c.InventorySlot(info.Container, int(info.Slot)))
+		}
+	}
+	return pks
+}
+
+// maxStackSize returns the maximum count of the stack passed in a single slot.
+func (p *Processor) maxStackSize(stack protocol.ItemStack) int {
+	if p.conf.MaxStackSize == nil {
+		return 64
+	}
+	return p.conf.MaxStackSize(stack)
+}
+
+// creative checks if the player is in creative mode.
+func (p *Processor) creative() bool {
+	return p.conf.Creative != nil && p.conf.Creative()
+}
+
+// containerKey is a comparable form of a protocol.FullContainerName.
+type containerKey struct {
+	id         byte
+	dynamicID  uint32
+	hasDynamic bool
+}
+
+// keyOf returns the containerKey of a protocol.FullContainerName.
+func keyOf(name protocol.FullContainerName) containerKey {
+	dynamicID, hasDynamic := name.DynamicContainerID.Value()
+	return containerKey{id: name.ContainerID, dynamicID: dynamicID, hasDynamic: hasDynamic}
+}
+
+// slot is a single slot in a Container.
+type slot struct {
+	c     *Container
+	index int
+}
+
+// slotInfos returns the slots that a StackRequestAction refers to.
+func slotInfos(a protocol.StackRequestAction) []protocol.StackRequestSlotInfo {
+	switch a := a.(type) {
+	case *protocol.TakeStackRequestAction:
+		return []protocol.StackRequestSlotInfo{a.Source, a.Destination}
+	case *protocol.PlaceStackRequestAction:
+		return []protocol.StackRequestSlotInfo{a.Source, a.Destination}
+	case *protocol.SwapStackRequestAction:
+		return []protocol.StackRequestSlotInfo{a.Source, a.Destination}
+	case *protocol.DropStackRequestAction:
+		return []protocol.StackRequestSlotInfo{a.Source}
+	case *protocol.DestroyStackRequestAction:
+		return []protocol.StackRequestSlotInfo{a.Source}
+	case *protocol.ConsumeStackRequestAction:
+		return []protocol.StackRequestSlotInfo{a.Source}
+	}
+	return nil
+}
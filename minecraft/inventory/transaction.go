@@ -0,0 +1,359 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/sandertv/gophertunnel/minecraft/item"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// transaction holds the changes made by the actions of a single request. The changes are only written to the
+// Containers once all actions have been applied successfully, so that a rejected request leaves the
+// Containers untouched.
+type transaction struct {
+	p         *Processor
+	requestID int32
+
+	changes map[slot]protocol.ItemInstance
+	// names holds the name that each changed slot was last referred to by, and order the changed slots in
+	// the order they were first changed.
+	names map[slot]protocol.FullContainerName
+	order []slot
+	drops []drop
+
+	// craft is the crafting action of the request, if any, and consumed holds the items consumed for it.
+	craft    protocol.StackRequestAction
+	consumed []protocol.ItemStack
+	// results holds the results of the crafting action once they are known, and crafted is true from then.
+	results []protocol.ItemStack
+	crafted bool
+	// created is the item in the created output container, from which crafting results are taken.
+	created protocol.ItemInstance
+}
+
+// drop is an item stack dropped by a request.
+type drop struct {
+	stack    protocol.ItemStack
+	randomly bool
+}
+
+// newTransaction returns a new transaction for the request with the ID passed.
+func newTransaction(p *Processor, requestID int32) *transaction {
+	return &transaction{
+		p:         p,
+		requestID: requestID,
+		changes:   make(map[slot]protocol.ItemInstance),
+		names:     make(map[slot]protocol.FullContainerName),
+	}
+}
+
+// reject returns a RequestError with the status and message passed.
+func reject(status uint8, format string, a ...any) *RequestError {
+	return &RequestError{Status: status, Msg: fmt.Sprintf(format, a...)}
+}
+
+// apply validates and applies a single action of the request.
+func (tx *transaction) apply(a protocol.StackRequestAction) *RequestError {
+	switch a := a.(type) {
+	case *protocol.TakeStackRequestAction:
+		return tx.transfer(a.Count, a.Source, a.Destination)
+	case *protocol.PlaceStackRequestAction:
+		return tx.transfer(a.Count, a.Source, a.Destination)
+	case *protocol.SwapStackRequestAction:
+		return tx.swap(a.Source, a.Destination)
+	case *protocol.DropStackRequestAction:
+		if tx.p.conf.Drop == nil {
+			return reject(protocol.ItemStackResponseStatusCannotDropItem, "dropping items is not allowed")
+		}
+		stack, err := tx.remove(a.Source, a.Count)
+		if err != nil {
+			return err
+		}
+		tx.drops = append(tx.drops, drop{stack: stack, randomly: a.Randomly})
+	case *protocol.DestroyStackRequestAction:
+		if !tx.p.creative() {
+			return reject(protocol.ItemStackResponseStatusCannotDestroyItem, "destroying items requires creative mode")
+		}
+		_, err := tx.remove(a.Source, a.Count)
+		return err
+	case *protocol.ConsumeStackRequestAction:
+		if tx.craft == nil || tx.crafted {
+			return reject(protocol.ItemStackResponseStatusCannotConsumeItem, "items may only be consumed by a crafting action before its results are taken")
+		}
+		stack, err := tx.remove(a.Source, a.Count)
+		if err != nil {
+			return err
+		}
+		tx.consumed = append(tx.consumed, stack)
+	case *protocol.CreateStackRequestAction:
+		if err := tx.resolve(); err != nil {
+			return err
+		}
+		if int(a.ResultsSlot) >= len(tx.results) {
+			return reject(protocol.ItemStackResponseStatusInvalidCraftResultIndex, "result %v out of range for %v results", a.ResultsSlot, len(tx.results))
+		}
+		tx.created = newInstance(tx.results[a.ResultsSlot])
+	case *protocol.CraftCreativeStackRequestAction:
+		if !tx.p.creative() || tx.p.conf.CreativeItem == nil {
+			return reject(protocol.ItemStackResponseStatusPlayerNotInCreativeMode, "taking creative items requires creative mode")
+		}
+		if tx.craft != nil {
+			return reject(protocol.ItemStackResponseStatusInvalidCraftRequest, "request has multiple crafting actions")
+		}
+		stack, ok := tx.p.conf.CreativeItem(a.CreativeItemNetworkID)
+		if !ok {
+			return reject(protocol.ItemStackResponseStatusFailedToCraftCreative, "unknown creative item %v", a.CreativeItemNetworkID)
+		}
+		stack.Count = uint16(tx.p.maxStackSize(stack))
+		tx.craft, tx.crafted, tx.results = a, true, []protocol.ItemStack{stack}
+		tx.created = newInstance(stack)
+	case *protocol.CraftRecipeStackRequestAction, *protocol.AutoCraftRecipeStackRequestAction,
+		*protocol.CraftRecipeOptionalStackRequestAction, *protocol.CraftGrindstoneRecipeStackRequestAction,
+		*protocol.CraftLoomRecipeStackRequestAction:
+		if tx.p.conf.Craft == nil {
+			return reject(protocol.ItemStackResponseStatusInvalidRequestCraftActionType, "crafting is not supported")
+		}
+		if tx.craft != nil {
+			return reject(protocol.ItemStackResponseStatusInvalidCraftRequest, "request has multiple crafting actions")
+		}
+		tx.craft = a
+	case *protocol.MineBlockStackRequestAction:
+		name := protocol.FullContainerName{ContainerID: protocol.ContainerHotBar}
+		s, current, err := tx.slot(protocol.StackRequestSlotInfo{Container: name, Slot: byte(a.HotbarSlot), StackNetworkID: a.StackNetworkID})
+		if err != nil {
+			return err
+		}
+		tx.set(s, name, current)
+	case *protocol.CraftResultsDeprecatedStackRequestAction, *protocol.CraftNonImplementedStackRequestAction:
+		// These actions duplicate information found in other actions and are ignored.
+	default:
+		return reject(protocol.ItemStackResponseStatusInvalidRequestActionType, "unsupported action %T", a)
+	}
+	return nil
+}
+
+// finish validates the request after all of its actions were applied.
+func (tx *transaction) finish() *RequestError {
+	if tx.craft == nil {
+		return nil
+	}
+	// The results of a crafting action may never be taken, but the items consumed must still satisfy it.
+	return tx.resolve()
+}
+
+// commit writes the changes of the transaction to the Containers and drops the items dropped.
+func (tx *transaction) commit() {
+	for s, instance := range tx.changes {
+		s.c.slots[s.index] = instance
+		tx.p.changedBy[s] = tx.requestID
+	}
+	if tx.p.conf.Drop != nil {
+		for _, d := range tx.drops {
+			tx.p.conf.Drop(d.stack, d.randomly)
+		}
+	}
+}
+
+// resolve determines the results of the crafting action of the request from the items consumed so far, and
+// places the first result in the created output container. It does nothing if the results are already
+// known.
+func (tx *transaction) resolve() *RequestError {
+	if tx.crafted {
+		return nil
+	}
+	if tx.craft == nil {
+		return reject(protocol.ItemStackResponseStatusMissingCreatedOutputContainer, "request has no crafting action")
+	}
+	results, err := tx.p.conf.Craft(tx.craft, tx.consumed)
+	if err != nil {
+		var reqErr RequestError
+		if errors.As(err, &reqErr) {
+			return reject(reqErr.Status, "craft: %v", reqErr.Msg)
+		}
+		return reject(protocol.ItemStackResponseStatusInvalidCraftRequest, "craft: %v", err)
+	}
+	if len(results) == 0 {
+		return reject(protocol.ItemStackResponseStatusEmptyCraftResults, "craft produced no results")
+	}
+	tx.results, tx.crafted = results, true
+	tx.created = newInstance(results[0])
+	return nil
+}
+
+// transfer moves count items from the source slot to the destination slot, as done by the take and place
+// actions.
+func (tx *transaction) transfer(count byte, src, dst protocol.StackRequestSlotInfo) *RequestError {
+	if count == 0 {
+		return reject(protocol.ItemStackResponseStatusInvalidTransferAmount, "cannot transfer 0 items")
+	}
+	if dst.Container.ContainerID == protocol.ContainerCreatedOutput {
+		return reject(protocol.ItemStackResponseStatusDstContainerEqualToCreatedOutputContainer, "cannot place items in the created output")
+	}
+	if keyOf(src.Container) == keyOf(dst.Container) && src.Slot == dst.Slot {
+		return reject(protocol.ItemStackResponseStatusDstContainerAndSlotEqualToSrcContainerAndSlot, "source and destination are the same slot")
+	}
+
+	var (
+		from    protocol.ItemInstance
+		fromRef slot
+	)
+	created := src.Container.ContainerID == protocol.ContainerCreatedOutput
+	if created {
+		if err := tx.resolve(); err != nil {
+			return err
+		}
+		from = tx.created
+	} else {
+		s, current, err := tx.slot(src)
+		if err != nil {
+			return err
+		}
+		from, fromRef = current, s
+	}
+	to, current, err := tx.slot(dst)
+	if err != nil {
+		return err
+	}
+	if uint16(count) > from.Stack.Count || item.Empty(from.Stack) {
+		return reject(protocol.ItemStackResponseStatusInvalidTransferAmount, "cannot transfer %v items from a stack of %v", count, from.Stack.Count)
+	}
+	if !item.Empty(current.Stack) && !stackable(current.Stack, from.Stack) {
+		return reject(protocol.ItemStackResponseStatusCannotPlaceItem, "destination holds a different item")
+	}
+	if int(current.Stack.Count)+int(count) > tx.p.maxStackSize(from.Stack) {
+		return reject(protocol.ItemStackResponseStatusCannotPlaceItem, "destination cannot hold %v more items", count)
+	}
+
+	remaining := from
+	remaining.Stack.Count -= uint16(count)
+	switch {
+	case !item.Empty(current.Stack):
+		current.Stack.Count += uint16(count)
+	case remaining.Stack.Count == 0:
+		// The full stack is moved, so it keeps its stack network ID.
+		current = from
+	default:
+		stack := from.Stack
+		stack.Count = uint16(count)
+		current = newInstance(stack)
+	}
+	if remaining.Stack.Count == 0 {
+		remaining = protocol.ItemInstance{}
+	}
+	if created {
+		tx.created = remaining
+	} else {
+		tx.set(fromRef, src.Container, remaining)
+	}
+	tx.set(to, dst.Container, current)
+	return nil
+}
+
+// swap swaps the items in two slots.
+func (tx *transaction) swap(a, b protocol.StackRequestSlotInfo) *RequestError {
+	if a.Container.ContainerID == protocol.ContainerCreatedOutput || b.Container.ContainerID == protocol.ContainerCreatedOutput {
+		return reject(protocol.ItemStackResponseStatusCannotSwapItem, "cannot swap with the created output")
+	}
+	sa, itemA, err := tx.slot(a)
+	if err != nil {
+		return err
+	}
+	sb, itemB, err := tx.slot(b)
+	if err != nil {
+		return err
+	}
+	tx.set(sa, a.Container, itemB)
+	tx.set(sb, b.Container, itemA)
+	return nil
+}
+
+// remove removes count items from a slot and returns them, as done by the drop, destroy and consume actions.
+func (tx *transaction) remove(info protocol.StackRequestSlotInfo, count byte) (protocol.ItemStack, *RequestError) {
+	s, current, err := tx.slot(info)
+	if err != nil {
+		return protocol.ItemStack{}, err
+	}
+	if count == 0 || uint16(count) > current.Stack.Count || item.Empty(current.Stack) {
+		return protocol.ItemStack{}, reject(protocol.ItemStackResponseStatusInvalidRemovedAmount, "cannot remove %v items from a stack of %v", count, current.Stack.Count)
+	}
+	removed := current.Stack
+	removed.Count = uint16(count)
+	current.Stack.Count -= uint16(count)
+	if current.Stack.Count == 0 {
+		current = protocol.ItemInstance{}
+	}
+	tx.set(s, info.Container, current)
+	return removed, nil
+}
+
+// slot looks up the slot that the StackRequestSlotInfo passed refers to and returns it along with the item
+// currently in it. An error is returned if the slot does not exist or if the stack network ID assumed by the
+// client does not match the one of the item in it.
+func (tx *transaction) slot(info protocol.StackRequestSlotInfo) (slot, protocol.ItemInstance, *RequestError) {
+	c, ok := tx.p.Container(info.Container)
+	if !ok {
+		return slot{}, protocol.ItemInstance{}, reject(protocol.ItemStackResponseStatusInvalidSourceContainer, "container %v is not open", info.Container.ContainerID)
+	}
+	if int(info.Slot) >= c.Size() {
+		return slot{}, protocol.ItemInstance{}, reject(protocol.ItemStackResponseStatusFailedToValidateSrcSlot, "slot %v out of range for container %v", info.Slot, info.Container.ContainerID)
+	}
+	s := slot{c: c, index: int(info.Slot)}
+	current, changed := tx.changes[s]
+	if !changed {
+		current = c.slots[s.index]
+	}
+	// A negative ID is the ID of a request of which the client has not yet received the response, including
+	// the current one. It refers to the stack that this request left in the slot.
+	id, lastRequest := info.StackNetworkID, tx.p.changedBy[s]
+	if changed {
+		lastRequest = tx.requestID
+	}
+	if id != current.StackNetworkID && (id >= 0 || id != lastRequest) {
+		return slot{}, protocol.ItemInstance{}, reject(protocol.ItemStackResponseStatusInvalidItemNetId, "slot %v of container %v holds stack %v, not %v", info.Slot, info.Container.ContainerID, current.StackNetworkID, id)
+	}
+	return s, current, nil
+}
+
+// set changes the item in a slot, which is referred to by the name passed.
+func (tx *transaction) set(s slot, name protocol.FullContainerName, instance protocol.ItemInstance) {
+	if _, ok := tx.names[s]; !ok {
+		tx.order = append(tx.order, s)
+	}
+	tx.changes[s], tx.names[s] = instance, name
+}
+
+// containerInfo returns the StackResponseContainerInfo describing every slot changed by the transaction,
+// grouped by the name of the container they were referred to by.
+func (tx *transaction) containerInfo() []protocol.StackResponseContainerInfo {
+	var info []protocol.StackResponseContainerInfo
+	indices := make(map[containerKey]int)
+	for _, s := range tx.order {
+		name, instance := tx.names[s], tx.changes[s]
+		i, ok := indices[keyOf(name)]
+		if !ok {
+			i = len(info)
+			indices[keyOf(name)] = i
+			info = append(info, protocol.StackResponseContainerInfo{Container: name})
+		}
+		customName, _ := item.DisplayName(instance.Stack)
+		info[i].SlotInfo = append(info[i].SlotInfo, protocol.StackResponseSlotInfo{
+			Slot:                 byte(s.index),
+			HotbarSlot:           byte(s.index),
+			Count:                byte(instance.Stack.Count),
+			StackNetworkID:       instance.StackNetworkID,
+			CustomName:           customName,
+			DurabilityCorrection: item.Damage(instance.Stack),
+		})
+	}
+	return info
+}
+
+// stackable checks if two item stacks are of the same item and may be combined into one stack.
+func stackable(a, b protocol.ItemStack) bool {
+	return a.ItemType == b.ItemType && a.BlockRuntimeID == b.BlockRuntimeID &&
+		slices.Equal(a.CanBePlacedOn, b.CanBePlacedOn) && slices.Equal(a.CanBreak, b.CanBreak) &&
+		(len(a.NBTData) == 0 && len(b.NBTData) == 0 || reflect.DeepEqual(a.NBTData, b.NBTData))
+}
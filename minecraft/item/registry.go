@@ -0,0 +1,164 @@
+// Package item implements a registry of the items known to a connection, built from the item table sent in
+// the ItemRegistry packet, and helpers to create and inspect protocol.ItemStack values.
+package item
+
+import (
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/block"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// Entry is a single item in a Registry.
+type Entry struct {
+	// Name is the name of the item, such as 'minecraft:stick'.
+	Name string
+	// NetworkID is the numerical ID used for the item in the ItemType of an item stack.
+	NetworkID int32
+	// ComponentBased specifies if the item is defined using components, which is the case for custom items.
+	ComponentBased bool
+	// Version is the version of the item entry. It is one of the protocol.ItemEntryVersion constants.
+	Version int32
+	// Data holds the component NBT of the item. It is generally only set for component based items.
+	Data map[string]any
+}
+
+// Components returns the components of a component based item, such as 'minecraft:icon' and
+// 'minecraft:durability'. Nil is returned if the item has no components.
+func (e Entry) Components() map[string]any {
+	m, _ := e.Data["components"].(map[string]any)
+	return m
+}
+
+// Properties returns the item properties of a component based item, such as 'max_stack_size' and
+// 'hand_equipped'. Nil is returned if the item has no item properties.
+func (e Entry) Properties() map[string]any {
+	m, _ := e.Components()["item_properties"].(map[string]any)
+	return m
+}
+
+// Flag returns the value of a boolean item property of a component based item, such as 'hand_equipped',
+// 'allow_off_hand', 'foil' or 'can_destroy_in_creative'. False is returned if the property is not set.
+func (e Entry) Flag(name string) bool {
+	switch v := e.Properties()[name].(type) {
+	case bool:
+		return v
+	case byte:
+		return v != 0
+	}
+	return false
+}
+
+// MaxStackSize returns the maximum stack size set in the item properties of a component based item. False
+// is returned if the item does not set it.
+func (e Entry) MaxStackSize() (int, bool) {
+	switch v := e.Properties()["max_stack_size"].(type) {
+	case byte:
+		return int(v), true
+	case int16:
+		return int(v), true
+	case int32:
+		return int(v), true
+	}
+	return 0, false
+}
+
+// Registry holds the items known to a connection and allows looking them up by name and network ID. A
+// Registry is not modified after it is created, so it is safe for concurrent use.
+type Registry struct {
+	entries []Entry
+	ids     map[int32]int
+	names   map[string]int
+}
+
+// NewRegistry creates a Registry holding the item entries passed, such as those from the ItemRegistry
+// packet.
+func NewRegistry(items []protocol.ItemEntry) *Registry {
+	r := &Registry{
+		entries: make([]Entry, 0, len(items)),
+		ids:     make(map[int32]int, len(items)),
+		names:   make(map[string]int, len(items)),
+	}
+	for _, item := range items {
+		r.ids[int32(item.RuntimeID)] = len(r.entries)
+		r.names[item.Name] = len(r.entries)
+		r.entries = append(r.entries, Entry{
+			Name:           item.Name,
+			NetworkID:      int32(item.RuntimeID),
+			ComponentBased: item.ComponentBased,
+			Version:        item.Version,
+			Data:           item.Data,
+		})
+	}
+	return r
+}
+
+// NewRegistryFromConn creates a Registry holding the items in the GameData of the minecraft.Conn passed.
+// For a Conn obtained using minecraft.Dial, the items are only known once the connection has spawned, so
+// NewRegistryFromConn should be called after that. For a Conn obtained using a minecraft.Listener, the items
+// are those passed in the GameData to Conn.StartGame.
+func NewRegistryFromConn(conn *minecraft.Conn) *Registry {
+	return NewRegistry(conn.GameData().Items)
+}
+
+// Entries returns every item in the Registry, in the order of the item table they were read from. The slice
+// returned must not be modified.
+func (r *Registry) Entries() []Entry {
+	return r.entries
+}
+
+// ByNetworkID looks up the item with the network ID passed. False is returned if no such item exists.
+func (r *Registry) ByNetworkID(id int32) (Entry, bool) {
+	i, ok := r.ids[id]
+	if !ok {
+		return Entry{}, false
+	}
+	return r.entries[i], true
+}
+
+// ByName looks up the item with the name passed, such as 'minecraft:stick'. False is returned if no such
+// item exists.
+func (r *Registry) ByName(name string) (Entry, bool) {
+	i, ok := r.names[name]
+	if !ok {
+		return Entry{}, false
+	}
+	return r.entries[i], true
+}
+
+// Name returns the name of the item in the item stack passed. False is returned if the network ID of the
+// stack is not in the Registry, which is also the case for empty stacks.
+func (r *Registry) Name(stack protocol.ItemStack) (string, bool) {
+	e, ok := r.ByNetworkID(stack.NetworkID)
+	return e.Name, ok
+}
+
+// Stack creates an item stack of count items with the name and metadata value passed. False is returned if
+// the Registry does not hold an item with the name. Stack does not set the BlockRuntimeID of the stack, which
+// the game requires for items that place a block: Use BlockStack to create stacks of such items.
+func (r *Registry) Stack(name string, metadata uint32, count uint16) (protocol.ItemStack, bool) {
+	e, ok := r.ByName(name)
+	if !ok {
+		return protocol.ItemStack{}, false
+	}
+	return protocol.ItemStack{
+		ItemType: protocol.ItemType{NetworkID: e.NetworkID, MetadataValue: metadata},
+		Count:    count,
+	}, true
+}
+
+// BlockStack creates an item stack of count items of the block item with the name passed, such as
+// 'minecraft:stone'. The BlockRuntimeID of the stack is set to the network ID in the block.Registry passed of
+// the block state with the same name and the properties passed. False is returned if the Registry does not
+// hold an item with the name or if the block.Registry does not hold the block state.
+func (r *Registry) BlockStack(blocks *block.Registry, name string, properties map[string]any, count uint16) (protocol.ItemStack, bool) {
+	stack, ok := r.Stack(name, 0, count)
+	if !ok {
+		return protocol.ItemStack{}, false
+	}
+	id, ok := blocks.NetworkID(name, properties)
+	if !ok {
+		return protocol.ItemStack{}, false
+	}
+	stack.BlockRuntimeID = int32(id)
+	return stack, true
+}
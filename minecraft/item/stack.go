@@ -0,0 +1,150 @@
+package item
+
+import (
+	"maps"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// Enchantment is an enchantment applied to an item stack, as found in the 'ench' list in the NBT of the
+// stack.
+type Enchantment struct {
+	// ID is the numerical ID of the enchantment type, such as 9 for sharpness.
+	ID int16
+	// Level is the level of the enchantment, starting at 1.
+	Level int16
+}
+
+// Empty checks if the item stack passed is empty, meaning it holds no items.
+func Empty(stack protocol.ItemStack) bool {
+	return stack.NetworkID == 0 || stack.Count == 0
+}
+
+// DisplayName returns the custom name of the item stack passed, which is stored in the display compound of
+// its NBT. False is returned if the stack has no custom name.
+func DisplayName(stack protocol.ItemStack) (string, bool) {
+	name, ok := display(stack)["Name"].(string)
+	return name, ok
+}
+
+// SetDisplayName sets the custom name of the item stack passed. An empty name removes the custom name. The
+// NBT of the stack is copied before it is changed, so that stacks sharing the same NBT are not affected.
+func SetDisplayName(stack *protocol.ItemStack, name string) {
+	setDisplay(stack, "Name", name, name != "")
+}
+
+// Lore returns the lore of the item stack passed: The lines of text shown under its name, which are stored
+// in the display compound of its NBT.
+func Lore(stack protocol.ItemStack) []string {
+	var lore []string
+	switch list := display(stack)["Lore"].(type) {
+	case []string:
+		lore = append(lore, list...)
+	case []any:
+		for _, line := range list {
+			if s, ok := line.(string); ok {
+				lore = append(lore, s)
+			}
+		}
+	}
+	return lore
+}
+
+// SetLore sets the lore of the item stack passed. Passing no lines removes the lore. The NBT of the stack is
+// copied before it is changed, so that stacks sharing the same NBT are not affected.
+func SetLore(stack *protocol.ItemStack, lines ...string) {
+	lore := make([]any, len(lines))
+	for i, line := range lines {
+		lore[i] = line
+	}
+	setDisplay(stack, "Lore", lore, len(lines) > 0)
+}
+
+// Enchantments returns the enchantments of the item stack passed, which are stored in the 'ench' list of its
+// NBT.
+func Enchantments(stack protocol.ItemStack) []Enchantment {
+	var entries []map[string]any
+	switch list := stack.NBTData["ench"].(type) {
+	case []map[string]any:
+		entries = list
+	case []any:
+		for _, e := range list {
+			if m, ok := e.(map[string]any); ok {
+				entries = append(entries, m)
+			}
+		}
+	}
+	enchantments := make([]Enchantment, 0, len(entries))
+	for _, m := range entries {
+		id, _ := m["id"].(int16)
+		lvl, _ := m["lvl"].(int16)
+		enchantments = append(enchantments, Enchantment{ID: id, Level: lvl})
+	}
+	return enchantments
+}
+
+// SetEnchantments sets the enchantments of the item stack passed, replacing any it had before. Passing no
+// enchantments removes them. The NBT of the stack is copied before it is changed, so that stacks sharing the
+// same NBT are not affected.
+func SetEnchantments(stack *protocol.ItemStack, enchantments ...Enchantment) {
+	if len(enchantments) == 0 {
+		setTag(stack, "ench", nil, false)
+		return
+	}
+	list := make([]any, len(enchantments))
+	for i, e := range enchantments {
+		list[i] = map[string]any{"id": e.ID, "lvl": e.Level}
+	}
+	setTag(stack, "ench", list, true)
+}
+
+// Damage returns the damage of the item stack passed, which is stored in its NBT for items with durability.
+func Damage(stack protocol.ItemStack) int32 {
+	damage, _ := stack.NBTData["Damage"].(int32)
+	return damage
+}
+
+// SetDamage sets the damage of the item stack passed. A damage of 0 removes it from the NBT. The NBT of the
+// stack is copied before it is changed, so that stacks sharing the same NBT are not affected.
+func SetDamage(stack *protocol.ItemStack, damage int32) {
+	setTag(stack, "Damage", damage, damage != 0)
+}
+
+// display returns the display compound in the NBT of a stack, or nil if it has none.
+func display(stack protocol.ItemStack) map[string]any {
+	m, _ := stack.NBTData["display"].(map[string]any)
+	return m
+}
+
+// setDisplay sets or, if set is false, removes a tag in the display compound of the NBT of a stack. The
+// display compound is removed once it is empty.
+func setDisplay(stack *protocol.ItemStack, name string, v any, set bool) {
+	m := maps.Clone(display(*stack))
+	if m == nil {
+		m = map[string]any{}
+	}
+	if set {
+		m[name] = v
+	} else {
+		delete(m, name)
+	}
+	setTag(stack, "display", m, len(m) > 0)
+}
+
+// setTag sets or, if set is false, removes a tag in the NBT of a stack, copying the NBT first. NBTData is
+// set to nil once it is empty.
+func setTag(stack *protocol.ItemStack, name string, v any, set bool) {
+	m := maps.Clone(stack.NBTData)
+	if m == nil {
+		m = map[string]any{}
+	}
+	if set {
+		m[name] = v
+	} else {
+		delete(m, name)
+	}
+	if len(m) == 0 {
+		m = nil
+	}
+	stack.NBTData = m
+}
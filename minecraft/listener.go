@@ -43,6 +43,20 @@ type ListenConfig struct {
 	// account.
 	AuthenticationDisabled bool
 
+	// Verifier, if non-nil, is used to verify the multiplayer tokens of players that join instead of the
+	// verifier of the authorization service of Minecraft, which is otherwise obtained when the Listener is
+	// created. It may be set to the verifier of a self-hosted [service.Issuer] to authenticate players in a
+	// closed network, or to one returned by [service.AuthorizationEnvironment.VerifierContext] to verify
+	// tokens against the OpenID configuration of another issuer. Verifier is not used if
+	// AuthenticationDisabled is true.
+	Verifier *oidc.IDTokenVerifier
+
+	// ServiceCache, if non-nil, is used to discover the authorization service of Minecraft and stores the
+	// OpenID configuration and keys used to verify the multiplayer tokens of players that join. A Listener
+	// may then be created and authenticate players while the discovery or authorization service is
+	// unavailable, as long as they were reachable once before. ServiceCache is not used if Verifier is set.
+	ServiceCache *service.Cache
+
 	// DisablePacketEncryption disables packet encryption for accepted connections.
 	// Authentication is unaffected. Only use this on trusted networks.
 	DisablePacketEncryption bool
@@ -140,6 +154,10 @@ type Listener struct {
 	// to the playerCount, no more players will be accepted.
 	playerCount atomic.Int32
 
+	// conns holds all connections that completed their login sequence and have not yet been closed.
+	conns   map[*Conn]struct{}
+	connsMu sync.Mutex
+
 	incoming chan *Conn
 	close    chan struct{}
 
@@ -199,14 +217,16 @@ func (cfg ListenConfig) ListenNetwork(network Network, address string) (*Listene
 		cfg.MaxDecompressedLen = math.MaxInt
 	}
 
-	var verifier *oidc.IDTokenVerifier
-	if !cfg.AuthenticationDisabled {
+	verifier := cfg.Verifier
+	if cfg.AuthenticationDisabled {
+		verifier = nil
+	} else if verifier == nil {
 		var err error
 		ctx := context.Background()
 		if cfg.HTTPClient != nil {
 			ctx = context.WithValue(ctx, oauth2.HTTPClient, cfg.HTTPClient)
 		}
-		verifier, err = oidcVerifier(ctx)
+		verifier, err = oidcVerifier(ctx, cfg.ServiceCache)
 		if err != nil {
 			return nil, fmt.Errorf("create default OIDC verifier: %w", err)
 		}
@@ -225,6 +245,7 @@ func (cfg ListenConfig) ListenNetwork(network Network, address string) (*Listene
 		listener: netListener,
 		packs:    slices.Clone(cfg.ResourcePacks),
 		incoming: make(chan *Conn),
+		conns:    make(map[*Conn]struct{}),
 		close:    make(chan struct{}),
 		key:      key,
 		verifier: verifier,
@@ -257,7 +278,16 @@ var (
 // or verifying the multiplayer token for OpenID authentication.
 // This method is only called once and cached globally which means it will
 // use the HTTP client from the first caller's context (oauth2.HTTPClient).
-func authEnv(ctx context.Context) (*service.AuthorizationEnvironment, error) {
+// If cache is non-nil, the environment is obtained from the cache instead.
+func authEnv(ctx context.Context, cache *service.Cache) (*service.AuthorizationEnvironment, error) {
+	if cache != nil {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+		return cache.AuthorizationEnvironment(ctx)
+	}
 	authEnvCacheMu.Lock()
 	defer authEnvCacheMu.Unlock()
 	if authEnvCache != nil {
@@ -287,8 +317,8 @@ func authEnv(ctx context.Context) (*service.AuthorizationEnvironment, error) {
 
 // oidcVerifier returns the OpenID token verifier that could be used for
 // authenticating new multiplayer tokens issued by the authorization service
-// of Minecraft.
-func oidcVerifier(ctx context.Context) (*oidc.IDTokenVerifier, error) {
+// of Minecraft, discovered using cache if non-nil.
+func oidcVerifier(ctx context.Context, cache *service.Cache) (*oidc.IDTokenVerifier, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -296,7 +326,7 @@ func oidcVerifier(ctx context.Context) (*oidc.IDTokenVerifier, error) {
 	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
-	e, err := authEnv(ctx)
+	e, err := authEnv(ctx, cache)
 	if err != nil {
 		return nil, fmt.Errorf("obtain environment for authorization: %w", err)
 	}
@@ -360,6 +390,24 @@ func (listener *Listener) PlayerCount() int {
 	return int(listener.playerCount.Load())
 }
 
+// Conns returns all connections of the Listener that completed their login sequence and have not yet been
+// closed, in no particular order.
+func (listener *Listener) Conns() []*Conn {
+	listener.connsMu.Lock()
+	defer listener.connsMu.Unlock()
+	conns := make([]*Conn, 0, len(listener.conns))
+	for conn := range listener.conns {
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+// Status returns the ServerStatus currently shown in the server list, as provided by the
+// ServerStatusProvider of the Listener.
+func (listener *Listener) Status() ServerStatus {
+	return listener.status()
+}
+
 // updatePongData updates the pong data of the listener using the current only players, maximum players and
 // server name of the listener, provided the listener isn't currently hijacking the pong of another server.
 func (listener *Listener) updatePongData() {
@@ -465,6 +513,9 @@ func (listener *Listener) status() ServerStatus {
 func (listener *Listener) handleConn(conn *Conn) {
 	defer func() {
 		_ = conn.Close()
+		listener.connsMu.Lock()
+		delete(listener.conns, conn)
+		listener.connsMu.Unlock()
 		listener.playerCount.Add(-1)
 		listener.updatePongData()
 	}()
@@ -485,6 +536,9 @@ func (listener *Listener) handleConn(conn *Conn) {
 				return
 			}
 			if !loggedInBefore && conn.loggedIn {
+				listener.connsMu.Lock()
+				listener.conns[conn] = struct{}{}
+				listener.connsMu.Unlock()
 				select {
 				case <-listener.close:
 					// The listener was closed while this one was logged in, so the incoming channel will be
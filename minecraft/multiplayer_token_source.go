@@ -19,6 +19,15 @@ type MultiplayerTokenSource interface {
 	MultiplayerToken(ctx context.Context, key *ecdsa.PublicKey) (jwt string, err error)
 }
 
+// ChainSource supplies the Minecraft authentication chain used to populate the trusted identity data of a
+// login. If the [Dialer.TokenSource] of a Dialer implements ChainSource, the chain is requested from it rather
+// than directly using the Xbox Live client of the Dialer, for example so that logins may be rate limited.
+type ChainSource interface {
+	// MinecraftChain requests a Minecraft authentication chain for the key passed, as returned by
+	// auth.RequestMinecraftChain.
+	MinecraftChain(ctx context.Context, key *ecdsa.PrivateKey) (chain string, err error)
+}
+
 // multiplayerTokenSource is an implementation of MultiplayerTokenSource used by default, which uses the
 // underlying [service.TokenSource] to log in to the Minecraft: Bedrock Edition's network services.
 type multiplayerTokenSource struct {
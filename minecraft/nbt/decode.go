@@ -44,11 +44,11 @@ func (d *Decoder) Decode(v any) error {
 	if val.Kind() != reflect.Pointer {
 		return NonPointerTypeError{ActualType: val.Type()}
 	}
-	tagType, tagName, err := d.tag()
+	t, tagName, err := d.tag()
 	if err != nil {
 		return err
 	}
-	if tagType == tagEnd && d.AllowZero {
+	if t == TagEnd && d.AllowZero {
 		// Some implementations write an empty tree as a single TAG_End. When decoding into a map, make sure we
 		// clear it so callers don't accidentally keep stale data.
 		if m, ok := v.(*map[string]any); ok {
@@ -60,7 +60,7 @@ func (d *Decoder) Decode(v any) error {
 		}
 		return nil
 	}
-	return d.unmarshalTag(val.Elem(), tagType, tagName)
+	return d.unmarshalTag(val.Elem(), t, tagName)
 }
 
 // Unmarshal decodes a slice of NBT data into a pointer to a Go values passed. Marshal will use the
@@ -92,6 +92,10 @@ func (d *Decoder) Decode(v any) error {
 // Unmarshal accepts struct fields with the 'nbt' struct tag. The 'nbt' struct tag allows setting the name of
 // a field that some tag should be decoded in. Setting the struct tag to '-' means that field will never be
 // filled by the decoding of the data passed.
+//
+// Values implementing Unmarshaler have their UnmarshalNBT method called with the raw tag. Values implementing
+// encoding.TextUnmarshaler, but not Unmarshaler, are decoded from a TAG_String using their UnmarshalText
+// method. A RawMessage is filled with the raw tag without decoding it.
 func Unmarshal(data []byte, v any) error {
 	return UnmarshalEncoding(data, v, NetworkLittleEndian)
 }
@@ -133,7 +137,7 @@ func (d *Decoder) unmarshalCompoundAny(m map[string]any) error {
 		if !nestedTagType.IsValid() {
 			return UnknownTagError{Off: d.r.off, Op: "Map", TagType: nestedTagType}
 		}
-		if nestedTagType == tagEnd {
+		if nestedTagType == TagEnd {
 			// We reached the end of the compound.
 			break
 		}
@@ -149,14 +153,38 @@ func (d *Decoder) unmarshalCompoundAny(m map[string]any) error {
 
 // unmarshalTag decodes a tag from the decoder's input stream into the reflect.Value passed, assuming the tag
 // has the type and name passed.
-func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) error {
+func (d *Decoder) unmarshalTag(val reflect.Value, t TagType, tagName string) error {
+	if val.Type() == rawMessageType {
+		raw, err := d.raw(t, tagName)
+		if err != nil {
+			return err
+		}
+		val.Set(reflect.ValueOf(raw))
+		return nil
+	}
+	// Values of an interface type cannot implement Unmarshaler: Only the types stored in them can.
+	if val.Kind() != reflect.Interface {
+		if u, tu := unmarshalerValue(val); u != nil {
+			raw, err := d.raw(t, tagName)
+			if err != nil {
+				return err
+			}
+			return u.UnmarshalNBT(raw)
+		} else if tu != nil && t == TagString {
+			value, err := d.Encoding.String(d.r)
+			if err != nil {
+				return err
+			}
+			return tu.UnmarshalText([]byte(value))
+		}
+	}
 	k := val.Kind()
 	switch t {
 	default:
 		return UnknownTagError{Off: d.r.off, TagType: t, Op: "Match"}
-	case tagEnd:
-		return UnexpectedTagError{Off: d.r.off, TagType: tagEnd}
-	case tagByte:
+	case TagEnd:
+		return UnexpectedTagError{Off: d.r.off, TagType: TagEnd}
+	case TagByte:
 		value, err := d.r.ReadByte()
 		if err != nil {
 			return BufferOverrunError{Op: "Byte"}
@@ -171,7 +199,7 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 		default:
 			return InvalidTypeError{Off: d.r.off, FieldType: val.Type(), Field: tagName, TagType: t}
 		}
-	case tagInt16:
+	case TagShort:
 		value, err := d.Encoding.Int16(d.r)
 		if err != nil {
 			return err
@@ -184,7 +212,7 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 		default:
 			return InvalidTypeError{Off: d.r.off, FieldType: val.Type(), Field: tagName, TagType: t}
 		}
-	case tagInt32:
+	case TagInt:
 		value, err := d.Encoding.Int32(d.r)
 		if err != nil {
 			return err
@@ -197,7 +225,7 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 		default:
 			return InvalidTypeError{Off: d.r.off, FieldType: val.Type(), Field: tagName, TagType: t}
 		}
-	case tagInt64:
+	case TagLong:
 		value, err := d.Encoding.Int64(d.r)
 		if err != nil {
 			return err
@@ -210,7 +238,7 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 		default:
 			return InvalidTypeError{Off: d.r.off, FieldType: val.Type(), Field: tagName, TagType: t}
 		}
-	case tagFloat32:
+	case TagFloat:
 		value, err := d.Encoding.Float32(d.r)
 		if err != nil {
 			return err
@@ -223,7 +251,7 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 		default:
 			return InvalidTypeError{Off: d.r.off, FieldType: val.Type(), Field: tagName, TagType: t}
 		}
-	case tagFloat64:
+	case TagDouble:
 		value, err := d.Encoding.Float64(d.r)
 		if err != nil {
 			return err
@@ -236,7 +264,7 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 		default:
 			return InvalidTypeError{Off: d.r.off, FieldType: val.Type(), Field: tagName, TagType: t}
 		}
-	case tagString:
+	case TagString:
 		value, err := d.Encoding.String(d.r)
 		if err != nil {
 			return err
@@ -249,7 +277,7 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 		default:
 			return InvalidTypeError{Off: d.r.off, FieldType: val.Type(), Field: tagName, TagType: t}
 		}
-	case tagByteArray:
+	case TagByteArray:
 		length, err := d.Encoding.Int32(d.r)
 		if err != nil {
 			return err
@@ -277,7 +305,7 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 			return InvalidTypeError{Off: d.r.off, FieldType: val.Type(), Field: tagName, TagType: t}
 		}
 		val.Set(value)
-	case tagInt32Array:
+	case TagIntArray:
 		s, err := d.Encoding.Int32Slice(d.r)
 		if err != nil {
 			return err
@@ -297,7 +325,7 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 		}
 		val.Set(value)
 
-	case tagInt64Array:
+	case TagLongArray:
 		s, err := d.Encoding.Int64Slice(d.r)
 		if err != nil {
 			return err
@@ -317,13 +345,13 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 		}
 		val.Set(value)
 
-	case tagSlice:
+	case TagList:
 		d.depth++
 		listTypeByte, err := d.r.ReadByte()
 		if err != nil {
 			return BufferOverrunError{Op: "Slice"}
 		}
-		listType := tagType(listTypeByte)
+		listType := TagType(listTypeByte)
 		if !listType.IsValid() {
 			return UnknownTagError{Off: d.r.off, TagType: listType, Op: "Slice"}
 		}
@@ -334,8 +362,13 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 		if val.Kind() == reflect.Interface {
 			sliceType = reflect.SliceOf(sliceType)
 		}
-		switch listType {
-		case tagByte:
+		elemCase := listType
+		if k == reflect.Slice && customDecoding(sliceType.Elem()) {
+			// Elements that decode themselves are always decoded one by one.
+			elemCase = TagEnd
+		}
+		switch elemCase {
+		case TagByte:
 			length, err := d.Encoding.Int32(d.r)
 			if err != nil {
 				return BufferOverrunError{Op: "ByteSlice"}
@@ -361,7 +394,7 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 			default:
 				return InvalidTypeError{Off: d.r.off, FieldType: val.Type().Elem(), Field: tagName, TagType: listType}
 			}
-		case tagInt32:
+		case TagInt:
 			b, err := d.Encoding.Int32Slice(d.r)
 			if err != nil {
 				return BufferOverrunError{Op: "Int32Slice"}
@@ -372,7 +405,7 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 			default:
 				return InvalidTypeError{Off: d.r.off, FieldType: val.Type().Elem(), Field: tagName, TagType: listType}
 			}
-		case tagInt64:
+		case TagLong:
 			b, err := d.Encoding.Int64Slice(d.r)
 			if err != nil {
 				return BufferOverrunError{Op: "Int64Slice"}
@@ -406,7 +439,7 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 			d.depth--
 		}
 
-	case tagStruct:
+	case TagCompound:
 		d.depth++
 		switch val.Kind() {
 		default:
@@ -421,7 +454,7 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 				if err != nil {
 					return err
 				}
-				if nestedTagType == tagEnd {
+				if nestedTagType == TagEnd {
 					// We reached the end of the fields.
 					break
 				}
@@ -475,7 +508,7 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 				if !nestedTagType.IsValid() {
 					return UnknownTagError{Off: d.r.off, Op: "Map", TagType: nestedTagType}
 				}
-				if nestedTagType == tagEnd {
+				if nestedTagType == TagEnd {
 					// We reached the end of the compound.
 					break
 				}
@@ -492,6 +525,27 @@ func (d *Decoder) unmarshalTag(val reflect.Value, t tagType, tagName string) err
 	return nil
 }
 
+// customDecoding checks if values of the type passed control their own decoding by implementing Unmarshaler
+// or encoding.TextUnmarshaler, or by being a RawMessage.
+func customDecoding(t reflect.Type) bool {
+	if t == rawMessageType {
+		return true
+	}
+	p := reflect.PointerTo(t)
+	return t.Implements(unmarshalerType) || t.Implements(textUnmarshalerType) || p.Implements(unmarshalerType) || p.Implements(textUnmarshalerType)
+}
+
+// raw reads the payload of a tag with the type and name passed into a RawMessage.
+func (d *Decoder) raw(t TagType, tagName string) (RawMessage, error) {
+	buf := bytes.NewBuffer(nil)
+	r := &TokenReader{Encoding: d.Encoding, MaxDepth: max(maximumNestingDepth-d.depth, 1), r: d.r}
+	w := &TokenWriter{Encoding: d.Encoding, w: newOffsetWriter(buf)}
+	if err := copyTag(r, w, t, tagName); err != nil {
+		return RawMessage{}, err
+	}
+	return RawMessage{Type: t, Data: buf.Bytes(), Encoding: d.Encoding}, nil
+}
+
 // populateFields populates the map passed with the fields of the reflect representation of a struct passed.
 // It takes into consideration the nbt struct field tag.
 func (d *Decoder) populateFields(val reflect.Value, m map[string]reflect.Value) {
@@ -522,7 +576,7 @@ func (d *Decoder) populateFields(val reflect.Value, m map[string]reflect.Value)
 }
 
 // tag reads a tag from the decoder, and its name if the tag type is not a TAG_End.
-func (d *Decoder) tag() (t tagType, tagName string, err error) {
+func (d *Decoder) tag() (t TagType, tagName string, err error) {
 	if d.depth >= maximumNestingDepth {
 		return 0, "", MaximumDepthReachedError{}
 	}
@@ -533,12 +587,12 @@ func (d *Decoder) tag() (t tagType, tagName string, err error) {
 	if err != nil {
 		return 0, "", BufferOverrunError{Op: "ReadTag"}
 	}
-	t = tagType(tagTypeByte)
-	if _, ok := d.Encoding.(networkBigEndian); ok && t == tagStruct && d.depth == 0 {
+	t = TagType(tagTypeByte)
+	if _, ok := d.Encoding.(networkBigEndian); ok && t == TagCompound && d.depth == 0 {
 		// As of Minecraft Java 1.20.2, the name of the root compound tag is not written over the network.
 		return t, "", err
 	}
-	if t != tagEnd {
+	if t != TagEnd {
 		// Only read a tag name if the tag's type is not TAG_End.
 		tagName, err = d.Encoding.String(d.r)
 	}
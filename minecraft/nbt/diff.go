@@ -0,0 +1,110 @@
+package nbt
+
+import (
+	"reflect"
+	"slices"
+)
+
+// ChangeKind is the kind of a Change between two NBT trees.
+type ChangeKind uint8
+
+const (
+	// ChangeAdded is a tag that is present in the new tree, but not in the old tree.
+	ChangeAdded ChangeKind = iota
+	// ChangeRemoved is a tag that is present in the old tree, but not in the new tree.
+	ChangeRemoved
+	// ChangeModified is a tag that is present in both trees, but with a different value or type.
+	ChangeModified
+)
+
+// String ...
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	default:
+		return "modified"
+	}
+}
+
+// Change is a single difference between two NBT trees, as returned by Diff.
+type Change struct {
+	// Kind is the kind of the change.
+	Kind ChangeKind
+	// Path is the path to the tag that changed.
+	Path Path
+	// Old is the value of the tag in the old tree. It is nil for a ChangeAdded.
+	Old any
+	// New is the value of the tag in the new tree. It is nil for a ChangeRemoved.
+	New any
+}
+
+// String ...
+func (c Change) String() string {
+	return c.Kind.String() + " " + c.Path.String()
+}
+
+// Diff returns the changes between two decoded NBT trees, such as the map[string]any values filled by
+// Unmarshal. Compounds are compared key by key and lists and arrays element by element, so that the changes
+// point to the deepest tags that differ. Changes are ordered by path, with compound keys in alphabetical
+// order and list elements in index order.
+func Diff(old, new any) []Change {
+	var changes []Change
+	diff(Path{}, old, new, &changes)
+	return changes
+}
+
+// diff appends the changes between the values old and new at the path passed to changes.
+func diff(path Path, old, new any, changes *[]Change) {
+	oldMap, oldIsMap := old.(map[string]any)
+	newMap, newIsMap := new.(map[string]any)
+	if oldIsMap && newIsMap {
+		keys := make([]string, 0, len(oldMap)+len(newMap))
+		for k := range oldMap {
+			keys = append(keys, k)
+		}
+		for k := range newMap {
+			if _, ok := oldMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			o, inOld := oldMap[k]
+			n, inNew := newMap[k]
+			switch {
+			case !inNew:
+				*changes = append(*changes, Change{Kind: ChangeRemoved, Path: path.Key(k), Old: o})
+			case !inOld:
+				*changes = append(*changes, Change{Kind: ChangeAdded, Path: path.Key(k), New: n})
+			default:
+				diff(path.Key(k), o, n, changes)
+			}
+		}
+		return
+	}
+	oldList, newList := reflect.ValueOf(old), reflect.ValueOf(new)
+	if isList(oldList) && isList(newList) && oldList.Type().Elem() == newList.Type().Elem() {
+		for i := 0; i < max(oldList.Len(), newList.Len()); i++ {
+			switch {
+			case i >= newList.Len():
+				*changes = append(*changes, Change{Kind: ChangeRemoved, Path: path.Index(i), Old: oldList.Index(i).Interface()})
+			case i >= oldList.Len():
+				*changes = append(*changes, Change{Kind: ChangeAdded, Path: path.Index(i), New: newList.Index(i).Interface()})
+			default:
+				diff(path.Index(i), oldList.Index(i).Interface(), newList.Index(i).Interface(), changes)
+			}
+		}
+		return
+	}
+	if !reflect.DeepEqual(old, new) {
+		*changes = append(*changes, Change{Kind: ChangeModified, Path: path, Old: old, New: new})
+	}
+}
+
+// isList checks if a value is a TAG_List or one of the array tags.
+func isList(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+}
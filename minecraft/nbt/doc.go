@@ -6,6 +6,13 @@
 // does, using nbt.Marshal() and nbt.Unmarshal when working with byte slices, and nbt.NewEncoder() and
 // nbt.NewDecoder() when working with readers or writers.
 //
+// For NBT that should not be decoded into Go values as a whole, such as large structure files, the
+// nbt.TokenReader and nbt.TokenWriter types read and write NBT one token at a time. Values may also be
+// converted from and to the stringified NBT used in Minecraft commands using nbt.MarshalSNBT() and
+// nbt.UnmarshalSNBT(). Decoded trees may be queried and modified using an nbt.Path, and compared using
+// nbt.Diff(). NBT files, such as Java Edition .dat files and Bedrock Edition level.dat files, are read and
+// written with nbt.ReadFile() and nbt.WriteFile(), which detect their compression, header and encoding.
+//
 // The package encodes and decodes the following Go types with the following NBT tags.
 //   byte/uint8: TAG_Byte
 //   bool: TAG_Byte
@@ -22,6 +29,10 @@
 //   struct{...}: TAG_Compound
 //   map[string]<type/any>: TAG_Compound
 //
+// Types may control their own encoding by implementing nbt.Marshaler and nbt.Unmarshaler. Types implementing
+// encoding.TextMarshaler and encoding.TextUnmarshaler are encoded as TAG_String. Decoding of a subtree may be
+// deferred by decoding it into an nbt.RawMessage.
+//
 // Structures decoded or encoded may have struct field tags in a comparable way to the JSON standard library.
 // The 'nbt' struct tag may be filled out the following ways:
 //   '-': Ignores the field completely when encoding and decoding.
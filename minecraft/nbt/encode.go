@@ -21,16 +21,7 @@ type Encoder struct {
 
 // NewEncoder returns a new encoder for the output stream writer passed.
 func NewEncoder(w io.Writer) *Encoder {
-	var writer *offsetWriter
-	if byteWriter, ok := w.(io.ByteWriter); ok {
-		writer = &offsetWriter{Writer: w, WriteByte: byteWriter.WriteByte}
-	} else {
-		writer = &offsetWriter{Writer: w, WriteByte: func(b byte) error {
-			_, err := w.Write([]byte{b})
-			return err
-		}}
-	}
-	return &Encoder{w: writer, Encoding: NetworkLittleEndian}
+	return &Encoder{w: newOffsetWriter(w), Encoding: NetworkLittleEndian}
 }
 
 // NewEncoderWithEncoding returns a new encoder for the output stream writer passed using a specific encoding.
@@ -75,6 +66,10 @@ func (e *Encoder) Encode(v any) error {
 // a field that some tag should be decoded in. Setting the struct tag to '-' means that field will never be
 // filled by the decoding of the data passed. Suffixing the 'nbt' struct tag with ',omitempty' will prevent
 // the field from being encoded if it is equal to its default value.
+//
+// Values implementing Marshaler are encoded as the value returned by their MarshalNBT method. Values
+// implementing encoding.TextMarshaler, but not Marshaler, are encoded as TAG_String. A RawMessage is encoded
+// as the tag it holds.
 func Marshal(v any) ([]byte, error) {
 	return MarshalEncoding(v, NetworkLittleEndian)
 }
@@ -104,30 +99,78 @@ var bufferPool = sync.Pool{
 // name and payload. An error is returned if any values in the reflect.Value found were not representable
 // with an NBT tag.
 func (e *Encoder) marshal(val reflect.Value, tagName string) error {
+	val, err := marshalerValue(val)
+	if err != nil {
+		return err
+	}
 	if val.Kind() == reflect.Interface {
 		val = val.Elem()
 	}
 	if val.Kind() == reflect.Ptr {
 		val = val.Elem()
 	}
-	tagType := tagFromType(val.Type())
-	if tagType == math.MaxUint8 {
+	t := tagFromValue(val)
+	if t == math.MaxUint8 || t == TagEnd {
 		return IncompatibleTypeError{Type: val.Type(), ValueName: tagName}
 	}
-	if err := e.writeTag(tagType, tagName); err != nil {
+	if err := e.writeTag(t, tagName); err != nil {
 		return err
 	}
-	return e.encode(val, tagName)
+	return e.encodeValue(val, tagName)
+}
+
+// tagFromValue returns the tag type a reflect.Value is encoded as. Unlike tagFromType, it takes the type of a
+// RawMessage into account.
+func tagFromValue(val reflect.Value) TagType {
+	if val.IsValid() && val.Type() == rawMessageType {
+		return val.Interface().(RawMessage).Type
+	}
+	return tagFromType(val.Type())
+}
+
+// elemTag returns the tag type that a slice element of an interface type or a type with custom encoding is
+// encoded as.
+func elemTag(val reflect.Value) (TagType, error) {
+	val, err := marshalerValue(val)
+	if err != nil {
+		return 0, err
+	}
+	if val.Kind() == reflect.Interface || val.Kind() == reflect.Pointer {
+		val = val.Elem()
+	}
+	return tagFromValue(val), nil
 }
 
 // encode encodes the payload of a value passed with the tag name passed. Unlike calling Encoder.marshal(), it
 // does not write the name and type of the tag.
 func (e *Encoder) encode(val reflect.Value, tagName string) error {
+	val, err := marshalerValue(val)
+	if err != nil {
+		return err
+	}
+	return e.encodeValue(val, tagName)
+}
+
+// encodeValue encodes the payload of a value passed with the tag name passed, after the value to encode in
+// place of it was obtained using marshalerValue.
+func (e *Encoder) encodeValue(val reflect.Value, tagName string) error {
 	kind := val.Kind()
 	if kind == reflect.Interface {
 		val = val.Elem()
 		kind = val.Kind()
 	}
+	if kind == reflect.Pointer {
+		val = val.Elem()
+		kind = val.Kind()
+	}
+	if val.Type() == rawMessageType {
+		raw := val.Interface().(RawMessage)
+		if raw.Type == TagEnd {
+			// A zero RawMessage does not hold a tag.
+			return IncompatibleTypeError{Type: val.Type(), ValueName: tagName}
+		}
+		return raw.transcode(e.w, e.Encoding)
+	}
 	switch vk := kind; vk {
 	case reflect.Uint8:
 		return e.w.WriteByte(byte(val.Uint()))
@@ -199,19 +242,34 @@ func (e *Encoder) encode(val reflect.Value, tagName string) error {
 	case reflect.Slice:
 		e.depth++
 		elemType := val.Type().Elem()
-		if elemType.Kind() == reflect.Interface {
-			if val.Len() == 0 {
-				// If the slice is empty, we cannot find out the type of the interface slice. Luckily the NBT
-				// format allows a byte type for empty lists.
-				elemType = nil
-			} else {
-				// The slice is not empty, so we'll simply get the tag type from the first element.
-				elemType = val.Index(0).Elem().Type()
+		custom := elemType.Kind() == reflect.Interface || customEncoding(elemType)
+		var listType TagType
+		switch {
+		case val.Len() == 0 && custom:
+			// If the slice is empty, we cannot find out the type of the interface slice. Luckily the NBT
+			// format allows a byte type for empty lists.
+			listType = TagEnd
+		case custom:
+			// The slice is not empty, so we take the tag type from the first element and make sure all other
+			// elements have the same tag type.
+			first, err := elemTag(val.Index(0))
+			if err != nil {
+				return err
+			}
+			for i := 1; i < val.Len(); i++ {
+				t, err := elemTag(val.Index(i))
+				if err != nil {
+					return err
+				}
+				if t != first {
+					return MismatchedListTypeError{ValueName: tagName, Index: i, ListType: first, TagType: t}
+				}
 			}
+			listType = first
+		default:
+			listType = tagFromType(elemType)
 		}
-
-		listType := tagFromType(elemType)
-		if listType == math.MaxUint8 {
+		if listType == math.MaxUint8 || (listType == TagEnd && val.Len() != 0) {
 			return IncompatibleTypeError{Type: val.Type(), ValueName: tagName}
 		}
 		if err := e.w.WriteByte(byte(listType)); err != nil {
@@ -222,6 +280,13 @@ func (e *Encoder) encode(val reflect.Value, tagName string) error {
 		}
 		for i := 0; i < val.Len(); i++ {
 			nestedValue := val.Index(i)
+			if !custom {
+				// The elements are of a type without custom encoding, so they are encoded as they are.
+				if err := e.encodeValue(nestedValue, ""); err != nil {
+					return err
+				}
+				continue
+			}
 			if err := e.encode(nestedValue, ""); err != nil {
 				return err
 			}
@@ -234,7 +299,7 @@ func (e *Encoder) encode(val reflect.Value, tagName string) error {
 			return err
 		}
 		e.depth--
-		return e.w.WriteByte(byte(tagEnd))
+		return e.w.WriteByte(byte(TagEnd))
 
 	case reflect.Map:
 		e.depth++
@@ -248,11 +313,17 @@ func (e *Encoder) encode(val reflect.Value, tagName string) error {
 			}
 		}
 		e.depth--
-		return e.w.WriteByte(byte(tagEnd))
+		return e.w.WriteByte(byte(TagEnd))
 	}
 	return nil
 }
 
+// customEncoding checks if values of the type passed control their own encoding by implementing Marshaler or
+// encoding.TextMarshaler, or by being a RawMessage.
+func customEncoding(t reflect.Type) bool {
+	return t == rawMessageType || methodsOf(t).custom()
+}
+
 // writeStructValues writes the values of all struct fields of a reflect.Value (must be of struct type) to
 // the io.Writer of the encoder.
 func (e *Encoder) writeStructValues(val reflect.Value) error {
@@ -284,6 +355,10 @@ func (e *Encoder) writeStructValues(val reflect.Value) error {
 		if tag != "" {
 			tagName = tag
 		}
+		if fieldValue.Type() == rawMessageType && fieldValue.Interface().(RawMessage).Type == TagEnd {
+			// A zero RawMessage does not hold a tag, so it is omitted like a field with ',omitempty'.
+			continue
+		}
 		if err := e.marshal(fieldValue, tagName); err != nil {
 			return err
 		}
@@ -292,14 +367,14 @@ func (e *Encoder) writeStructValues(val reflect.Value) error {
 }
 
 // writeTag writes a single tag to the io.Writer held by the Encoder. The tag type and the name are written.
-func (e *Encoder) writeTag(t tagType, tagName string) error {
+func (e *Encoder) writeTag(t TagType, tagName string) error {
 	if e.depth >= maximumNestingDepth {
 		return MaximumDepthReachedError{}
 	}
 	if err := e.w.WriteByte(byte(t)); err != nil {
 		return err
 	}
-	if _, ok := e.Encoding.(networkBigEndian); ok && t == tagStruct && e.depth == 0 {
+	if _, ok := e.Encoding.(networkBigEndian); ok && t == TagCompound && e.depth == 0 {
 		// As of Minecraft Java 1.20.2, the name of the root compound tag is not written over the network.
 		return nil
 	}
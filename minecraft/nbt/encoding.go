@@ -162,6 +162,9 @@ func (e networkLittleEndian) String(r *offsetReader) (string, error) {
 	if length > maxStringSize {
 		return "", InvalidStringError{N: uint(length), Off: r.off, Err: errStringTooLong}
 	}
+	if err := r.reserve(int64(length)); err != nil {
+		return "", err
+	}
 	data := make([]byte, length)
 	if _, err := r.Read(data); err != nil {
 		return "", BufferOverrunError{Op: "String"}
@@ -130,6 +130,9 @@ func (e bigEndian) String(r *offsetReader) (string, error) {
 	if n < 0 {
 		return "", BufferOverrunError{Op: "String"}
 	}
+	if err := r.reserve(int64(uint16(n))); err != nil {
+		return "", err
+	}
 	b := make([]byte, uint16(n))
 	if _, err := r.Read(b); err != nil {
 		return "", BufferOverrunError{Op: "String"}
@@ -294,6 +297,9 @@ func (e littleEndian) String(r *offsetReader) (string, error) {
 	if err != nil {
 		return "", BufferOverrunError{Op: "String"}
 	}
+	if err := r.reserve(int64(uint16(strLen))); err != nil {
+		return "", err
+	}
 	b := make([]byte, uint16(strLen))
 	if _, err := r.Read(b); err != nil {
 		return "", BufferOverrunError{Op: "String"}
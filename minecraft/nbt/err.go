@@ -11,7 +11,7 @@ import (
 type InvalidTypeError struct {
 	Off       int64
 	Field     string
-	TagType   tagType
+	TagType   TagType
 	FieldType reflect.Type
 }
 
@@ -25,7 +25,7 @@ func (err InvalidTypeError) Error() string {
 type UnknownTagError struct {
 	Off     int64
 	Op      string
-	TagType tagType
+	TagType TagType
 }
 
 // Error ...
@@ -36,7 +36,7 @@ func (err UnknownTagError) Error() string {
 // UnexpectedTagError is returned when a tag type encountered was not expected, and thus valid in its context.
 type UnexpectedTagError struct {
 	Off     int64
-	TagType tagType
+	TagType TagType
 }
 
 // Error ...
@@ -85,7 +85,7 @@ func (err InvalidArraySizeError) Error() string {
 type UnexpectedNamedTagError struct {
 	Off     int64
 	TagName string
-	TagType tagType
+	TagType TagType
 }
 
 // Error ...
@@ -118,6 +118,20 @@ func (err IncompatibleTypeError) Error() string {
 	return fmt.Sprintf("nbt: value type %v (%v) cannot be translated to an NBT tag", err.Type, err.ValueName)
 }
 
+// MismatchedListTypeError is returned if the elements of a slice encoded as a TAG_List are not all encoded
+// as the same tag type.
+type MismatchedListTypeError struct {
+	ValueName string
+	Index     int
+	ListType  TagType
+	TagType   TagType
+}
+
+// Error ...
+func (err MismatchedListTypeError) Error() string {
+	return fmt.Sprintf("nbt: element %v of list (%v) is %v, but the list holds %v", err.Index, err.ValueName, err.TagType, err.ListType)
+}
+
 var errStringTooLong = errors.New("string length exceeds maximum length")
 
 // InvalidStringError is returned if a string read is not valid, meaning it does not exist exclusively out of
@@ -168,3 +182,39 @@ type InvalidVarintError struct {
 func (err InvalidVarintError) Error() string {
 	return fmt.Sprintf("nbt: varint did not terminate after %v bytes at offset %v", err.N, err.Off)
 }
+
+// LimitExceededError is returned by a TokenReader or TokenWriter if one of its limits is exceeded.
+type LimitExceededError struct {
+	Off   int64
+	Limit string
+	Max   int64
+}
+
+// Error ...
+func (err LimitExceededError) Error() string {
+	return fmt.Sprintf("nbt: %v limit of %v exceeded at offset %v", err.Limit, err.Max, err.Off)
+}
+
+// InvalidTokenError is returned by a TokenWriter if a Token written is not valid in its context, such as a
+// TokenEnd outside of a compound or list, or a list element of the wrong type.
+type InvalidTokenError struct {
+	Off    int64
+	Token  Token
+	Reason string
+}
+
+// Error ...
+func (err InvalidTokenError) Error() string {
+	return fmt.Sprintf("nbt: invalid %v token for %v tag '%v' at offset %v: %v", err.Token.Kind, err.Token.Type, err.Token.Name, err.Off, err.Reason)
+}
+
+// SyntaxError is returned by UnmarshalSNBT and ParsePath if the SNBT or path passed is not valid.
+type SyntaxError struct {
+	Off int
+	Msg string
+}
+
+// Error ...
+func (err SyntaxError) Error() string {
+	return fmt.Sprintf("nbt: syntax error at offset %v: %v", err.Off, err.Msg)
+}
@@ -0,0 +1,186 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+)
+
+// Compression is a compression algorithm that an NBT file may be compressed with.
+type Compression uint8
+
+const (
+	// CompressionNone is used for uncompressed files, such as Bedrock Edition level.dat and .mcstructure
+	// files.
+	CompressionNone Compression = iota
+	// CompressionGzip is used for most Java Edition files, such as level.dat, .nbt structure files and .schem
+	// files.
+	CompressionGzip
+	// CompressionZlib is used for Java Edition region file chunks.
+	CompressionZlib
+)
+
+// String ...
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionGzip:
+		return "gzip"
+	case CompressionZlib:
+		return "zlib"
+	default:
+		return fmt.Sprintf("Compression(%v)", byte(c))
+	}
+}
+
+// File describes the format of an NBT file: Its compression, encoding, header and the name of its root tag.
+// A File returned by ReadFile may be passed to WriteFile to write the file back in the same format.
+type File struct {
+	// Encoding is the encoding of the NBT in the file: BigEndian for Java Edition files and LittleEndian for
+	// Bedrock Edition files. If nil, LittleEndian is used when writing.
+	Encoding Encoding
+	// Compression is the compression the file is compressed with.
+	Compression Compression
+	// RootName is the name of the root tag of the file. It is usually empty, but some files, such as Java
+	// Edition .schem files, name it.
+	RootName string
+	// Header specifies if the NBT is preceded by the 8-byte header used by Bedrock Edition level.dat files,
+	// which holds the storage version and the length of the NBT.
+	Header bool
+	// HeaderVersion is the storage version written in the header if Header is true.
+	HeaderVersion int32
+}
+
+// ReadFile reads the NBT file at the path passed and decodes it into a pointer to a Go value passed, the
+// same way Unmarshal would. The compression, header and encoding of the file are detected automatically and
+// returned in a File, together with the name of the root tag.
+func ReadFile(path string, v any) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, err
+	}
+	return UnmarshalFile(data, v)
+}
+
+// WriteFile encodes a Go value to NBT, the same way Marshal would, and writes it to a file at the path
+// passed in the format described by f.
+func WriteFile(path string, v any, f File) error {
+	data, err := MarshalFile(v, f)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// UnmarshalFile decodes the content of an NBT file into a pointer to a Go value passed. It detects the
+// format of the file the same way ReadFile does.
+func UnmarshalFile(data []byte, v any) (File, error) {
+	var f File
+	switch {
+	case len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b:
+		f.Compression = CompressionGzip
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return f, fmt.Errorf("nbt: read gzip header: %w", err)
+		}
+		if data, err = io.ReadAll(r); err != nil {
+			return f, fmt.Errorf("nbt: decompress gzip: %w", err)
+		}
+	case len(data) >= 2 && data[0]&0x0f == 8 && (uint16(data[0])<<8|uint16(data[1]))%31 == 0:
+		// The zlib header is only two bytes with a checksum, so uncompressed data could in theory look like
+		// one. Such data is treated as uncompressed if it fails to decompress.
+		if r, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+			if decompressed, err := io.ReadAll(r); err == nil {
+				f.Compression, data = CompressionZlib, decompressed
+			}
+		}
+	}
+	if len(data) > 8 && int(binary.LittleEndian.Uint32(data[4:])) == len(data)-8 && TagType(data[8]) == TagCompound {
+		f.Header, f.HeaderVersion = true, int32(binary.LittleEndian.Uint32(data))
+		data = data[8:]
+	}
+
+	// Java Edition files are big endian and generally compressed, while Bedrock Edition files are little
+	// endian and generally not, so the most likely encoding is tried first.
+	candidates := []Encoding{LittleEndian, BigEndian}
+	if f.Compression != CompressionNone && !f.Header {
+		candidates = []Encoding{BigEndian, LittleEndian}
+	}
+	var err error
+	for _, encoding := range candidates {
+		if f.RootName, err = scanFile(data, encoding); err == nil {
+			f.Encoding = encoding
+			return f, UnmarshalEncoding(data, v, encoding)
+		}
+	}
+	return f, fmt.Errorf("nbt: detect file encoding: %w", err)
+}
+
+// scanFile checks if data holds exactly one tag encoded with the encoding passed and returns its name.
+func scanFile(data []byte, encoding Encoding) (string, error) {
+	r := NewTokenReader(bytes.NewReader(data), encoding)
+	root, err := r.Next()
+	if err != nil {
+		return "", err
+	}
+	if r.Depth() > 0 {
+		if err := r.Skip(); err != nil {
+			return "", err
+		}
+	}
+	if r.Offset() != int64(len(data)) {
+		return "", fmt.Errorf("%v bytes left after root tag", int64(len(data))-r.Offset())
+	}
+	return root.Name, nil
+}
+
+// MarshalFile encodes a Go value to NBT, the same way Marshal would, and returns it as the content of an NBT
+// file in the format described by f.
+func MarshalFile(v any, f File) ([]byte, error) {
+	encoding := f.Encoding
+	if encoding == nil {
+		encoding = LittleEndian
+	}
+	buf := bytes.NewBuffer(nil)
+	if f.Header {
+		// The length in the header is filled out once the NBT is written.
+		buf.Write(make([]byte, 8))
+	}
+	enc := NewEncoderWithEncoding(buf, encoding)
+	if err := enc.marshal(reflect.ValueOf(v), f.RootName); err != nil {
+		return nil, err
+	}
+	data := buf.Bytes()
+	if f.Header {
+		binary.LittleEndian.PutUint32(data, uint32(f.HeaderVersion))
+		binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+	}
+
+	var (
+		compressed bytes.Buffer
+		w          io.WriteCloser
+	)
+	switch f.Compression {
+	case CompressionNone:
+		return data, nil
+	case CompressionGzip:
+		w = gzip.NewWriter(&compressed)
+	case CompressionZlib:
+		w = zlib.NewWriter(&compressed)
+	default:
+		return nil, fmt.Errorf("nbt: unknown compression %v", f.Compression)
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("nbt: compress %v: %w", f.Compression, err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("nbt: compress %v: %w", f.Compression, err)
+	}
+	return compressed.Bytes(), nil
+}
@@ -0,0 +1,217 @@
+package nbt
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// Marshaler is implemented by types that control their own NBT encoding. MarshalNBT returns the Go value that
+// is encoded in place of the value implementing Marshaler. It may return any value that Marshal accepts,
+// including other Marshalers and a RawMessage.
+type Marshaler interface {
+	MarshalNBT() (any, error)
+}
+
+// Unmarshaler is implemented by types that control their own NBT decoding. UnmarshalNBT is called with the
+// raw tag that is decoded into the value. RawMessage.Unmarshal may be used to decode the tag into another Go
+// value first.
+type Unmarshaler interface {
+	UnmarshalNBT(data RawMessage) error
+}
+
+// Types implementing encoding.TextMarshaler and not Marshaler are encoded as TAG_String. Similarly, types
+// implementing encoding.TextUnmarshaler and not Unmarshaler are decoded from a TAG_String.
+var (
+	marshalerType       = reflect.TypeFor[Marshaler]()
+	unmarshalerType     = reflect.TypeFor[Unmarshaler]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	rawMessageType      = reflect.TypeFor[RawMessage]()
+)
+
+// RawMessage is a single raw, undecoded NBT tag. A RawMessage may be used as a struct field, map value or
+// slice element to defer decoding of a subtree, for example when only some of the NBT of an item is known.
+// A RawMessage decoded is encoded back without changes. A zero RawMessage, which has no Type, holds no tag:
+// As a struct field it is omitted, while encoding it anywhere else returns an error.
+type RawMessage struct {
+	// Type is the type of the tag.
+	Type TagType
+	// Data is the payload of the tag, excluding its type and name.
+	Data []byte
+	// Encoding is the encoding the payload is encoded with. If it is different from the encoding used to
+	// encode the RawMessage, the payload is converted to that encoding.
+	Encoding Encoding
+}
+
+// Unmarshal decodes the tag held by the RawMessage into a pointer to a Go value passed, the same way
+// Unmarshal would.
+func (m RawMessage) Unmarshal(v any) error {
+	if m.Type == TagEnd {
+		return UnexpectedTagError{TagType: TagEnd}
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, len(m.Data)+3))
+	w := newOffsetWriter(buf)
+	_ = w.WriteByte(byte(m.Type))
+	if _, ok := m.Encoding.(networkBigEndian); !ok || m.Type != TagCompound {
+		if err := m.Encoding.WriteString(w, ""); err != nil {
+			return err
+		}
+	}
+	buf.Write(m.Data)
+	return UnmarshalEncoding(buf.Bytes(), v, m.Encoding)
+}
+
+// transcode writes the payload of the RawMessage to w using the encoding passed.
+func (m RawMessage) transcode(w *offsetWriter, encoding Encoding) error {
+	if m.Encoding == encoding {
+		if _, err := w.Write(m.Data); err != nil {
+			return FailedWriteError{Op: "WriteRawMessage", Off: w.off, Err: err}
+		}
+		return nil
+	}
+	r := &TokenReader{Encoding: m.Encoding, r: newOffsetReader(bytes.NewReader(m.Data))}
+	tw := &TokenWriter{Encoding: encoding, w: w}
+	return copyTag(r, tw, m.Type, "")
+}
+
+// copyTag copies the payload of a tag, of which the type and name were already read, from r to w, without
+// writing the type and name of the tag.
+func copyTag(r *TokenReader, w *TokenWriter, t TagType, name string) error {
+	tok, err := r.payload(t, name)
+	if err != nil {
+		return err
+	}
+	if err := w.payload(tok); err != nil {
+		return err
+	}
+	for r.Depth() > 0 {
+		tok, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return BufferOverrunError{Op: "RawMessage"}
+		} else if err != nil {
+			return err
+		}
+		if err := w.WriteToken(tok); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typeMethods records which of the marshaler interfaces a type T and *T implement.
+type typeMethods struct {
+	marshaler, textMarshaler       bool
+	ptrMarshaler, ptrTextMarshaler bool
+	// unmarshaler and textUnmarshaler are true if *T implements Unmarshaler or encoding.TextUnmarshaler.
+	unmarshaler, textUnmarshaler bool
+}
+
+// custom checks if values of the type control their own encoding.
+func (m typeMethods) custom() bool {
+	return m.marshaler || m.textMarshaler || m.ptrMarshaler || m.ptrTextMarshaler
+}
+
+// methodCache is a map[reflect.Type]typeMethods, so that the interfaces a type implements are only looked up
+// once. Looking them up for every value encoded or decoded is expensive.
+var methodCache sync.Map
+
+// methodsOf returns the typeMethods of the type passed.
+func methodsOf(t reflect.Type) typeMethods {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Struct:
+	default:
+		if t.PkgPath() == "" {
+			// Predeclared and unnamed types, such as int32 and []string, have no methods, and neither do
+			// pointers to them.
+			return typeMethods{}
+		}
+	}
+	if m, ok := methodCache.Load(t); ok {
+		return m.(typeMethods)
+	}
+	p := reflect.PointerTo(t)
+	m := typeMethods{
+		marshaler:        t.Implements(marshalerType),
+		textMarshaler:    t.Implements(textMarshalerType),
+		ptrMarshaler:     p.Implements(marshalerType),
+		ptrTextMarshaler: p.Implements(textMarshalerType),
+		unmarshaler:      p.Implements(unmarshalerType),
+		textUnmarshaler:  p.Implements(textUnmarshalerType),
+	}
+	methodCache.Store(t, m)
+	return m
+}
+
+// marshalerValue returns the value that should be encoded in place of val if val implements Marshaler or
+// encoding.TextMarshaler. If it implements neither, val is returned unchanged.
+func marshalerValue(val reflect.Value) (reflect.Value, error) {
+	for range maximumNestingDepth {
+		if val.Kind() == reflect.Interface || val.Kind() == reflect.Pointer {
+			if val.IsNil() {
+				return val, nil
+			}
+		}
+		t := val.Type()
+		m := methodsOf(t)
+		switch {
+		case m.marshaler:
+			v, err := val.Interface().(Marshaler).MarshalNBT()
+			if err != nil {
+				return val, err
+			}
+			val = reflect.ValueOf(v)
+			if !val.IsValid() {
+				return val, IncompatibleTypeError{Type: nil}
+			}
+			continue
+		case m.ptrMarshaler && val.CanAddr():
+			val = val.Addr()
+			continue
+		case t == rawMessageType:
+			return val, nil
+		case m.textMarshaler:
+			text, err := val.Interface().(encoding.TextMarshaler).MarshalText()
+			return reflect.ValueOf(string(text)), err
+		case m.ptrTextMarshaler && val.CanAddr():
+			val = val.Addr()
+			continue
+		case val.Kind() == reflect.Interface:
+			val = val.Elem()
+			continue
+		}
+		return val, nil
+	}
+	return val, MaximumDepthReachedError{}
+}
+
+// unmarshalerValue returns the Unmarshaler or encoding.TextUnmarshaler that val, or a pointer to val,
+// implements, if any. Nil pointers are allocated.
+func unmarshalerValue(val reflect.Value) (Unmarshaler, encoding.TextUnmarshaler) {
+	t := val.Type()
+	if val.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if m := methodsOf(t); !m.unmarshaler && !m.textUnmarshaler {
+		return nil, nil
+	}
+	if val.Kind() != reflect.Pointer {
+		if !val.CanAddr() {
+			return nil, nil
+		}
+		val = val.Addr()
+	}
+	if val.IsNil() {
+		if !val.CanSet() {
+			return nil, nil
+		}
+		val.Set(reflect.New(t))
+	}
+	if u, ok := val.Interface().(Unmarshaler); ok {
+		return u, nil
+	}
+	return nil, val.Interface().(encoding.TextUnmarshaler)
+}
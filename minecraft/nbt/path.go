@@ -0,0 +1,470 @@
+package nbt
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Path is a path to zero or more tags in a decoded NBT tree, such as a map[string]any filled by Unmarshal.
+// Paths are written in the NBT path syntax used by Minecraft commands:
+//
+//	Items                  the tag 'Items' of the root compound
+//	Items[0].tag           the tag 'tag' of the first element of the list 'Items'
+//	Items[-1]              the last element of the list 'Items'
+//	Items[]                all elements of the list 'Items'
+//	Items[{Count:1b}]      all elements of the list 'Items' that match the compound filter
+//	display{Name:"a"}      the tag 'display' if it matches the compound filter
+//	{id:"chest"}           the root compound if it matches the compound filter
+//	"key with spaces".x    a quoted key
+//
+// A compound matches a filter if it has every tag of the filter with an equal value. Nested compounds in
+// the filter are matched the same way, and lists in the filter match if every element of the filter list
+// matches an element of the list.
+type Path struct {
+	nodes []pathNode
+}
+
+// pathNodeKind is the kind of a single step of a Path.
+type pathNodeKind uint8
+
+const (
+	pathKey pathNodeKind = iota
+	pathIndex
+	pathAll
+	pathRoot
+)
+
+// pathNode is a single step of a Path.
+type pathNode struct {
+	kind   pathNodeKind
+	key    string
+	index  int
+	filter map[string]any
+}
+
+// ParsePath parses a Path from its string representation. An error is returned if the path is not valid.
+func ParsePath(s string) (Path, error) {
+	p := &snbtParser{data: s}
+	var path Path
+	if p.off < len(p.data) && p.data[p.off] == '{' {
+		filter, err := p.compound(0)
+		if err != nil {
+			return Path{}, err
+		}
+		path.nodes = append(path.nodes, pathNode{kind: pathRoot, filter: filter.(map[string]any)})
+	} else if err := path.parseKey(p); err != nil {
+		return Path{}, err
+	}
+	for p.off < len(p.data) {
+		switch {
+		case p.consume('.'):
+			if err := path.parseKey(p); err != nil {
+				return Path{}, err
+			}
+		case p.consume('['):
+			if err := path.parseBrackets(p); err != nil {
+				return Path{}, err
+			}
+		default:
+			return Path{}, p.errorf("unexpected character %q in path", p.data[p.off])
+		}
+	}
+	return path, nil
+}
+
+// MustParsePath parses a Path like ParsePath, but panics if the path is not valid. It is intended for paths
+// that are constants.
+func MustParsePath(s string) Path {
+	path, err := ParsePath(s)
+	if err != nil {
+		panic(err)
+	}
+	return path
+}
+
+// parseKey parses a compound key with an optional filter.
+func (path *Path) parseKey(p *snbtParser) error {
+	node := pathNode{kind: pathKey}
+	if p.off < len(p.data) && (p.data[p.off] == '"' || p.data[p.off] == '\'') {
+		key, err := p.quoted()
+		if err != nil {
+			return err
+		}
+		node.key = key
+	} else {
+		start := p.off
+		for p.off < len(p.data) && isUnquotedSNBTChar(p.data[p.off]) && p.data[p.off] != '.' {
+			p.off++
+		}
+		if node.key = p.data[start:p.off]; node.key == "" {
+			return p.errorf("expected key in path")
+		}
+	}
+	if p.off < len(p.data) && p.data[p.off] == '{' {
+		filter, err := p.compound(0)
+		if err != nil {
+			return err
+		}
+		node.filter = filter.(map[string]any)
+	}
+	path.nodes = append(path.nodes, node)
+	return nil
+}
+
+// parseBrackets parses the contents of a list index, starting after the '['.
+func (path *Path) parseBrackets(p *snbtParser) error {
+	node := pathNode{kind: pathAll}
+	switch {
+	case p.off < len(p.data) && p.data[p.off] == '{':
+		filter, err := p.compound(0)
+		if err != nil {
+			return err
+		}
+		node.filter = filter.(map[string]any)
+	case p.off < len(p.data) && p.data[p.off] != ']':
+		start := p.off
+		if p.data[p.off] == '-' {
+			p.off++
+		}
+		for p.off < len(p.data) && p.data[p.off] >= '0' && p.data[p.off] <= '9' {
+			p.off++
+		}
+		index, err := strconv.Atoi(p.data[start:p.off])
+		if err != nil {
+			return p.errorf("invalid list index %q", p.data[start:p.off])
+		}
+		node.kind, node.index = pathIndex, index
+	}
+	if !p.consume(']') {
+		return p.errorf("expected ']' in path")
+	}
+	path.nodes = append(path.nodes, node)
+	return nil
+}
+
+// String returns the string representation of the Path, which may be parsed again using ParsePath.
+func (path Path) String() string {
+	var b strings.Builder
+	for i, node := range path.nodes {
+		switch node.kind {
+		case pathKey:
+			if i != 0 {
+				b.WriteByte('.')
+			}
+			if node.key == "" || strings.IndexFunc(node.key, func(r rune) bool {
+				return !isUnquotedSNBTChar(byte(r)) || r > 0x7f || r == '.'
+			}) != -1 {
+				writeSNBTString(&b, node.key)
+			} else {
+				b.WriteString(node.key)
+			}
+			writePathFilter(&b, node.filter)
+		case pathRoot:
+			writePathFilter(&b, node.filter)
+		case pathIndex:
+			b.WriteString("[" + strconv.Itoa(node.index) + "]")
+		case pathAll:
+			b.WriteByte('[')
+			writePathFilter(&b, node.filter)
+			b.WriteByte(']')
+		}
+	}
+	return b.String()
+}
+
+// writePathFilter writes the compound filter of a path node, if it has one.
+func writePathFilter(b *strings.Builder, filter map[string]any) {
+	if filter == nil {
+		return
+	}
+	s, err := MarshalSNBT(filter)
+	if err != nil {
+		// Filters are always parsed from SNBT, so they can always be written as SNBT again.
+		panic(err)
+	}
+	b.WriteString(s)
+}
+
+// Key returns a copy of the Path extended with a compound key.
+func (path Path) Key(key string) Path {
+	return path.with(pathNode{kind: pathKey, key: key})
+}
+
+// Index returns a copy of the Path extended with a list index. Negative indices count from the end of the
+// list.
+func (path Path) Index(index int) Path {
+	return path.with(pathNode{kind: pathIndex, index: index})
+}
+
+// with returns a copy of the Path with the node passed appended.
+func (path Path) with(node pathNode) Path {
+	return Path{nodes: append(path.nodes[:len(path.nodes):len(path.nodes)], node)}
+}
+
+// pathTarget is a tag matched by a Path, together with a function to replace it in its parent.
+type pathTarget struct {
+	value any
+	set   func(v any) error
+	// attach, if not nil, adds the value of the target, which is a compound created by Path.Set, to the
+	// tree, together with any created compounds it is in. It is only called once a tag in it is set, so
+	// that the tree remains unchanged if a path cannot be set.
+	attach func()
+}
+
+// Get returns the values of all tags in the tree that the Path matches. The values are returned as they are
+// stored in the tree, so compounds and lists returned may be modified to modify the tree. If the Path
+// matches no tags, Get returns nil.
+func (path Path) Get(tree any) []any {
+	targets, _ := path.resolve(tree, path.nodes, false)
+	values := make([]any, 0, len(targets))
+	for _, target := range targets {
+		values = append(values, target.value)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// First returns the value of the first tag in the tree that the Path matches, and false if it matches none.
+func (path Path) First(tree any) (any, bool) {
+	values := path.Get(tree)
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values[0], true
+}
+
+// Set sets all tags in the tree that the Path matches to v and returns the number of tags set. If the last
+// step of the Path is a compound key that does not exist yet, it is created, as are compounds for any
+// missing compound keys before it. If a later step of the Path can never match such a created compound,
+// such as a list index, an error is returned and the tree is left unchanged. Elements of lists can only be
+// set to values with the same tag type as the other elements of the list. The root of the tree cannot be
+// set.
+func (path Path) Set(tree any, v any) (int, error) {
+	if len(path.nodes) == 0 || path.nodes[len(path.nodes)-1].kind == pathRoot {
+		return 0, fmt.Errorf("nbt: cannot set root of tree")
+	}
+	targets, err := path.resolve(tree, path.nodes, true)
+	if err != nil {
+		return 0, err
+	}
+	for i, target := range targets {
+		if err := target.set(v); err != nil {
+			return i, err
+		}
+	}
+	return len(targets), nil
+}
+
+// Delete removes all tags in the tree that the Path matches and returns the number of tags removed. The
+// root of the tree cannot be removed.
+func (path Path) Delete(tree any) (int, error) {
+	if len(path.nodes) == 0 || path.nodes[len(path.nodes)-1].kind == pathRoot {
+		return 0, fmt.Errorf("nbt: cannot delete root of tree")
+	}
+	last := path.nodes[len(path.nodes)-1]
+	parents, _ := path.resolve(tree, path.nodes[:len(path.nodes)-1], false)
+	n := 0
+	for _, parent := range parents {
+		switch last.kind {
+		case pathKey:
+			m, ok := parent.value.(map[string]any)
+			if !ok {
+				continue
+			}
+			if v, ok := m[last.key]; ok && matchesFilter(v, last.filter) {
+				delete(m, last.key)
+				n++
+			}
+		case pathIndex, pathAll:
+			list := reflect.ValueOf(parent.value)
+			if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+				continue
+			}
+			keep := make([]int, 0, list.Len())
+			for i := 0; i < list.Len(); i++ {
+				var remove bool
+				if last.kind == pathIndex {
+					remove = i == listIndex(last.index, list.Len())
+				} else {
+					remove = matchesFilter(list.Index(i).Interface(), last.filter)
+				}
+				if !remove {
+					keep = append(keep, i)
+				}
+			}
+			if len(keep) == list.Len() {
+				continue
+			}
+			newList := newListValue(list, len(keep))
+			for i, index := range keep {
+				newList.Index(i).Set(list.Index(index))
+			}
+			if err := parent.set(newList.Interface()); err != nil {
+				return n, err
+			}
+			n += list.Len() - len(keep)
+		}
+	}
+	return n, nil
+}
+
+// resolve returns the targets that the nodes passed match in the tree. If create is true, missing compound
+// keys are created, but they are only added to the tree once a target in them is set. An error is returned
+// if create is true and no targets were found because a step of the path cannot match a created compound.
+func (path Path) resolve(tree any, nodes []pathNode, create bool) ([]pathTarget, error) {
+	targets := []pathTarget{{value: tree, set: func(any) error { return fmt.Errorf("nbt: cannot set root of tree") }}}
+	for i, node := range nodes {
+		last := i == len(nodes)-1
+		next := make([]pathTarget, 0, len(targets))
+		for _, target := range targets {
+			switch node.kind {
+			case pathRoot:
+				if matchesFilter(target.value, node.filter) {
+					next = append(next, target)
+				}
+			case pathKey:
+				m, ok := target.value.(map[string]any)
+				if !ok {
+					continue
+				}
+				key, attach := node.key, target.attach
+				set := func(v any) error {
+					if attach != nil {
+						attach()
+					}
+					m[key] = v
+					return nil
+				}
+				v, ok := m[key]
+				if !ok && create && node.filter == nil {
+					if last {
+						next = append(next, pathTarget{value: v, set: set})
+						continue
+					}
+					// Only compounds can be created implicitly: The tag after this one is either a key in it
+					// or a list index, which can never match an empty compound.
+					created := map[string]any{}
+					next = append(next, pathTarget{value: created, set: set, attach: func() { _ = set(created) }})
+					continue
+				}
+				if ok && matchesFilter(v, node.filter) {
+					next = append(next, pathTarget{value: v, set: set})
+				}
+			case pathIndex, pathAll:
+				list := reflect.ValueOf(target.value)
+				if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+					continue
+				}
+				// The elements share the target of the list, so that setting multiple elements of it each
+				// modify the latest copy of the list.
+				shared := &pathTarget{value: target.value, set: target.set}
+				for j := 0; j < list.Len(); j++ {
+					if node.kind == pathIndex && j != listIndex(node.index, list.Len()) {
+						continue
+					}
+					elem := list.Index(j).Interface()
+					if node.kind == pathAll && !matchesFilter(elem, node.filter) {
+						continue
+					}
+					next = append(next, pathTarget{value: elem, set: listSetter(shared, j)})
+				}
+			}
+		}
+		if len(next) == 0 && create && slices.ContainsFunc(targets, func(target pathTarget) bool { return target.attach != nil }) {
+			return nil, fmt.Errorf("nbt: cannot set %v: %v does not exist", path, Path{nodes: nodes[:i]})
+		}
+		targets = next
+	}
+	return targets, nil
+}
+
+// listSetter returns a function that sets the element at index of the list held by target. The list is
+// copied, so that arrays, which are stored by value, are updated in the tree too.
+func listSetter(target *pathTarget, index int) func(v any) error {
+	return func(v any) error {
+		list := reflect.ValueOf(target.value)
+		val := reflect.ValueOf(v)
+		elemType := list.Type().Elem()
+		if !val.IsValid() || !val.Type().AssignableTo(elemType) {
+			return fmt.Errorf("nbt: cannot set element of %v to %T", list.Type(), v)
+		}
+		if elemType.Kind() == reflect.Interface {
+			for i := 0; i < list.Len(); i++ {
+				if i != index && tagFromType(list.Index(i).Elem().Type()) != tagFromType(val.Type()) {
+					return fmt.Errorf("nbt: cannot set element of list of %v to %T", tagFromType(list.Index(i).Elem().Type()), v)
+				}
+			}
+		}
+		newList := newListValue(list, list.Len())
+		reflect.Copy(newList, list)
+		newList.Index(index).Set(val)
+		if err := target.set(newList.Interface()); err != nil {
+			return err
+		}
+		target.value = newList.Interface()
+		return nil
+	}
+}
+
+// newListValue returns a new slice or array of n elements of the same type as list.
+func newListValue(list reflect.Value, n int) reflect.Value {
+	if list.Kind() == reflect.Array {
+		return reflect.New(reflect.ArrayOf(n, list.Type().Elem())).Elem()
+	}
+	return reflect.MakeSlice(list.Type(), n, n)
+}
+
+// listIndex converts a possibly negative index to an index into a list with length n.
+func listIndex(index, n int) int {
+	if index < 0 {
+		return n + index
+	}
+	return index
+}
+
+// matchesFilter checks if v matches the compound filter passed. A nil filter matches every value.
+func matchesFilter(v any, filter map[string]any) bool {
+	if filter == nil {
+		return true
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return false
+	}
+	for key, want := range filter {
+		got, ok := m[key]
+		if !ok || !matchesValue(got, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesValue checks if the value got matches the value want of a filter.
+func matchesValue(got, want any) bool {
+	switch want := want.(type) {
+	case map[string]any:
+		return matchesFilter(got, want)
+	case []any:
+		list := reflect.ValueOf(got)
+		if list.Kind() != reflect.Slice {
+			return false
+		}
+		for _, w := range want {
+			found := false
+			for i := 0; i < list.Len() && !found; i++ {
+				found = matchesValue(list.Index(i).Interface(), w)
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(got, want)
+}
@@ -10,6 +10,8 @@ type offsetReader struct {
 	io.Reader
 	off int64
 	buf [8]byte
+	// limit is the offset up to which data may be read, as checked by reserve. Zero means no limit.
+	limit int64
 
 	// ReadByte is a function provided by offsetReader if the io.Reader does not implement io.ByteReader.
 	ReadByte func() (byte, error)
@@ -57,3 +59,12 @@ func (b *offsetReader) Read(p []byte) (n int, err error) {
 	b.off += int64(n)
 	return
 }
+
+// reserve checks if n more bytes may be read without reading past the limit of the offsetReader. It is
+// called with the length of a payload before the payload is allocated and read.
+func (b *offsetReader) reserve(n int64) error {
+	if b.limit > 0 && b.off+n > b.limit {
+		return LimitExceededError{Off: b.off, Limit: "size", Max: b.limit}
+	}
+	return nil
+}
@@ -0,0 +1,478 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MarshalSNBT encodes an object to its stringified NBT (SNBT) representation, as used in Minecraft commands.
+// The Go values passed are converted to tags the same way Marshal does. Numeric tags other than TAG_Int are
+// written with a type suffix, such as 1b, 2s, 3L, 4.5f and 6.5d, and arrays are written with a type prefix,
+// such as [B;1b,2b] and [I;1,2]. Strings are always quoted, and compound keys only if they contain characters
+// other than letters, digits, '_', '-', '.' and '+'. Keys of compounds are written in the order they appear
+// in the NBT, which is random for maps.
+//
+// An error is returned for values that cannot be encoded as NBT, or for NaN and infinite float values, as
+// SNBT has no representation for them.
+func MarshalSNBT(v any) (string, error) {
+	data, err := MarshalEncoding(v, LittleEndian)
+	if err != nil {
+		return "", err
+	}
+	r := NewTokenReader(bytes.NewReader(data), LittleEndian)
+	var b strings.Builder
+	for {
+		tok, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return b.String(), nil
+		} else if err != nil {
+			return "", err
+		}
+		if err := writeSNBTToken(&b, r, tok); err != nil {
+			return "", err
+		}
+	}
+}
+
+// writeSNBTToken writes a Token read from the TokenReader passed to b in SNBT form.
+func writeSNBTToken(b *strings.Builder, r *TokenReader, tok Token) error {
+	switch tok.Kind {
+	case TokenEnd:
+		if tok.Type == TagCompound {
+			b.WriteByte('}')
+		} else {
+			b.WriteByte(']')
+		}
+		return nil
+	case TokenCompound:
+		b.WriteByte('{')
+		for first := true; ; first = false {
+			nested, err := r.Next()
+			if err != nil {
+				return err
+			}
+			if nested.Kind != TokenEnd {
+				if !first {
+					b.WriteByte(',')
+				}
+				writeSNBTKey(b, nested.Name)
+				b.WriteByte(':')
+			}
+			if err := writeSNBTToken(b, r, nested); err != nil {
+				return err
+			}
+			if nested.Kind == TokenEnd {
+				return nil
+			}
+		}
+	case TokenList:
+		b.WriteByte('[')
+		for i := 0; i <= tok.Len; i++ {
+			nested, err := r.Next()
+			if err != nil {
+				return err
+			}
+			if i != 0 && nested.Kind != TokenEnd {
+				b.WriteByte(',')
+			}
+			if err := writeSNBTToken(b, r, nested); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	switch v := tok.Value.(type) {
+	case byte:
+		b.WriteString(strconv.Itoa(int(int8(v))) + "b")
+	case int16:
+		b.WriteString(strconv.Itoa(int(v)) + "s")
+	case int32:
+		b.WriteString(strconv.Itoa(int(v)))
+	case int64:
+		b.WriteString(strconv.FormatInt(v, 10) + "L")
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return IncompatibleTypeError{ValueName: tok.Name, Type: reflect.TypeOf(v)}
+		}
+		b.WriteString(strconv.FormatFloat(float64(v), 'g', -1, 32) + "f")
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return IncompatibleTypeError{ValueName: tok.Name, Type: reflect.TypeOf(v)}
+		}
+		b.WriteString(strconv.FormatFloat(v, 'g', -1, 64) + "d")
+	case string:
+		writeSNBTString(b, v)
+	case []byte:
+		b.WriteString("[B;")
+		for i, x := range v {
+			if i != 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.Itoa(int(int8(x))) + "b")
+		}
+		b.WriteByte(']')
+	case []int32:
+		b.WriteString("[I;")
+		for i, x := range v {
+			if i != 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.Itoa(int(x)))
+		}
+		b.WriteByte(']')
+	case []int64:
+		b.WriteString("[L;")
+		for i, x := range v {
+			if i != 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.FormatInt(x, 10) + "L")
+		}
+		b.WriteByte(']')
+	}
+	return nil
+}
+
+// writeSNBTKey writes the key of a tag in a compound, quoting it only if needed.
+func writeSNBTKey(b *strings.Builder, key string) {
+	if key == "" || strings.IndexFunc(key, func(r rune) bool { return !isUnquotedSNBTChar(byte(r)) || r > 0x7f }) != -1 {
+		writeSNBTString(b, key)
+		return
+	}
+	b.WriteString(key)
+}
+
+// writeSNBTString writes a quoted string. Double quotes are used, unless the string contains double quotes
+// but no single quotes.
+func writeSNBTString(b *strings.Builder, s string) {
+	quote := byte('"')
+	if strings.ContainsRune(s, '"') && !strings.ContainsRune(s, '\'') {
+		quote = '\''
+	}
+	b.WriteByte(quote)
+	for i := 0; i < len(s); i++ {
+		if s[i] == quote || s[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	b.WriteByte(quote)
+}
+
+// isUnquotedSNBTChar checks if c may be part of an unquoted SNBT string.
+func isUnquotedSNBTChar(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c == '-' || c == '.' || c == '+'
+}
+
+// UnmarshalSNBT decodes stringified NBT (SNBT) into a pointer to a Go value passed. The value is decoded
+// the same way Unmarshal decodes NBT, so it may be a struct, a map[string]any or any other type listed
+// for Unmarshal.
+//
+// UnmarshalSNBT accepts the SNBT syntax used in Minecraft commands: Compounds such as {a:1b,"b c":"d"},
+// lists such as [1,2,3], typed arrays such as [B;1b,2b], [I;1,2] and [L;1L,2L], strings quoted with single or
+// double quotes, and unquoted strings. Numbers may carry a case-insensitive type suffix: b for TAG_Byte, s
+// for TAG_Short, l for TAG_Long, f for TAG_Float and d for TAG_Double. Numbers without a suffix are TAG_Int
+// if they are integers and TAG_Double otherwise. The unquoted values true and false are TAG_Byte.
+func UnmarshalSNBT(data string, v any) error {
+	p := &snbtParser{data: data}
+	p.skipSpace()
+	tree, err := p.value(0)
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	if p.off != len(p.data) {
+		return p.errorf("unexpected trailing data")
+	}
+	b, err := MarshalEncoding(tree, LittleEndian)
+	if err != nil {
+		return err
+	}
+	return UnmarshalEncoding(b, v, LittleEndian)
+}
+
+// snbtParser parses SNBT into the Go values that the NBT of the SNBT would be decoded into as any.
+type snbtParser struct {
+	data string
+	off  int
+}
+
+// value parses any SNBT value at the current offset.
+func (p *snbtParser) value(depth int) (any, error) {
+	if depth >= maximumNestingDepth {
+		return nil, MaximumDepthReachedError{}
+	}
+	if p.off >= len(p.data) {
+		return nil, p.errorf("expected value")
+	}
+	switch c := p.data[p.off]; {
+	case c == '{':
+		return p.compound(depth)
+	case c == '[':
+		return p.list(depth)
+	case c == '"' || c == '\'':
+		return p.quoted()
+	}
+	s := p.unquoted()
+	if s == "" {
+		return nil, p.errorf("unexpected character %q", p.data[p.off])
+	}
+	return parseSNBTScalar(s), nil
+}
+
+// compound parses a TAG_Compound into a map[string]any.
+func (p *snbtParser) compound(depth int) (any, error) {
+	p.off++
+	m := make(map[string]any)
+	p.skipSpace()
+	if p.consume('}') {
+		return m, nil
+	}
+	for {
+		p.skipSpace()
+		var key string
+		if p.off < len(p.data) && (p.data[p.off] == '"' || p.data[p.off] == '\'') {
+			k, err := p.quoted()
+			if err != nil {
+				return nil, err
+			}
+			key = k
+		} else if key = p.unquoted(); key == "" {
+			return nil, p.errorf("expected compound key")
+		}
+		p.skipSpace()
+		if !p.consume(':') {
+			return nil, p.errorf("expected ':' after compound key %q", key)
+		}
+		p.skipSpace()
+		v, err := p.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := m[key]; ok {
+			return nil, p.errorf("duplicate compound key %q", key)
+		}
+		m[key] = v
+		p.skipSpace()
+		if p.consume('}') {
+			return m, nil
+		}
+		if !p.consume(',') {
+			return nil, p.errorf("expected ',' or '}' in compound")
+		}
+	}
+}
+
+// list parses a TAG_List into a []any, or a TAG_ByteArray, TAG_IntArray or TAG_LongArray into an array.
+func (p *snbtParser) list(depth int) (any, error) {
+	p.off++
+	p.skipSpace()
+	if p.off+1 < len(p.data) && p.data[p.off+1] == ';' {
+		return p.array()
+	}
+	var (
+		l        = make([]any, 0)
+		elemType TagType
+	)
+	if p.consume(']') {
+		return l, nil
+	}
+	for {
+		p.skipSpace()
+		v, err := p.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		t := tagFromType(reflect.TypeOf(v))
+		if len(l) == 0 {
+			elemType = t
+		} else if t != elemType {
+			return nil, p.errorf("list element of type %v in list of %v", t, elemType)
+		}
+		l = append(l, v)
+		p.skipSpace()
+		if p.consume(']') {
+			return l, nil
+		}
+		if !p.consume(',') {
+			return nil, p.errorf("expected ',' or ']' in list")
+		}
+	}
+}
+
+// array parses the typed array at the current offset, starting after the '['.
+func (p *snbtParser) array() (any, error) {
+	prefix := p.data[p.off]
+	p.off += 2
+
+	var values []int64
+	p.skipSpace()
+	for !p.consume(']') {
+		if len(values) != 0 {
+			if !p.consume(',') {
+				return nil, p.errorf("expected ',' or ']' in array")
+			}
+			p.skipSpace()
+		}
+		s := p.unquoted()
+		var (
+			x  int64
+			ok bool
+		)
+		switch n := parseSNBTScalar(s).(type) {
+		case byte:
+			x, ok = int64(int8(n)), prefix == 'B'
+		case int32:
+			x, ok = int64(n), prefix == 'I' || (prefix == 'B' && n >= math.MinInt8 && n <= math.MaxInt8)
+		case int64:
+			x, ok = n, prefix == 'L'
+		}
+		if !ok {
+			return nil, p.errorf("invalid element %q in %c array", s, prefix)
+		}
+		values = append(values, x)
+		p.skipSpace()
+	}
+
+	var elem reflect.Type
+	switch prefix {
+	case 'B':
+		elem = byteType
+	case 'I':
+		elem = int32Type
+	case 'L':
+		elem = int64Type
+	default:
+		return nil, p.errorf("unknown array type %c", prefix)
+	}
+	arr := reflect.New(reflect.ArrayOf(len(values), elem)).Elem()
+	for i, x := range values {
+		if prefix == 'B' {
+			arr.Index(i).SetUint(uint64(byte(x)))
+			continue
+		}
+		arr.Index(i).SetInt(x)
+	}
+	return arr.Interface(), nil
+}
+
+// quoted parses a string quoted with single or double quotes.
+func (p *snbtParser) quoted() (string, error) {
+	quote := p.data[p.off]
+	p.off++
+	var b strings.Builder
+	for p.off < len(p.data) {
+		c := p.data[p.off]
+		p.off++
+		switch c {
+		case quote:
+			return b.String(), nil
+		case '\\':
+			if p.off >= len(p.data) {
+				return "", p.errorf("unterminated escape sequence")
+			}
+			c = p.data[p.off]
+			p.off++
+			switch c {
+			case '\\', '"', '\'':
+			case 'n':
+				c = '\n'
+			case 't':
+				c = '\t'
+			default:
+				return "", p.errorf("invalid escape sequence \\%c", c)
+			}
+		}
+		b.WriteByte(c)
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// unquoted parses an unquoted string, which may be empty if the character at the current offset is not
+// valid in one.
+func (p *snbtParser) unquoted() string {
+	start := p.off
+	for p.off < len(p.data) && isUnquotedSNBTChar(p.data[p.off]) {
+		p.off++
+	}
+	return p.data[start:p.off]
+}
+
+// consume skips c if it is the character at the current offset.
+func (p *snbtParser) consume(c byte) bool {
+	if p.off < len(p.data) && p.data[p.off] == c {
+		p.off++
+		return true
+	}
+	return false
+}
+
+// skipSpace skips whitespace at the current offset.
+func (p *snbtParser) skipSpace() {
+	for p.off < len(p.data) && strings.IndexByte(" \t\r\n", p.data[p.off]) != -1 {
+		p.off++
+	}
+}
+
+// errorf returns a SyntaxError at the current offset.
+func (p *snbtParser) errorf(format string, a ...any) error {
+	return SyntaxError{Off: p.off, Msg: fmt.Sprintf(format, a...)}
+}
+
+// parseSNBTScalar converts an unquoted SNBT value to a number or boolean if it has the form of one, or keeps
+// it as a string otherwise.
+func parseSNBTScalar(s string) any {
+	switch strings.ToLower(s) {
+	case "true":
+		return byte(1)
+	case "false":
+		return byte(0)
+	}
+	if len(s) > 1 {
+		num := s[:len(s)-1]
+		switch s[len(s)-1] {
+		case 'b', 'B':
+			// Bytes are signed in SNBT, as written by the encoder: Values outside -128..127 remain strings.
+			if v, err := strconv.ParseInt(num, 10, 8); err == nil {
+				return byte(v)
+			}
+		case 's', 'S':
+			if v, err := strconv.ParseInt(num, 10, 16); err == nil {
+				return int16(v)
+			}
+		case 'l', 'L':
+			if v, err := strconv.ParseInt(num, 10, 64); err == nil {
+				return v
+			}
+		case 'f', 'F':
+			if v, err := strconv.ParseFloat(num, 32); err == nil && isSNBTFloat(num) {
+				return float32(v)
+			}
+		case 'd', 'D':
+			if v, err := strconv.ParseFloat(num, 64); err == nil && isSNBTFloat(num) {
+				return v
+			}
+		}
+	}
+	if v, err := strconv.ParseInt(s, 10, 32); err == nil {
+		return int32(v)
+	}
+	if strings.ContainsAny(s, ".eE") && isSNBTFloat(s) {
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v
+		}
+	}
+	return s
+}
+
+// isSNBTFloat checks if s only holds characters of a decimal floating point number, so that values such as
+// "Inf" and "NaN", which strconv.ParseFloat accepts, remain strings.
+func isSNBTFloat(s string) bool {
+	return strings.Trim(s, "0123456789.eE+-") == ""
+}
@@ -1,68 +1,71 @@
 package nbt
 
 import (
+	"fmt"
 	"math"
 	"reflect"
 )
 
+// The NBT tag types. The Go types each of them is encoded from and decoded into are listed in the package
+// documentation.
 const (
-	tagEnd tagType = iota
-	tagByte
-	tagInt16
-	tagInt32
-	tagInt64
-	tagFloat32
-	tagFloat64
-	tagByteArray
-	tagString
-	tagSlice
-	tagStruct
-	tagInt32Array
-	tagInt64Array
+	TagEnd TagType = iota
+	TagByte
+	TagShort
+	TagInt
+	TagLong
+	TagFloat
+	TagDouble
+	TagByteArray
+	TagString
+	TagList
+	TagCompound
+	TagIntArray
+	TagLongArray
 )
 
-// tagType represents the type of NBT tag.
-type tagType byte
+// TagType represents the type of an NBT tag, as written in front of named tags and in the header of TAG_List.
+type TagType byte
 
-// String converts a tagType to its string representation. This looks like `TAG_` + `<tag type>`, such as `TAG_Byte`.
-func (t tagType) String() string {
+// String converts a TagType to its string representation. This looks like `TAG_` + `<tag type>`, such as `TAG_Byte`.
+func (t TagType) String() string {
 	switch t {
-	case tagEnd:
+	case TagEnd:
 		return "TAG_End"
-	case tagByte:
+	case TagByte:
 		return "TAG_Byte"
-	case tagInt16:
+	case TagShort:
 		return "TAG_Short"
-	case tagInt32:
+	case TagInt:
 		return "TAG_Int"
-	case tagInt64:
+	case TagLong:
 		return "TAG_Long"
-	case tagFloat32:
+	case TagFloat:
 		return "TAG_Float"
-	case tagFloat64:
+	case TagDouble:
 		return "TAG_Double"
-	case tagByteArray:
+	case TagByteArray:
 		return "TAG_ByteArray"
-	case tagString:
+	case TagString:
 		return "TAG_String"
-	case tagSlice:
+	case TagList:
 		return "TAG_List"
-	case tagStruct:
+	case TagCompound:
 		return "TAG_Compound"
-	case tagInt32Array:
+	case TagIntArray:
 		return "TAG_IntArray"
-	case tagInt64Array:
+	case TagLongArray:
 		return "TAG_LongArray"
 	default:
-		panic("unknown tag")
+		return fmt.Sprintf("TAG_Unknown(%v)", byte(t))
 	}
 }
 
-// IsValid checks if the tagType is valid/known.
-func (t tagType) IsValid() bool {
+// IsValid checks if the TagType is valid/known.
+func (t TagType) IsValid() bool {
 	switch t {
-	case tagEnd, tagByte, tagInt16, tagInt32, tagInt64, tagFloat32, tagFloat64, tagByteArray, tagString,
-		tagSlice, tagStruct, tagInt32Array, tagInt64Array:
+	case TagEnd, TagByte, TagShort, TagInt, TagLong, TagFloat, TagDouble, TagByteArray, TagString,
+		TagList, TagCompound, TagIntArray, TagLongArray:
 		return true
 	default:
 		return false
@@ -71,38 +74,38 @@ func (t tagType) IsValid() bool {
 
 // tagFromType matches a reflect.Type with a tag type that can hold its value. If none is found, math.MaxUint8
 // is returned.
-func tagFromType(p reflect.Type) tagType {
+func tagFromType(p reflect.Type) TagType {
 	if p == nil {
-		return tagEnd
+		return TagEnd
 	}
 	switch p.Kind() {
 	case reflect.Uint8, reflect.Bool:
-		return tagByte
+		return TagByte
 	case reflect.Int16:
-		return tagInt16
+		return TagShort
 	case reflect.Int32:
-		return tagInt32
+		return TagInt
 	case reflect.Int64:
-		return tagInt64
+		return TagLong
 	case reflect.Float32:
-		return tagFloat32
+		return TagFloat
 	case reflect.Float64:
-		return tagFloat64
+		return TagDouble
 	case reflect.Array:
 		switch p.Elem().Kind() {
 		case reflect.Uint8:
-			return tagByteArray
+			return TagByteArray
 		case reflect.Int32:
-			return tagInt32Array
+			return TagIntArray
 		case reflect.Int64:
-			return tagInt64Array
+			return TagLongArray
 		}
 	case reflect.String:
-		return tagString
+		return TagString
 	case reflect.Slice:
-		return tagSlice
+		return TagList
 	case reflect.Struct, reflect.Map:
-		return tagStruct
+		return TagCompound
 	}
 	return math.MaxUint8
 }
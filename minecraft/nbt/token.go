@@ -0,0 +1,102 @@
+package nbt
+
+import (
+	"fmt"
+)
+
+// TokenKind is the kind of a Token read by a TokenReader or written by a TokenWriter.
+type TokenKind uint8
+
+const (
+	// TokenValue is a tag holding a single value, which is any tag other than TAG_Compound and TAG_List.
+	TokenValue TokenKind = iota
+	// TokenCompound is the start of a TAG_Compound. It is followed by the tokens of the tags in the compound
+	// and closed by a TokenEnd.
+	TokenCompound
+	// TokenList is the start of a TAG_List, holding the list header. It is followed by exactly Token.Len
+	// tokens of the type Token.ElemType and closed by a TokenEnd.
+	TokenList
+	// TokenEnd is the end of the TAG_Compound or TAG_List last started.
+	TokenEnd
+)
+
+// String ...
+func (k TokenKind) String() string {
+	switch k {
+	case TokenValue:
+		return "Value"
+	case TokenCompound:
+		return "Compound"
+	case TokenList:
+		return "List"
+	case TokenEnd:
+		return "End"
+	default:
+		return fmt.Sprintf("TokenKind(%v)", byte(k))
+	}
+}
+
+// Token is a single element of a stream of NBT. Tags that hold a single value are a single Token, while
+// TAG_Compound and TAG_List are represented by a start Token, the tokens of their contents, and a Token with
+// the kind TokenEnd.
+type Token struct {
+	// Kind is the kind of the token.
+	Kind TokenKind
+	// Type is the type of the tag the token represents. For a TokenEnd, it is the type of the tag that is
+	// ended, TagCompound or TagList.
+	Type TagType
+	// Name is the name of the tag. It is only set for tags in a TAG_Compound and for the root tag.
+	Name string
+	// Value is the value of a TokenValue. Its Go type depends on Type:
+	//
+	//	TAG_Byte: byte
+	//	TAG_Short: int16
+	//	TAG_Int: int32
+	//	TAG_Long: int64
+	//	TAG_Float: float32
+	//	TAG_Double: float64
+	//	TAG_ByteArray: []byte
+	//	TAG_String: string
+	//	TAG_IntArray: []int32
+	//	TAG_LongArray: []int64
+	Value any
+	// ElemType is the type of the elements of a TokenList.
+	ElemType TagType
+	// Len is the number of elements of a TokenList.
+	Len int
+}
+
+// valueType returns the TagType a Go value held by a TokenValue corresponds to, or false if the value is
+// not of one of the types listed for Token.Value.
+func valueType(v any) (TagType, bool) {
+	switch v.(type) {
+	case byte:
+		return TagByte, true
+	case int16:
+		return TagShort, true
+	case int32:
+		return TagInt, true
+	case int64:
+		return TagLong, true
+	case float32:
+		return TagFloat, true
+	case float64:
+		return TagDouble, true
+	case []byte:
+		return TagByteArray, true
+	case string:
+		return TagString, true
+	case []int32:
+		return TagIntArray, true
+	case []int64:
+		return TagLongArray, true
+	}
+	return 0, false
+}
+
+// tokenFrame is a TAG_Compound or TAG_List that a TokenReader or TokenWriter is currently in.
+type tokenFrame struct {
+	t         TagType
+	elemType  TagType
+	remaining int
+}
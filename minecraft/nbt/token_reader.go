@@ -0,0 +1,277 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// maxArrayPrealloc is the maximum number of elements allocated for an array before its elements are read.
+// Larger arrays grow as their elements are read, so that a bogus length prefix cannot cause a huge
+// allocation.
+const maxArrayPrealloc = 1 << 16
+
+// TokenReader reads NBT from an input stream one Token at a time, without decoding it into Go values. It
+// allows scanning, filtering or transforming large NBT trees without holding them in memory.
+type TokenReader struct {
+	// Encoding is the variant used to read the NBT.
+	Encoding Encoding
+	// MaxDepth is the maximum number of nested compound and list tags. Zero means the default maximum depth
+	// of 512, which is also the highest depth allowed.
+	MaxDepth int
+	// MaxBytes is the maximum number of bytes read from the input stream. It is checked against the length
+	// of strings and arrays before they are read. Zero means no limit, although the NetworkLittleEndian
+	// encoding always limits the input to 4 MiB.
+	MaxBytes int64
+	// MaxLen is the maximum number of elements of a single TAG_List, TAG_ByteArray, TAG_IntArray or
+	// TAG_LongArray. Zero means no limit.
+	MaxLen int
+
+	r     *offsetReader
+	stack []tokenFrame
+}
+
+// NewTokenReader returns a TokenReader reading NBT from r using the encoding passed.
+func NewTokenReader(r io.Reader, encoding Encoding) *TokenReader {
+	return &TokenReader{Encoding: encoding, r: newOffsetReader(r)}
+}
+
+// Depth returns the number of compound and list tags the TokenReader is currently in.
+func (r *TokenReader) Depth() int {
+	return len(r.stack)
+}
+
+// Offset returns the number of bytes read so far.
+func (r *TokenReader) Offset() int64 {
+	return r.r.off
+}
+
+// Next reads the next Token from the input stream. Once a root tag is fully read, Next continues with the
+// next root tag in the stream. io.EOF is returned if the input stream ends before the start of a new root
+// tag.
+func (r *TokenReader) Next() (Token, error) {
+	if err := r.checkOffset(); err != nil {
+		return Token{}, err
+	}
+	r.r.limit = r.MaxBytes
+	if len(r.stack) == 0 {
+		return r.root()
+	}
+	top := &r.stack[len(r.stack)-1]
+	if top.t == TagList {
+		if top.remaining == 0 {
+			r.stack = r.stack[:len(r.stack)-1]
+			return Token{Kind: TokenEnd, Type: TagList}, nil
+		}
+		top.remaining--
+		return r.payload(top.elemType, "")
+	}
+	b, err := r.r.ReadByte()
+	if err != nil {
+		return Token{}, BufferOverrunError{Op: "ReadTag"}
+	}
+	t := TagType(b)
+	if !t.IsValid() {
+		return Token{}, UnknownTagError{Off: r.r.off, Op: "Compound", TagType: t}
+	}
+	if t == TagEnd {
+		r.stack = r.stack[:len(r.stack)-1]
+		return Token{Kind: TokenEnd, Type: TagCompound}, nil
+	}
+	name, err := r.Encoding.String(r.r)
+	if err != nil {
+		return Token{}, err
+	}
+	return r.payload(t, name)
+}
+
+// Skip skips the remaining tokens of the compound or list tag the TokenReader is currently in, including
+// its TokenEnd. It is typically called directly after Next returns a TokenCompound or TokenList that is not
+// of interest.
+func (r *TokenReader) Skip() error {
+	depth := len(r.stack)
+	if depth == 0 {
+		return nil
+	}
+	for len(r.stack) >= depth {
+		if _, err := r.Next(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return BufferOverrunError{Op: "Skip"}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// root reads the type and name of a root tag.
+func (r *TokenReader) root() (Token, error) {
+	b, err := r.r.ReadByte()
+	if errors.Is(err, io.EOF) {
+		return Token{}, io.EOF
+	} else if err != nil {
+		return Token{}, BufferOverrunError{Op: "ReadTag"}
+	}
+	t := TagType(b)
+	if !t.IsValid() {
+		return Token{}, UnknownTagError{Off: r.r.off, Op: "Root", TagType: t}
+	}
+	if t == TagEnd {
+		return Token{}, UnexpectedTagError{Off: r.r.off, TagType: t}
+	}
+	if _, ok := r.Encoding.(networkBigEndian); ok && t == TagCompound {
+		// As of Minecraft Java 1.20.2, the name of the root compound tag is not written over the network.
+		return r.payload(t, "")
+	}
+	name, err := r.Encoding.String(r.r)
+	if err != nil {
+		return Token{}, err
+	}
+	return r.payload(t, name)
+}
+
+// payload reads the payload of a tag with the type and name passed and returns its Token.
+func (r *TokenReader) payload(t TagType, name string) (Token, error) {
+	tok := Token{Kind: TokenValue, Type: t, Name: name}
+	var err error
+	switch t {
+	case TagByte:
+		var v byte
+		if v, err = r.r.ReadByte(); err != nil {
+			return tok, BufferOverrunError{Op: "Byte"}
+		}
+		tok.Value = v
+	case TagShort:
+		tok.Value, err = r.Encoding.Int16(r.r)
+	case TagInt:
+		tok.Value, err = r.Encoding.Int32(r.r)
+	case TagLong:
+		tok.Value, err = r.Encoding.Int64(r.r)
+	case TagFloat:
+		tok.Value, err = r.Encoding.Float32(r.r)
+	case TagDouble:
+		tok.Value, err = r.Encoding.Float64(r.r)
+	case TagString:
+		tok.Value, err = r.Encoding.String(r.r)
+	case TagByteArray:
+		n, err := r.length("ByteArray")
+		if err != nil {
+			return tok, err
+		}
+		// Read the array in bounded chunks, so that a length prefix exceeding the data available never
+		// allocates more than the data actually read.
+		buf := bytes.NewBuffer(make([]byte, 0, min(n, maxArrayPrealloc)))
+		if m, _ := buf.ReadFrom(io.LimitReader(r.r, int64(n))); m != int64(n) {
+			return tok, BufferOverrunError{Op: "ByteArray"}
+		}
+		tok.Value = buf.Bytes()
+	case TagIntArray:
+		n, err := r.length("IntArray")
+		if err != nil {
+			return tok, err
+		}
+		s := make([]int32, 0, min(n, maxArrayPrealloc))
+		for range n {
+			v, err := r.Encoding.Int32(r.r)
+			if err != nil {
+				return tok, err
+			}
+			if err := r.checkOffset(); err != nil {
+				return tok, err
+			}
+			s = append(s, v)
+		}
+		tok.Value = s
+	case TagLongArray:
+		n, err := r.length("LongArray")
+		if err != nil {
+			return tok, err
+		}
+		s := make([]int64, 0, min(n, maxArrayPrealloc))
+		for range n {
+			v, err := r.Encoding.Int64(r.r)
+			if err != nil {
+				return tok, err
+			}
+			if err := r.checkOffset(); err != nil {
+				return tok, err
+			}
+			s = append(s, v)
+		}
+		tok.Value = s
+	case TagCompound:
+		if err := r.push(tokenFrame{t: TagCompound}); err != nil {
+			return tok, err
+		}
+		tok.Kind = TokenCompound
+	case TagList:
+		b, err := r.r.ReadByte()
+		if err != nil {
+			return tok, BufferOverrunError{Op: "List"}
+		}
+		elemType := TagType(b)
+		if !elemType.IsValid() {
+			return tok, UnknownTagError{Off: r.r.off, Op: "List", TagType: elemType}
+		}
+		n, err := r.length("List")
+		if err != nil {
+			return tok, err
+		}
+		if elemType == TagEnd && n != 0 {
+			return tok, UnexpectedTagError{Off: r.r.off, TagType: elemType}
+		}
+		if err := r.push(tokenFrame{t: TagList, elemType: elemType, remaining: n}); err != nil {
+			return tok, err
+		}
+		tok.Kind, tok.ElemType, tok.Len = TokenList, elemType, n
+	default:
+		return tok, UnknownTagError{Off: r.r.off, Op: "Match", TagType: t}
+	}
+	return tok, err
+}
+
+// length reads the length of a list or array and checks it against the limits of the TokenReader.
+func (r *TokenReader) length(op string) (int, error) {
+	n, err := r.Encoding.Int32(r.r)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, BufferOverrunError{Op: op}
+	}
+	if r.MaxLen > 0 && int(n) > r.MaxLen {
+		return 0, LimitExceededError{Off: r.r.off, Limit: "length", Max: int64(r.MaxLen)}
+	}
+	if remaining, ok := r.r.Reader.(interface{ Len() int }); ok && int(n) > remaining.Len() {
+		// Every element takes at least one byte, so the length can never exceed the remaining data.
+		return 0, BufferOverrunError{Op: op}
+	}
+	if err := r.r.reserve(int64(n)); err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// push enters a compound or list tag, checking the depth limit of the TokenReader.
+func (r *TokenReader) push(frame tokenFrame) error {
+	maxDepth := maximumNestingDepth
+	if r.MaxDepth > 0 && r.MaxDepth < maxDepth {
+		maxDepth = r.MaxDepth
+	}
+	if len(r.stack) >= maxDepth {
+		return LimitExceededError{Off: r.r.off, Limit: "depth", Max: int64(maxDepth)}
+	}
+	r.stack = append(r.stack, frame)
+	return nil
+}
+
+// checkOffset checks if the TokenReader has not read more bytes than it is allowed to.
+func (r *TokenReader) checkOffset() error {
+	if r.r.off >= maximumNetworkOffset && r.Encoding == NetworkLittleEndian {
+		return MaximumBytesReadError{}
+	}
+	if r.MaxBytes > 0 && r.r.off > r.MaxBytes {
+		return LimitExceededError{Off: r.r.off, Limit: "size", Max: r.MaxBytes}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package nbt
+
+import (
+	"io"
+)
+
+// TokenWriter writes NBT to an output stream one Token at a time. Tokens are validated as they are written,
+// so that the output is always well-formed once every started compound and list is ended.
+type TokenWriter struct {
+	// Encoding is the variant used to write the NBT.
+	Encoding Encoding
+	// MaxDepth is the maximum number of nested compound and list tags. Zero means the default maximum depth
+	// of 512, which is also the highest depth allowed.
+	MaxDepth int
+
+	w     *offsetWriter
+	stack []tokenFrame
+}
+
+// NewTokenWriter returns a TokenWriter writing NBT to w using the encoding passed.
+func NewTokenWriter(w io.Writer, encoding Encoding) *TokenWriter {
+	return &TokenWriter{Encoding: encoding, w: newOffsetWriter(w)}
+}
+
+// Depth returns the number of compound and list tags the TokenWriter is currently in.
+func (w *TokenWriter) Depth() int {
+	return len(w.stack)
+}
+
+// WriteToken writes a Token to the output stream. The Token must be valid in its context: Tags in a
+// compound are written with their name, list elements must have the element type of the list and a list
+// may only be ended once all of its elements are written. Tokens read from a TokenReader may be passed to
+// WriteToken unchanged.
+func (w *TokenWriter) WriteToken(tok Token) error {
+	if tok.Kind == TokenEnd {
+		return w.end(tok)
+	}
+	switch tok.Kind {
+	case TokenCompound:
+		tok.Type = TagCompound
+	case TokenList:
+		tok.Type = TagList
+		if tok.Len < 0 || (tok.ElemType == TagEnd && tok.Len != 0) || !tok.ElemType.IsValid() {
+			return InvalidTokenError{Off: w.w.off, Token: tok, Reason: "invalid list header"}
+		}
+	case TokenValue:
+		t, ok := valueType(tok.Value)
+		if !ok || t != tok.Type {
+			return InvalidTokenError{Off: w.w.off, Token: tok, Reason: "value does not match tag type"}
+		}
+	default:
+		return InvalidTokenError{Off: w.w.off, Token: tok, Reason: "unknown token kind"}
+	}
+
+	if len(w.stack) == 0 || w.stack[len(w.stack)-1].t == TagCompound {
+		if err := w.writeTag(tok.Type, tok.Name); err != nil {
+			return err
+		}
+	} else {
+		top := &w.stack[len(w.stack)-1]
+		if top.remaining == 0 {
+			return InvalidTokenError{Off: w.w.off, Token: tok, Reason: "list has no elements left"}
+		}
+		if tok.Type != top.elemType {
+			return InvalidTokenError{Off: w.w.off, Token: tok, Reason: "type does not match list element type " + top.elemType.String()}
+		}
+		top.remaining--
+	}
+	return w.payload(tok)
+}
+
+// end writes a TokenEnd, closing the compound or list last started.
+func (w *TokenWriter) end(tok Token) error {
+	if len(w.stack) == 0 {
+		return InvalidTokenError{Off: w.w.off, Token: tok, Reason: "no compound or list to end"}
+	}
+	top := w.stack[len(w.stack)-1]
+	if top.t == TagList && top.remaining != 0 {
+		return InvalidTokenError{Off: w.w.off, Token: tok, Reason: "list has elements left"}
+	}
+	w.stack = w.stack[:len(w.stack)-1]
+	if top.t == TagCompound {
+		if err := w.w.WriteByte(byte(TagEnd)); err != nil {
+			return FailedWriteError{Op: "WriteEnd", Off: w.w.off, Err: err}
+		}
+	}
+	return nil
+}
+
+// writeTag writes the type and name of a tag in a compound or at the root.
+func (w *TokenWriter) writeTag(t TagType, name string) error {
+	if err := w.w.WriteByte(byte(t)); err != nil {
+		return FailedWriteError{Op: "WriteTag", Off: w.w.off, Err: err}
+	}
+	if _, ok := w.Encoding.(networkBigEndian); ok && t == TagCompound && len(w.stack) == 0 {
+		// As of Minecraft Java 1.20.2, the name of the root compound tag is not written over the network.
+		return nil
+	}
+	return w.Encoding.WriteString(w.w, name)
+}
+
+// payload writes the payload of a token, entering a compound or list if the token starts one.
+func (w *TokenWriter) payload(tok Token) error {
+	switch tok.Kind {
+	case TokenCompound:
+		return w.push(tokenFrame{t: TagCompound})
+	case TokenList:
+		if err := w.w.WriteByte(byte(tok.ElemType)); err != nil {
+			return FailedWriteError{Op: "WriteList", Off: w.w.off, Err: err}
+		}
+		if err := w.Encoding.WriteInt32(w.w, int32(tok.Len)); err != nil {
+			return err
+		}
+		return w.push(tokenFrame{t: TagList, elemType: tok.ElemType, remaining: tok.Len})
+	}
+	switch v := tok.Value.(type) {
+	case byte:
+		return w.w.WriteByte(v)
+	case int16:
+		return w.Encoding.WriteInt16(w.w, v)
+	case int32:
+		return w.Encoding.WriteInt32(w.w, v)
+	case int64:
+		return w.Encoding.WriteInt64(w.w, v)
+	case float32:
+		return w.Encoding.WriteFloat32(w.w, v)
+	case float64:
+		return w.Encoding.WriteFloat64(w.w, v)
+	case string:
+		return w.Encoding.WriteString(w.w, v)
+	case []byte:
+		if err := w.Encoding.WriteInt32(w.w, int32(len(v))); err != nil {
+			return err
+		}
+		if _, err := w.w.Write(v); err != nil {
+			return FailedWriteError{Op: "WriteByteArray", Off: w.w.off, Err: err}
+		}
+	case []int32:
+		if err := w.Encoding.WriteInt32(w.w, int32(len(v))); err != nil {
+			return err
+		}
+		for _, x := range v {
+			if err := w.Encoding.WriteInt32(w.w, x); err != nil {
+				return err
+			}
+		}
+	case []int64:
+		if err := w.Encoding.WriteInt32(w.w, int32(len(v))); err != nil {
+			return err
+		}
+		for _, x := range v {
+			if err := w.Encoding.WriteInt64(w.w, x); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// push enters a compound or list tag, checking the depth limit of the TokenWriter.
+func (w *TokenWriter) push(frame tokenFrame) error {
+	maxDepth := maximumNestingDepth
+	if w.MaxDepth > 0 && w.MaxDepth < maxDepth {
+		maxDepth = w.MaxDepth
+	}
+	if len(w.stack) >= maxDepth {
+		return LimitExceededError{Off: w.w.off, Limit: "depth", Max: int64(maxDepth)}
+	}
+	w.stack = append(w.stack, frame)
+	return nil
+}
@@ -13,6 +13,18 @@ type offsetWriter struct {
 	WriteByte func(byte) error
 }
 
+// newOffsetWriter returns a new offset writer for the io.Writer passed, setting the WriteByte function as
+// appropriate for that particular writer.
+func newOffsetWriter(w io.Writer) *offsetWriter {
+	if byteWriter, ok := w.(io.ByteWriter); ok {
+		return &offsetWriter{Writer: w, WriteByte: byteWriter.WriteByte}
+	}
+	return &offsetWriter{Writer: w, WriteByte: func(b byte) error {
+		_, err := w.Write([]byte{b})
+		return err
+	}}
+}
+
 // Write writes a byte slice to the underlying io.Writer. It increases the byte offset by exactly n.
 func (w *offsetWriter) Write(b []byte) (n int, err error) {
 	n, err = w.Writer.Write(b)
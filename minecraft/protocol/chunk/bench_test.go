@@ -0,0 +1,114 @@
+package chunk_test
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol/chunk"
+)
+
+// benchmarkChunk returns a Chunk with 24 sub-chunks filled with a mix of 40 different blocks, with water in
+// the second layer of some positions.
+func benchmarkChunk() *chunk.Chunk {
+	const air, water = 0, 1
+	r := rand.New(rand.NewPCG(1, 2))
+	c := &chunk.Chunk{SubChunks: make([]*chunk.SubChunk, 24), Biomes: make([]*chunk.PalettedStorage, 24)}
+	for i := range c.SubChunks {
+		s := chunk.NewSubChunk(int8(i-4), air)
+		for x := range byte(16) {
+			for y := range byte(16) {
+				for z := range byte(16) {
+					s.SetBlock(x, y, z, 0, 2+r.Uint32N(40), air)
+					if r.IntN(16) == 0 {
+						s.SetBlock(x, y, z, 1, water, air)
+					}
+				}
+			}
+		}
+		c.SubChunks[i] = s
+	}
+	for i := range c.Biomes {
+		if i > 0 && i%4 != 0 {
+			c.Biomes[i] = c.Biomes[i-1]
+			continue
+		}
+		c.Biomes[i] = chunk.NewPalettedStorage(1)
+		c.Biomes[i].Set(0, 0, 0, 7)
+	}
+	c.BlockEntities = []map[string]any{{"id": "Chest", "x": int32(1), "y": int32(64), "z": int32(3), "Items": []any{}}}
+	return c
+}
+
+func BenchmarkDecode(b *testing.B) {
+	data, err := chunk.Encode(benchmarkChunk())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := chunk.Decode(data, 24, 24); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	c := benchmarkChunk()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := chunk.Encode(c); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeSubChunk(b *testing.B) {
+	data, err := chunk.EncodeSubChunk(benchmarkChunk().SubChunks[0])
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := chunk.DecodeSubChunk(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeSubChunk(b *testing.B) {
+	s := benchmarkChunk().SubChunks[0]
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := chunk.EncodeSubChunk(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPalettedStorageAt(b *testing.B) {
+	s := benchmarkChunk().SubChunks[0].Layers[0]
+	for b.Loop() {
+		for x := range byte(16) {
+			for y := range byte(16) {
+				for z := range byte(16) {
+					_ = s.At(x, y, z)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkPalettedStorageSet(b *testing.B) {
+	s := chunk.NewPalettedStorage(0)
+	for b.Loop() {
+		for x := range byte(16) {
+			for y := range byte(16) {
+				for z := range byte(16) {
+					s.Set(x, y, z, uint32(x^y^z))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package chunk
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+	"github.com/sandertv/gophertunnel/minecraft/protocol/packet"
+)
+
+// Chunk holds the decoded payload of a packet.LevelChunk: The sub-chunks, biomes, border blocks and block
+// entities of a 16xYx16 column of a world.
+type Chunk struct {
+	// SubChunks holds the sub-chunks of the chunk, starting at the bottom of the world. It is empty if the
+	// sub-chunks are sent using the blob cache or requested with packet.SubChunkRequest.
+	SubChunks []*SubChunk
+	// Biomes holds one biome storage for every sub-chunk in the height range of the dimension, starting at
+	// the bottom of the world. A storage may be the same pointer as the storage before it, in which case it
+	// is encoded as a copy of it.
+	Biomes []*PalettedStorage
+	// BorderBlocks holds the positions of border blocks in the chunk, which are only used in Education
+	// Edition. Each position holds the X coordinate in the upper 4 bits and the Z coordinate in the lower 4
+	// bits.
+	BorderBlocks []byte
+	// BlockEntities holds the NBT of the block entities in the chunk.
+	BlockEntities []map[string]any
+}
+
+// SubChunkCount returns the number of sub-chunks in the height range of one of the vanilla dimensions, which
+// is also the number of biome storages in the payload of a packet.LevelChunk for that dimension.
+func SubChunkCount(dimension int32) int {
+	switch dimension {
+	case packet.DimensionNether:
+		// 0-127.
+		return 8
+	case packet.DimensionEnd:
+		// 0-255.
+		return 16
+	default:
+		// -64-319.
+		return 24
+	}
+}
+
+// DecodeLevelChunk decodes the RawPayload of a packet.LevelChunk. The number of sub-chunks and biome
+// storages in the payload is derived from the fields of the packet and its dimension. Decode should be used
+// for dimensions with a custom height range.
+func DecodeLevelChunk(pk *packet.LevelChunk) (*Chunk, error) {
+	subChunkCount, biomeCount := int(pk.SubChunkCount), SubChunkCount(pk.Dimension)
+	if _, ok := pk.SubChunkLimit.Value(); ok {
+		// The sub-chunks are requested by the client with a SubChunkRequest packet.
+		subChunkCount = 0
+	}
+	if pk.CacheEnabled {
+		// Both the sub-chunks and the biomes are sent as blobs.
+		subChunkCount, biomeCount = 0, 0
+	}
+	return Decode(pk.RawPayload, subChunkCount, biomeCount)
+}
+
+// EncodeLevelChunk encodes a Chunk to a packet.LevelChunk at the position and in the dimension passed. The
+// blob cache and sub-chunk request mode are not used.
+func EncodeLevelChunk(c *Chunk, pos protocol.ChunkPos, dimension int32) (*packet.LevelChunk, error) {
+	payload, err := Encode(c)
+	if err != nil {
+		return nil, err
+	}
+	return &packet.LevelChunk{
+		Position:      pos,
+		Dimension:     dimension,
+		SubChunkCount: uint32(len(c.SubChunks)),
+		RawPayload:    payload,
+	}, nil
+}
+
+// Decode decodes the payload of a packet.LevelChunk holding the number of sub-chunks and biome storages
+// passed.
+func Decode(data []byte, subChunkCount, biomeCount int) (*Chunk, error) {
+	buf := bytes.NewBuffer(data)
+	c := &Chunk{SubChunks: make([]*SubChunk, subChunkCount), Biomes: make([]*PalettedStorage, biomeCount)}
+	for i := range c.SubChunks {
+		s, err := decodeSubChunk(buf)
+		if err != nil {
+			return nil, fmt.Errorf("sub-chunk %v: %w", i, err)
+		}
+		c.SubChunks[i] = s
+	}
+	for i := range c.Biomes {
+		s, err := decodePalettedStorage(buf)
+		if err != nil {
+			return nil, fmt.Errorf("decode biomes %v: %w", i, err)
+		}
+		if s == nil {
+			if i == 0 {
+				return nil, fmt.Errorf("decode biomes 0: first biome storage cannot copy the previous storage")
+			}
+			s = c.Biomes[i-1]
+		}
+		c.Biomes[i] = s
+	}
+
+	borderBlockCount, err := buf.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("read border block count: %w", err)
+	}
+	if borderBlockCount > 0 {
+		c.BorderBlocks = bytes.Clone(buf.Next(int(borderBlockCount)))
+		if len(c.BorderBlocks) != int(borderBlockCount) {
+			return nil, fmt.Errorf("read border blocks: expected %v bytes, got %v", borderBlockCount, len(c.BorderBlocks))
+		}
+	}
+	if c.BlockEntities, err = decodeBlockEntities(buf); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Encode encodes a Chunk to the payload of a packet.LevelChunk.
+func Encode(c *Chunk) ([]byte, error) {
+	if len(c.BorderBlocks) > 255 {
+		return nil, fmt.Errorf("encode chunk: too many border blocks (%v)", len(c.BorderBlocks))
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, 4096))
+	for i, s := range c.SubChunks {
+		if err := encodeSubChunk(buf, s); err != nil {
+			return nil, fmt.Errorf("sub-chunk %v: %w", i, err)
+		}
+	}
+	for i, s := range c.Biomes {
+		if i > 0 && s == c.Biomes[i-1] {
+			buf.WriteByte(storageCopyLast<<1 | 1)
+			continue
+		}
+		encodePalettedStorage(buf, s)
+	}
+	buf.WriteByte(byte(len(c.BorderBlocks)))
+	buf.Write(c.BorderBlocks)
+	if err := encodeBlockEntities(buf, c.BlockEntities); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,12 @@
+// Package chunk implements decoding and encoding of the network formats of chunks and sub-chunks, as found in
+// the payload of a packet.LevelChunk and in the sub-chunk entries of a packet.SubChunk.
+//
+// Blocks and biomes are held in a PalettedStorage, which holds a palette of values and an index into that
+// palette for every position in a sub-chunk. The values held by block storages are block runtime IDs or, if
+// the UseBlockNetworkIDHashes field of the GameData of a connection is true, block network ID hashes. The
+// package does not interpret these values, so both are handled the same way.
+//
+// A LevelChunk packet may be decoded using chunk.DecodeLevelChunk(), after which individual blocks may be read
+// using Chunk.SubChunks[i].Block(). The payload of a sub-chunk entry may be decoded using
+// chunk.DecodeSubChunkPayload().
+package chunk
@@ -0,0 +1,277 @@
+package chunk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"slices"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// PalettedStorage is a storage of 4096 values, one for every position in a 16x16x16 sub-chunk, used for both
+// blocks and biomes. Rather than holding every value directly, it holds a palette of the distinct values in
+// the storage and, for every position, an index into that palette packed into 32-bit words.
+//
+// The values held are block runtime IDs, block network ID hashes or biome IDs, depending on what the storage
+// is used for. PalettedStorage does not interpret them.
+type PalettedStorage struct {
+	bitsPerIndex   uint8
+	indicesPerWord uint8
+	indexMask      uint32
+	words          []uint32
+	palette        []uint32
+}
+
+// validBitsPerIndex is a list of all bit widths that a PalettedStorage may use for its indices.
+var validBitsPerIndex = [...]uint8{0, 1, 2, 3, 4, 5, 6, 8, 16}
+
+// NewPalettedStorage returns a PalettedStorage with every position set to the value passed. It uses no memory
+// for indices until a different value is set.
+func NewPalettedStorage(value uint32) *PalettedStorage {
+	return newPalettedStorage(0, nil, []uint32{value})
+}
+
+// newPalettedStorage returns a PalettedStorage using indices of the bit width passed, packed into words.
+func newPalettedStorage(bitsPerIndex uint8, words, palette []uint32) *PalettedStorage {
+	s := &PalettedStorage{bitsPerIndex: bitsPerIndex, words: words, palette: palette}
+	if bitsPerIndex != 0 {
+		s.indicesPerWord = 32 / bitsPerIndex
+		s.indexMask = 1<<bitsPerIndex - 1
+		if s.words == nil {
+			s.words = make([]uint32, wordCount(bitsPerIndex))
+		}
+	}
+	return s
+}
+
+// wordCount returns the number of 32-bit words needed to hold 4096 indices of the bit width passed. Indices
+// never span multiple words, so some bits of each word may be left unused.
+func wordCount(bitsPerIndex uint8) int {
+	if bitsPerIndex == 0 {
+		return 0
+	}
+	indicesPerWord := 32 / int(bitsPerIndex)
+	return (4096 + indicesPerWord - 1) / indicesPerWord
+}
+
+// At returns the value at the position passed. x, y and z must be in the range 0-15.
+func (s *PalettedStorage) At(x, y, z byte) uint32 {
+	if s.bitsPerIndex == 0 {
+		return s.palette[0]
+	}
+	return s.palette[s.index(offset(x, y, z))]
+}
+
+// Set sets the value at the position passed. x, y and z must be in the range 0-15. If the value is not yet in
+// the palette of the storage, it is added, and the indices are widened if the palette no longer fits.
+func (s *PalettedStorage) Set(x, y, z byte, v uint32) {
+	i := slices.Index(s.palette, v)
+	if i == -1 {
+		if len(s.palette) >= 4096 {
+			// A sub-chunk can hold no more than 4096 distinct values, so some in the palette may be unused.
+			s.Compact()
+			if len(s.palette) >= 4096 {
+				// Every position holds a different value, so the value overwritten is not used anywhere else:
+				// Replace it in the palette.
+				s.palette[s.index(offset(x, y, z))] = v
+				return
+			}
+		}
+		i = len(s.palette)
+		s.palette = append(s.palette, v)
+		if len(s.palette) > 1<<s.bitsPerIndex {
+			s.resize(requiredBitsPerIndex(len(s.palette)))
+		}
+	}
+	if s.bitsPerIndex != 0 {
+		s.setIndex(offset(x, y, z), uint32(i))
+	}
+}
+
+// Palette returns the palette of the storage: The distinct values the storage may hold. The palette may
+// contain values that are no longer at any position. The slice returned must not be modified.
+func (s *PalettedStorage) Palette() []uint32 {
+	return s.palette
+}
+
+// BitsPerIndex returns the number of bits used for each index into the palette. It is 0 if the storage holds
+// the same value at every position.
+func (s *PalettedStorage) BitsPerIndex() int {
+	return int(s.bitsPerIndex)
+}
+
+// Uniform checks if the storage holds the same value at every position and returns that value if so.
+func (s *PalettedStorage) Uniform() (uint32, bool) {
+	if s.bitsPerIndex == 0 {
+		return s.palette[0], true
+	}
+	first := s.palette[s.index(0)]
+	for i := uint16(1); i < 4096; i++ {
+		if s.palette[s.index(i)] != first {
+			return 0, false
+		}
+	}
+	return first, true
+}
+
+// Compact removes values from the palette that are not at any position and shrinks the indices of the
+// storage to the smallest bit width that fits the palette that remains.
+func (s *PalettedStorage) Compact() {
+	if s.bitsPerIndex == 0 {
+		return
+	}
+	used := make([]bool, len(s.palette))
+	for i := uint16(0); i < 4096; i++ {
+		used[s.index(i)] = true
+	}
+	remap := make([]uint32, len(s.palette))
+	palette := make([]uint32, 0, len(s.palette))
+	for i, v := range s.palette {
+		if used[i] {
+			remap[i] = uint32(len(palette))
+			palette = append(palette, v)
+		}
+	}
+	compacted := newPalettedStorage(requiredBitsPerIndex(len(palette)), nil, palette)
+	if compacted.bitsPerIndex != 0 {
+		for i := uint16(0); i < 4096; i++ {
+			compacted.setIndex(i, remap[s.index(i)])
+		}
+	}
+	*s = *compacted
+}
+
+// Clone returns a deep copy of the storage.
+func (s *PalettedStorage) Clone() *PalettedStorage {
+	c := *s
+	c.words, c.palette = slices.Clone(s.words), slices.Clone(s.palette)
+	return &c
+}
+
+// resize changes the bit width of the indices of the storage, keeping the value at every position.
+func (s *PalettedStorage) resize(bitsPerIndex uint8) {
+	resized := newPalettedStorage(bitsPerIndex, nil, s.palette)
+	if s.bitsPerIndex != 0 {
+		for i := uint16(0); i < 4096; i++ {
+			resized.setIndex(i, s.index(i))
+		}
+	}
+	*s = *resized
+}
+
+// index returns the palette index at the offset passed.
+func (s *PalettedStorage) index(off uint16) uint32 {
+	word, shift := s.position(off)
+	return (s.words[word] >> shift) & s.indexMask
+}
+
+// setIndex sets the palette index at the offset passed.
+func (s *PalettedStorage) setIndex(off uint16, index uint32) {
+	word, shift := s.position(off)
+	s.words[word] = s.words[word]&^(s.indexMask<<shift) | index<<shift
+}
+
+// position returns the word and bit shift within that word holding the index at the offset passed.
+func (s *PalettedStorage) position(off uint16) (int, uint32) {
+	return int(off / uint16(s.indicesPerWord)), uint32(off%uint16(s.indicesPerWord)) * uint32(s.bitsPerIndex)
+}
+
+// offset returns the offset of a position in a sub-chunk. Positions are ordered by X, then Z, then Y.
+func offset(x, y, z byte) uint16 {
+	return uint16(x&15)<<8 | uint16(z&15)<<4 | uint16(y&15)
+}
+
+// requiredBitsPerIndex returns the smallest valid bit width that fits indices into a palette of the size
+// passed.
+func requiredBitsPerIndex(paletteSize int) uint8 {
+	if paletteSize <= 1 {
+		return 0
+	}
+	n := uint8(bits.Len(uint(paletteSize - 1)))
+	for _, b := range validBitsPerIndex {
+		if b >= n {
+			return b
+		}
+	}
+	return 16
+}
+
+// storageCopyLast is the bit width written in the header of a biome storage to indicate that it is equal to
+// the storage of the sub-chunk below it.
+const storageCopyLast = 0x7f
+
+// decodePalettedStorage decodes a PalettedStorage in its network format from buf. A nil storage is returned
+// if the header indicates the storage is a copy of the previous one, which is only valid for biomes.
+func decodePalettedStorage(buf *bytes.Buffer) (*PalettedStorage, error) {
+	header, err := buf.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("read storage header: %w", err)
+	}
+	bitsPerIndex := header >> 1
+	if bitsPerIndex == storageCopyLast {
+		return nil, nil
+	}
+	if header&1 == 0 {
+		return nil, fmt.Errorf("storage header %#x has persistent palette, expected runtime palette", header)
+	}
+	if !slices.Contains(validBitsPerIndex[:], bitsPerIndex) {
+		return nil, fmt.Errorf("invalid storage bits per index %v", bitsPerIndex)
+	}
+
+	var words []uint32
+	if n := wordCount(bitsPerIndex); n > 0 {
+		data := buf.Next(n * 4)
+		if len(data) != n*4 {
+			return nil, fmt.Errorf("read storage words: expected %v bytes, got %v", n*4, len(data))
+		}
+		words = make([]uint32, n)
+		for i := range words {
+			words[i] = binary.LittleEndian.Uint32(data[i*4:])
+		}
+	}
+
+	paletteSize := int32(1)
+	if bitsPerIndex != 0 {
+		if err := protocol.Varint32(buf, &paletteSize); err != nil {
+			return nil, fmt.Errorf("read palette size: %w", err)
+		}
+		if paletteSize <= 0 || paletteSize > 4096 {
+			return nil, fmt.Errorf("invalid palette size %v", paletteSize)
+		}
+	}
+	palette := make([]uint32, paletteSize)
+	for i := range palette {
+		var v int32
+		if err := protocol.Varint32(buf, &v); err != nil {
+			return nil, fmt.Errorf("read palette entry %v: %w", i, err)
+		}
+		palette[i] = uint32(v)
+	}
+
+	s := newPalettedStorage(bitsPerIndex, words, palette)
+	if bitsPerIndex != 0 && len(palette) < 1<<bitsPerIndex {
+		for i := uint16(0); i < 4096; i++ {
+			if index := s.index(i); index >= uint32(len(palette)) {
+				return nil, fmt.Errorf("palette index %v out of range for palette of size %v", index, len(palette))
+			}
+		}
+	}
+	return s, nil
+}
+
+// encodePalettedStorage encodes a PalettedStorage in its network format to buf.
+func encodePalettedStorage(buf *bytes.Buffer, s *PalettedStorage) {
+	buf.WriteByte(s.bitsPerIndex<<1 | 1)
+	buf.Grow(len(s.words)*4 + len(s.palette)*5 + 5)
+	for _, word := range s.words {
+		buf.Write(binary.LittleEndian.AppendUint32(buf.AvailableBuffer(), word))
+	}
+	if s.bitsPerIndex != 0 {
+		_ = protocol.WriteVarint32(buf, int32(len(s.palette)))
+	}
+	for _, v := range s.palette {
+		_ = protocol.WriteVarint32(buf, int32(v))
+	}
+}
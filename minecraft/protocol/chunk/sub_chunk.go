@@ -0,0 +1,239 @@
+package chunk
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/sandertv/gophertunnel/minecraft/nbt"
+)
+
+const (
+	// SubChunkVersionSingleLayer is the oldest sub-chunk version still accepted by the client. Sub-chunks of
+	// this version hold a single layer of blocks.
+	SubChunkVersionSingleLayer = 1
+	// SubChunkVersionLayers is the sub-chunk version that holds a variable number of layers of blocks.
+	SubChunkVersionLayers = 8
+	// SubChunkVersionIndexed is the sub-chunk version that holds a variable number of layers of blocks and the
+	// Y index of the sub-chunk. It is the version sent by vanilla servers.
+	SubChunkVersionIndexed = 9
+)
+
+// SubChunk is a 16x16x16 section of a chunk. It holds one or more layers of blocks: The first layer holds the
+// regular blocks, while the second layer is generally used for water in waterlogged blocks.
+type SubChunk struct {
+	// Version is the version the sub-chunk is encoded with. It is one of the SubChunkVersion constants above.
+	// If left zero, SubChunkVersionIndexed is used.
+	Version byte
+	// Index is the Y index of the sub-chunk, counting from Y=0, so that the sub-chunk at Y=-64 has index -4.
+	// It is only encoded with SubChunkVersionIndexed.
+	Index int8
+	// Layers holds the layers of blocks of the sub-chunk. The values in the storages are block runtime IDs
+	// or, if GameData.UseBlockNetworkIDHashes is true, block network ID hashes. Both are encoded the same way.
+	Layers []*PalettedStorage
+}
+
+// NewSubChunk returns a SubChunk with the Y index passed and a single layer that holds the air block passed
+// at every position.
+func NewSubChunk(index int8, air uint32) *SubChunk {
+	return &SubChunk{Version: SubChunkVersionIndexed, Index: index, Layers: []*PalettedStorage{NewPalettedStorage(air)}}
+}
+
+// Block returns the block at the position passed in the layer passed. x, y and z must be in the range 0-15.
+// If the sub-chunk does not have the layer, the air block passed is returned.
+func (s *SubChunk) Block(x, y, z byte, layer int, air uint32) uint32 {
+	if layer >= len(s.Layers) {
+		return air
+	}
+	return s.Layers[layer].At(x, y, z)
+}
+
+// SetBlock sets the block at the position passed in the layer passed. x, y and z must be in the range 0-15.
+// If the sub-chunk does not have the layer yet, layers filled with the air block passed are added.
+func (s *SubChunk) SetBlock(x, y, z byte, layer int, block, air uint32) {
+	if layer >= len(s.Layers) {
+		if block == air {
+			return
+		}
+		for len(s.Layers) <= layer {
+			s.Layers = append(s.Layers, NewPalettedStorage(air))
+		}
+	}
+	s.Layers[layer].Set(x, y, z, block)
+}
+
+// Empty checks if every layer of the sub-chunk holds only the air block passed.
+func (s *SubChunk) Empty(air uint32) bool {
+	for _, layer := range s.Layers {
+		if v, ok := layer.Uniform(); !ok || v != air {
+			return false
+		}
+	}
+	return true
+}
+
+// Compact compacts every layer of the sub-chunk and removes trailing layers that hold only the air block
+// passed. The first layer is always kept.
+func (s *SubChunk) Compact(air uint32) {
+	for _, layer := range s.Layers {
+		layer.Compact()
+	}
+	for len(s.Layers) > 1 {
+		if v, ok := s.Layers[len(s.Layers)-1].Uniform(); !ok || v != air {
+			break
+		}
+		s.Layers = s.Layers[:len(s.Layers)-1]
+	}
+}
+
+// Clone returns a deep copy of the sub-chunk.
+func (s *SubChunk) Clone() *SubChunk {
+	c := *s
+	c.Layers = make([]*PalettedStorage, len(s.Layers))
+	for i, layer := range s.Layers {
+		c.Layers[i] = layer.Clone()
+	}
+	return &c
+}
+
+// DecodeSubChunk decodes a sub-chunk in its network format from data, as found in the payload of a
+// packet.LevelChunk. data must hold exactly one sub-chunk.
+func DecodeSubChunk(data []byte) (*SubChunk, error) {
+	buf := bytes.NewBuffer(data)
+	s, err := decodeSubChunk(buf)
+	if err != nil {
+		return nil, err
+	}
+	if buf.Len() != 0 {
+		return nil, fmt.Errorf("decode sub-chunk: %v unread bytes left", buf.Len())
+	}
+	return s, nil
+}
+
+// EncodeSubChunk encodes a sub-chunk to its network format.
+func EncodeSubChunk(s *SubChunk) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+	if err := encodeSubChunk(buf, s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeSubChunkPayload decodes the RawPayload of a protocol.SubChunkEntry, which holds a sub-chunk followed
+// by the NBT of the block entities in it.
+func DecodeSubChunkPayload(data []byte) (*SubChunk, []map[string]any, error) {
+	buf := bytes.NewBuffer(data)
+	s, err := decodeSubChunk(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	blockEntities, err := decodeBlockEntities(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, blockEntities, nil
+}
+
+// EncodeSubChunkPayload encodes a sub-chunk followed by the NBT of the block entities passed, so that it may
+// be used as the RawPayload of a protocol.SubChunkEntry.
+func EncodeSubChunkPayload(s *SubChunk, blockEntities []map[string]any) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+	if err := encodeSubChunk(buf, s); err != nil {
+		return nil, err
+	}
+	if err := encodeBlockEntities(buf, blockEntities); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeSubChunk decodes a single sub-chunk from buf.
+func decodeSubChunk(buf *bytes.Buffer) (*SubChunk, error) {
+	version, err := buf.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("decode sub-chunk: read version: %w", err)
+	}
+	s := &SubChunk{Version: version}
+	layerCount := byte(1)
+	switch version {
+	case SubChunkVersionSingleLayer:
+	case SubChunkVersionLayers, SubChunkVersionIndexed:
+		if layerCount, err = buf.ReadByte(); err != nil {
+			return nil, fmt.Errorf("decode sub-chunk: read layer count: %w", err)
+		}
+		if version == SubChunkVersionIndexed {
+			index, err := buf.ReadByte()
+			if err != nil {
+				return nil, fmt.Errorf("decode sub-chunk: read index: %w", err)
+			}
+			s.Index = int8(index)
+		}
+	default:
+		return nil, fmt.Errorf("decode sub-chunk: unknown version %v", version)
+	}
+	s.Layers = make([]*PalettedStorage, layerCount)
+	for i := range s.Layers {
+		layer, err := decodePalettedStorage(buf)
+		if err != nil {
+			return nil, fmt.Errorf("decode sub-chunk: layer %v: %w", i, err)
+		}
+		if layer == nil {
+			return nil, fmt.Errorf("decode sub-chunk: layer %v: block storage cannot copy the previous storage", i)
+		}
+		s.Layers[i] = layer
+	}
+	return s, nil
+}
+
+// encodeSubChunk encodes a single sub-chunk to buf.
+func encodeSubChunk(buf *bytes.Buffer, s *SubChunk) error {
+	version := s.Version
+	if version == 0 {
+		version = SubChunkVersionIndexed
+	}
+	buf.WriteByte(version)
+	switch version {
+	case SubChunkVersionSingleLayer:
+		if len(s.Layers) != 1 {
+			return fmt.Errorf("encode sub-chunk: version %v must have exactly 1 layer, got %v", version, len(s.Layers))
+		}
+	case SubChunkVersionLayers, SubChunkVersionIndexed:
+		if len(s.Layers) > 255 {
+			return fmt.Errorf("encode sub-chunk: too many layers (%v)", len(s.Layers))
+		}
+		buf.WriteByte(byte(len(s.Layers)))
+		if version == SubChunkVersionIndexed {
+			buf.WriteByte(byte(s.Index))
+		}
+	default:
+		return fmt.Errorf("encode sub-chunk: unknown version %v", version)
+	}
+	for _, layer := range s.Layers {
+		encodePalettedStorage(buf, layer)
+	}
+	return nil
+}
+
+// decodeBlockEntities decodes the NBT of block entities from buf until it is empty.
+func decodeBlockEntities(buf *bytes.Buffer) ([]map[string]any, error) {
+	var blockEntities []map[string]any
+	dec := nbt.NewDecoderWithEncoding(buf, nbt.NetworkLittleEndian)
+	for buf.Len() > 0 {
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			return nil, fmt.Errorf("decode block entity %v: %w", len(blockEntities), err)
+		}
+		blockEntities = append(blockEntities, m)
+	}
+	return blockEntities, nil
+}
+
+// encodeBlockEntities encodes the NBT of the block entities passed to buf.
+func encodeBlockEntities(buf *bytes.Buffer, blockEntities []map[string]any) error {
+	enc := nbt.NewEncoderWithEncoding(buf, nbt.NetworkLittleEndian)
+	for i, m := range blockEntities {
+		if err := enc.Encode(m); err != nil {
+			return fmt.Errorf("encode block entity %v: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -138,13 +138,9 @@ func Parse(request []byte, verifier *oidc.IDTokenVerifier) (IdentityData, Client
 		}
 	} else if req.Token != "" {
 		// Parse the token without verification to extract identity and the public key for encryption.
-		tok, err := jwt.ParseSigned(req.Token, []jose.SignatureAlgorithm{jose.ES384, jose.RS256})
+		claims, err := parseUnverifiedToken(req.Token)
 		if err != nil {
-			return iData, cData, res, fmt.Errorf("parse unverified token: %w", err)
-		}
-		var claims tokenClaims
-		if err := tok.UnsafeClaimsWithoutVerification(&claims); err != nil {
-			return iData, cData, res, fmt.Errorf("parse unverified token claims: %w", err)
+			return iData, cData, res, err
 		}
 		if err := ParsePublicKey(claims.ClientPublicKey, key); err != nil {
 			return iData, cData, res, fmt.Errorf("parse cpk: %w", err)
@@ -323,39 +319,41 @@ func parseAsKey(k any, pub *ecdsa.PublicKey) error {
 // Encode encodes a login request using the encoded login chain passed and the client data. The request's
 // client data token is signed using the private key passed. It must be the same as the one used to get the
 // login chain. The multiplayer token is used as the Token field in the connection request.
+// If the login chain is empty, the request is authenticated using only the multiplayer token.
 func Encode(loginChain string, data ClientData, key *ecdsa.PrivateKey, token string, legacy bool) []byte {
-	// We first decode the login chain we actually got in a new certificate.
-	cert := &certificate{}
-	_ = json.Unmarshal([]byte(loginChain), &cert)
-
-	// We parse the header of the first claim it has in the chain, which will soon be the second claim.
 	keyData := MarshalPublicKey(&key.PublicKey)
-	tok, _ := jwt.ParseSigned(cert.Chain[0], []jose.SignatureAlgorithm{jose.ES384})
-
-	//lint:ignore S1005 Double assignment is done explicitly to prevent panics.
-	x5uData, _ := tok.Headers[0].ExtraHeaders["x5u"]
-	x5u, _ := x5uData.(string)
-	claims := jwt.Claims{
-		Expiry:    jwt.NewNumericDate(time.Now().Add(time.Hour * 6)),
-		NotBefore: jwt.NewNumericDate(time.Now().Add(-time.Hour * 6)),
-	}
-
 	signer, _ := jose.NewSigner(jose.SigningKey{Key: key, Algorithm: jose.ES384}, &jose.SignerOptions{
 		ExtraHeaders: map[jose.HeaderKey]any{"x5u": keyData},
 	})
-	firstJWT, _ := jwt.Signed(signer).Claims(identityPublicKeyClaims{
-		Claims:               claims,
-		IdentityPublicKey:    x5u,
-		CertificateAuthority: true,
-	}).Serialize()
 
 	req := &request{
-		Certificate: certificate{
-			// We add our own claim at the start of the chain.
-			Chain: append(chain{firstJWT}, cert.Chain...),
-		},
-		Token:  token,
-		Legacy: legacy,
+		Certificate: certificate{Chain: chain{""}},
+		Token:       token,
+		Legacy:      legacy,
+	}
+	if loginChain != "" {
+		// We first decode the login chain we actually got in a new certificate.
+		cert := &certificate{}
+		_ = json.Unmarshal([]byte(loginChain), &cert)
+
+		// We parse the header of the first claim it has in the chain, which will soon be the second claim.
+		tok, _ := jwt.ParseSigned(cert.Chain[0], []jose.SignatureAlgorithm{jose.ES384})
+
+		//lint:ignore S1005 Double assignment is done explicitly to prevent panics.
+		x5uData, _ := tok.Headers[0].ExtraHeaders["x5u"]
+		x5u, _ := x5uData.(string)
+		claims := jwt.Claims{
+			Expiry:    jwt.NewNumericDate(time.Now().Add(time.Hour * 6)),
+			NotBefore: jwt.NewNumericDate(time.Now().Add(-time.Hour * 6)),
+		}
+		firstJWT, _ := jwt.Signed(signer).Claims(identityPublicKeyClaims{
+			Claims:               claims,
+			IdentityPublicKey:    x5u,
+			CertificateAuthority: true,
+		}).Serialize()
+
+		// We add our own claim at the start of the chain.
+		req.Certificate.Chain = append(chain{firstJWT}, cert.Chain...)
 	}
 	// We create another token this time, which is signed the same as the claim we just inserted in the chain,
 	// just now it contains client data.
@@ -471,6 +469,29 @@ func (tc tokenClaims) identityData() IdentityData {
 	}
 }
 
+// ParseTokenIdentityData returns the IdentityData held by the claims of the multiplayer token passed,
+// without verifying the token. It is typically used by a client to find out the identity it logs in with.
+func ParseTokenIdentityData(token string) (IdentityData, error) {
+	claims, err := parseUnverifiedToken(token)
+	if err != nil {
+		return IdentityData{}, err
+	}
+	return claims.identityData(), nil
+}
+
+// parseUnverifiedToken parses the claims of the multiplayer token passed without verifying its signature.
+func parseUnverifiedToken(token string) (tokenClaims, error) {
+	tok, err := jwt.ParseSigned(token, []jose.SignatureAlgorithm{jose.ES256, jose.ES384, jose.ES512, jose.RS256})
+	if err != nil {
+		return tokenClaims{}, fmt.Errorf("parse unverified token: %w", err)
+	}
+	var claims tokenClaims
+	if err := tok.UnsafeClaimsWithoutVerification(&claims); err != nil {
+		return tokenClaims{}, fmt.Errorf("parse unverified token claims: %w", err)
+	}
+	return claims, nil
+}
+
 // identityFromXUID returns the UUID derived from the player's XUID claimed
 // from the new multiplayer token.
 func identityFromXUID(xuid string) uuid.UUID {
@@ -0,0 +1,418 @@
+package packet
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// MarshalJSON encodes a packet into JSON, so that it may be logged or inspected. The JSON is an object holding
+// the ID of the packet, the name of its type and its fields:
+//
+//	{"id": 9, "name": "Text", "packet": {"TextType": 1, ...}}
+//
+// Fields are encoded using their Go names, in the order they are declared. protocol.Optional fields are null
+// if not set. Values of interface types, such as protocol.StackRequestAction, and the values in NBT and entity
+// metadata maps are encoded as an object holding the name of their concrete type and their value:
+//
+//	{"type": "TakeStackRequestAction", "value": {...}}
+//	{"type": "int16", "value": 5}
+//
+// Byte slices and arrays are encoded as base64, and floats that are not finite as the strings "NaN", "+Inf"
+// and "-Inf". Decoding the JSON using UnmarshalJSON results in a packet that encodes to the same bytes as the
+// packet passed, apart from the order of the tags in NBT compounds, which is not kept by Go maps either.
+func MarshalJSON(pk Packet) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString(`{"id":` + strconv.FormatUint(uint64(pk.ID()), 10) + `,"name":`)
+	writeJSONString(buf, reflect.TypeOf(pk).Elem().Name())
+	buf.WriteString(`,"packet":`)
+	if err := encodeJSON(buf, reflect.ValueOf(pk).Elem()); err != nil {
+		return nil, fmt.Errorf("encode %T: %w", pk, err)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a packet encoded using MarshalJSON. The packet is created using the function in the
+// Pool passed for the ID in the JSON, so NewClientPool or NewServerPool should be passed depending on the
+// side that sent the packet.
+func UnmarshalJSON(data []byte, pool Pool) (Packet, error) {
+	var v struct {
+		ID     *uint32
+		Name   string
+		Packet json.RawMessage
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("decode packet JSON: %w", err)
+	}
+	if v.ID == nil {
+		return nil, fmt.Errorf("decode packet JSON: missing packet ID")
+	}
+	f, ok := pool[*v.ID]
+	if !ok {
+		return nil, fmt.Errorf("decode packet JSON: unknown packet ID %v", *v.ID)
+	}
+	pk := f()
+	if name := reflect.TypeOf(pk).Elem().Name(); v.Name != "" && v.Name != name {
+		return nil, fmt.Errorf("decode packet JSON: packet ID %v is %v, not %v", *v.ID, name, v.Name)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(v.Packet))
+	dec.UseNumber()
+	var fields any
+	if err := dec.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("decode packet JSON: %w", err)
+	}
+	if err := decodeJSON(reflect.ValueOf(pk).Elem(), fields); err != nil {
+		return nil, fmt.Errorf("decode %T: %w", pk, err)
+	}
+	return pk, nil
+}
+
+// jsonVariants holds the concrete types of all interface types found in packets, indexed by the name of the
+// type. The concrete type of a value of one of these interfaces is encoded along with it.
+var jsonVariants = map[reflect.Type]map[string]reflect.Type{
+	reflect.TypeFor[protocol.StackRequestAction](): variants(
+		&protocol.TakeStackRequestAction{}, &protocol.PlaceStackRequestAction{}, &protocol.SwapStackRequestAction{},
+		&protocol.DropStackRequestAction{}, &protocol.DestroyStackRequestAction{}, &protocol.ConsumeStackRequestAction{},
+		&protocol.CreateStackRequestAction{}, &protocol.LabTableCombineStackRequestAction{},
+		&protocol.BeaconPaymentStackRequestAction{}, &protocol.MineBlockStackRequestAction{},
+		&protocol.CraftRecipeStackRequestAction{}, &protocol.AutoCraftRecipeStackRequestAction{},
+		&protocol.CraftCreativeStackRequestAction{}, &protocol.CraftRecipeOptionalStackRequestAction{},
+		&protocol.CraftGrindstoneRecipeStackRequestAction{}, &protocol.CraftLoomRecipeStackRequestAction{},
+		&protocol.CraftNonImplementedStackRequestAction{}, &protocol.CraftResultsDeprecatedStackRequestAction{},
+	),
+	reflect.TypeFor[protocol.InventoryTransactionData](): variants(
+		&protocol.NormalTransactionData{}, &protocol.MismatchTransactionData{}, &protocol.UseItemTransactionData{},
+		&protocol.UseItemOnEntityTransactionData{}, &protocol.ReleaseItemTransactionData{},
+	),
+	reflect.TypeFor[protocol.ItemDescriptor](): variants(
+		&protocol.InvalidItemDescriptor{}, &protocol.DefaultItemDescriptor{}, &protocol.MoLangItemDescriptor{},
+		&protocol.ItemTagItemDescriptor{},
+	),
+	reflect.TypeFor[protocol.ShapeData](): variants(
+		&protocol.ArrowShape{}, &protocol.BoxShape{}, &protocol.ConeShape{}, &protocol.CylinderShape{},
+		&protocol.EllipsoidShape{}, &protocol.LastShape{}, &protocol.LineShape{}, &protocol.PyramidShape{},
+		&protocol.SphereShape{}, &protocol.TextShape{},
+	),
+	reflect.TypeFor[protocol.Event](): variants(
+		&protocol.AchievementAwardedEvent{}, &protocol.EntityInteractEvent{}, &protocol.PortalBuiltEvent{},
+		&protocol.PortalUsedEvent{}, &protocol.MobKilledEvent{}, &protocol.CauldronUsedEvent{},
+		&protocol.PlayerDiedEvent{}, &protocol.BossKilledEvent{}, &protocol.AgentCommandEvent{},
+		&protocol.AgentCreatedEvent{}, &protocol.PatternRemovedEvent{}, &protocol.SlashCommandExecutedEvent{},
+		&protocol.FishBucketedEvent{}, &protocol.MobBornEvent{}, &protocol.PetDiedEvent{},
+		&protocol.CauldronInteractEvent{}, &protocol.ComposterInteractEvent{}, &protocol.BellUsedEvent{},
+		&protocol.EntityDefinitionTriggerEvent{}, &protocol.RaidUpdateEvent{}, &protocol.MovementAnomalyEvent{},
+		&protocol.MovementCorrectedEvent{}, &protocol.ExtractHoneyEvent{}, &protocol.TargetBlockHitEvent{},
+		&protocol.PiglinBarterEvent{}, &protocol.WaxedOrUnwaxedCopperEvent{},
+		&protocol.CodeBuilderRuntimeActionEvent{}, &protocol.CodeBuilderScoreboardEvent{},
+		&protocol.StriderRiddenInLavaInOverworldEvent{}, &protocol.SneakCloseToSculkSensorEvent{},
+		&protocol.CarefulRestorationEvent{}, &protocol.ItemUsedEvent{},
+	),
+}
+
+// variants returns the types of the values passed, indexed by their names.
+func variants(values ...any) map[string]reflect.Type {
+	m := make(map[string]reflect.Type, len(values))
+	for _, v := range values {
+		m[reflect.TypeOf(v).Elem().Name()] = reflect.TypeOf(v)
+	}
+	return m
+}
+
+var (
+	anyType          = reflect.TypeFor[any]()
+	mapStringAnyType = reflect.TypeFor[map[string]any]()
+	bitsetType       = reflect.TypeFor[protocol.Bitset]()
+	inputFlagsType   = reflect.TypeFor[protocol.InputFlags]()
+	textMarshaler    = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshaler  = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// anyTypes holds the types that may be found in values of type any, such as those in NBT and entity metadata
+// maps, indexed by the name they are encoded with. Slices and arrays of these types are written as '[]T' and
+// '[N]T'.
+var anyTypes = map[string]reflect.Type{
+	"bool":           reflect.TypeFor[bool](),
+	"byte":           reflect.TypeFor[byte](),
+	"int16":          reflect.TypeFor[int16](),
+	"int32":          reflect.TypeFor[int32](),
+	"int64":          reflect.TypeFor[int64](),
+	"float32":        reflect.TypeFor[float32](),
+	"float64":        reflect.TypeFor[float64](),
+	"string":         reflect.TypeFor[string](),
+	"any":            anyType,
+	"map[string]any": mapStringAnyType,
+	"BlockPos":       reflect.TypeFor[protocol.BlockPos](),
+	"Vec3":           reflect.TypeFor[mgl32.Vec3](),
+}
+
+// anyTypeName returns the name that a type found in a value of type any is encoded with.
+func anyTypeName(t reflect.Type) (string, error) {
+	for name, at := range anyTypes {
+		if at == t {
+			return name, nil
+		}
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		name, err := anyTypeName(t.Elem())
+		return "[]" + name, err
+	case reflect.Array:
+		name, err := anyTypeName(t.Elem())
+		return "[" + strconv.Itoa(t.Len()) + "]" + name, err
+	}
+	return "", fmt.Errorf("unsupported type %v in value of type any", t)
+}
+
+// parseAnyTypeName parses a name returned by anyTypeName back into its type.
+func parseAnyTypeName(name string) (reflect.Type, error) {
+	if t, ok := anyTypes[name]; ok {
+		return t, nil
+	}
+	if elem, ok := strings.CutPrefix(name, "[]"); ok {
+		t, err := parseAnyTypeName(elem)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(t), nil
+	}
+	if n, elem, ok := strings.Cut(strings.TrimPrefix(name, "["), "]"); ok && strings.HasPrefix(name, "[") {
+		length, err := strconv.ParseUint(n, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid array length in type %q", name)
+		}
+		t, err := parseAnyTypeName(elem)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ArrayOf(int(length), t), nil
+	}
+	return nil, fmt.Errorf("unknown type %q", name)
+}
+
+// isOptional checks if a type is an instance of protocol.Optional.
+func isOptional(t reflect.Type) bool {
+	return t.PkgPath() == "github.com/sandertv/gophertunnel/minecraft/protocol" && strings.HasPrefix(t.Name(), "Optional[")
+}
+
+// unexportedField returns the unexported field with the name passed of the addressable struct v, in a way
+// that allows it to be read and set.
+func unexportedField(v reflect.Value, name string) reflect.Value {
+	f := v.FieldByName(name)
+	return reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+}
+
+// encodeJSON writes the JSON encoding of v to buf.
+func encodeJSON(buf *bytes.Buffer, v reflect.Value) error {
+	t := v.Type()
+	if !v.CanAddr() && (t.Kind() == reflect.Struct || t.Kind() == reflect.Array) {
+		// The unexported fields of structs can only be read from addressable values.
+		c := reflect.New(t).Elem()
+		c.Set(v)
+		v = c
+	}
+	switch {
+	case t == bitsetType:
+		b := v.Interface().(protocol.Bitset)
+		var set []int
+		for i := range b.Len() {
+			if b.Load(i) {
+				set = append(set, i)
+			}
+		}
+		return writeJSON(buf, map[string]any{"size": b.Len(), "set": set})
+	case t == inputFlagsType:
+		f := v.Interface().(protocol.InputFlags)
+		if !f.Present() {
+			buf.WriteString("null")
+			return nil
+		}
+		ids := unexportedField(v, "ids").Interface().([]int32)
+		return writeJSON(buf, map[string]any{"size": f.Len(), "ids": ids})
+	case isOptional(t):
+		if !unexportedField(v, "set").Bool() {
+			buf.WriteString("null")
+			return nil
+		}
+		return encodeJSON(buf, unexportedField(v, "val"))
+	case t.Implements(textMarshaler):
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		writeJSONString(buf, string(text))
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		buf.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buf.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			buf.WriteString(`"NaN"`)
+		case math.IsInf(f, 1):
+			buf.WriteString(`"+Inf"`)
+		case math.IsInf(f, -1):
+			buf.WriteString(`"-Inf"`)
+		default:
+			buf.WriteString(strconv.FormatFloat(f, 'g', -1, t.Bits()))
+		}
+	case reflect.String:
+		if s := v.String(); utf8.ValidString(s) {
+			writeJSONString(buf, s)
+		} else {
+			// JSON strings cannot hold invalid UTF-8, so the raw bytes are encoded instead.
+			buf.WriteString(`{"bytes":"` + base64.StdEncoding.EncodeToString([]byte(s)) + `"}`)
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			buf.WriteString(`"` + base64.StdEncoding.EncodeToString(b) + `"`)
+			return nil
+		}
+		buf.WriteByte('[')
+		for i := range v.Len() {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeJSON(buf, v.Index(i)); err != nil {
+				return fmt.Errorf("[%v]: %w", i, err)
+			}
+		}
+		buf.WriteByte(']')
+	case reflect.Map:
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(mapKey(a), mapKey(b))
+		})
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(buf, mapKey(key))
+			buf.WriteByte(':')
+			if err := encodeJSON(buf, v.MapIndex(key)); err != nil {
+				return fmt.Errorf("%v: %w", mapKey(key), err)
+			}
+		}
+		buf.WriteByte('}')
+	case reflect.Struct:
+		buf.WriteByte('{')
+		first := true
+		for _, f := range jsonFields(v) {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			writeJSONString(buf, f.name)
+			buf.WriteByte(':')
+			if err := encodeJSON(buf, f.v); err != nil {
+				return fmt.Errorf("%v: %w", f.name, err)
+			}
+		}
+		buf.WriteByte('}')
+	case reflect.Pointer:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		return encodeJSON(buf, v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		var name string
+		if t == anyType {
+			var err error
+			if name, err = anyTypeName(v.Elem().Type()); err != nil {
+				return err
+			}
+		} else if _, ok := jsonVariants[t]; ok {
+			name = v.Elem().Type().Elem().Name()
+		} else {
+			return fmt.Errorf("unsupported interface type %v", t)
+		}
+		buf.WriteString(`{"type":`)
+		writeJSONString(buf, name)
+		buf.WriteString(`,"value":`)
+		if err := encodeJSON(buf, v.Elem()); err != nil {
+			return fmt.Errorf("%v: %w", name, err)
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported type %v", t)
+	}
+	return nil
+}
+
+// jsonField is a field of a struct encoded to JSON.
+type jsonField struct {
+	name string
+	v    reflect.Value
+}
+
+// jsonFields returns the exported fields of the struct v in the order they are declared. The fields of
+// embedded structs are returned as if they were fields of v.
+func jsonFields(v reflect.Value) []jsonField {
+	var fields []jsonField
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		switch {
+		case f.Anonymous && f.Type.Kind() == reflect.Struct:
+			fields = append(fields, jsonFields(v.Field(i))...)
+		case f.IsExported():
+			fields = append(fields, jsonField{name: f.Name, v: v.Field(i)})
+		}
+	}
+	return fields
+}
+
+// mapKey returns the string form of a map key.
+func mapKey(key reflect.Value) string {
+	switch key.Kind() {
+	case reflect.String:
+		return key.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10)
+	default:
+		return strconv.FormatUint(key.Uint(), 10)
+	}
+}
+
+// writeJSON writes the JSON encoding of v, as produced by json.Marshal, to buf.
+func writeJSON(buf *bytes.Buffer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	return nil
+}
+
+// writeJSONString writes s as a JSON string to buf.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	data, _ := json.Marshal(s)
+	buf.Write(data)
+}
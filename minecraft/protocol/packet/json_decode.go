@@ -0,0 +1,288 @@
+package packet
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// decodeJSON decodes the JSON value data, as decoded by a json.Decoder with UseNumber set, into the
+// addressable value v.
+func decodeJSON(v reflect.Value, data any) error {
+	t := v.Type()
+	switch {
+	case t == bitsetType:
+		var b struct {
+			Size int
+			Set  []int
+		}
+		if err := remarshal(data, &b); err != nil {
+			return err
+		}
+		bitset := protocol.NewBitset(b.Size)
+		for _, i := range b.Set {
+			if i < 0 || i >= b.Size {
+				return fmt.Errorf("bit %v out of range for bitset of size %v", i, b.Size)
+			}
+			bitset.Set(i)
+		}
+		v.Set(reflect.ValueOf(bitset))
+		return nil
+	case t == inputFlagsType:
+		if data == nil {
+			v.SetZero()
+			return nil
+		}
+		var f struct {
+			Size int
+			IDs  []int32
+		}
+		if err := remarshal(data, &f); err != nil {
+			return err
+		}
+		for _, id := range f.IDs {
+			if id < 0 || int(id) >= f.Size {
+				return fmt.Errorf("flag %v out of range for input flags of size %v", id, f.Size)
+			}
+		}
+		v.Set(reflect.ValueOf(protocol.NewInputFlagsFromIDs(f.Size, f.IDs)))
+		return nil
+	case isOptional(t):
+		v.SetZero()
+		if data == nil {
+			return nil
+		}
+		unexportedField(v, "set").SetBool(true)
+		return decodeJSON(unexportedField(v, "val"), data)
+	case reflect.PointerTo(t).Implements(textUnmarshaler):
+		s, ok := data.(string)
+		if !ok {
+			return typeError(data, "string")
+		}
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		b, ok := data.(bool)
+		if !ok {
+			return typeError(data, "bool")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := data.(json.Number)
+		if !ok {
+			return typeError(data, "integer")
+		}
+		i, err := strconv.ParseInt(n.String(), 10, t.Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %v %v", t, n)
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := data.(json.Number)
+		if !ok {
+			return typeError(data, "integer")
+		}
+		u, err := strconv.ParseUint(n.String(), 10, t.Bits())
+		if err != nil {
+			return fmt.Errorf("invalid %v %v", t, n)
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch data := data.(type) {
+		case json.Number:
+			var err error
+			if f, err = strconv.ParseFloat(data.String(), t.Bits()); err != nil {
+				return fmt.Errorf("invalid %v %v", t, data)
+			}
+		case string:
+			switch data {
+			case "NaN":
+				f = math.NaN()
+			case "+Inf":
+				f = math.Inf(1)
+			case "-Inf":
+				f = math.Inf(-1)
+			default:
+				return fmt.Errorf("invalid %v %q", t, data)
+			}
+		default:
+			return typeError(data, "number")
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		switch data := data.(type) {
+		case string:
+			v.SetString(data)
+		case map[string]any:
+			b, err := decodeBase64(data["bytes"])
+			if err != nil || len(data) != 1 {
+				return fmt.Errorf("invalid string %v", data)
+			}
+			v.SetString(string(b))
+		default:
+			return typeError(data, "string")
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			b, err := decodeBase64(data)
+			if err != nil {
+				return err
+			}
+			if t.Kind() == reflect.Array {
+				if len(b) != t.Len() {
+					return fmt.Errorf("expected %v bytes, got %v", t.Len(), len(b))
+				}
+				reflect.Copy(v, reflect.ValueOf(b))
+				return nil
+			}
+			v.Set(reflect.ValueOf(b).Convert(t))
+			return nil
+		}
+		if data == nil && t.Kind() == reflect.Slice {
+			v.SetZero()
+			return nil
+		}
+		list, ok := data.([]any)
+		if !ok {
+			return typeError(data, "array")
+		}
+		if t.Kind() == reflect.Array && len(list) != t.Len() {
+			return fmt.Errorf("expected %v elements, got %v", t.Len(), len(list))
+		} else if t.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(t, len(list), len(list)))
+		}
+		for i, elem := range list {
+			if err := decodeJSON(v.Index(i), elem); err != nil {
+				return fmt.Errorf("[%v]: %w", i, err)
+			}
+		}
+	case reflect.Map:
+		if data == nil {
+			v.SetZero()
+			return nil
+		}
+		m, ok := data.(map[string]any)
+		if !ok {
+			return typeError(data, "object")
+		}
+		v.Set(reflect.MakeMapWithSize(t, len(m)))
+		for k, elem := range m {
+			key := reflect.New(t.Key()).Elem()
+			if err := decodeJSON(key, mapKeyJSON(t.Key(), k)); err != nil {
+				return fmt.Errorf("key %q: %w", k, err)
+			}
+			value := reflect.New(t.Elem()).Elem()
+			if err := decodeJSON(value, elem); err != nil {
+				return fmt.Errorf("%v: %w", k, err)
+			}
+			v.SetMapIndex(key, value)
+		}
+	case reflect.Struct:
+		m, ok := data.(map[string]any)
+		if !ok {
+			return typeError(data, "object")
+		}
+		fields := jsonFields(v)
+		for _, f := range fields {
+			if elem, ok := m[f.name]; ok {
+				if err := decodeJSON(f.v, elem); err != nil {
+					return fmt.Errorf("%v: %w", f.name, err)
+				}
+			}
+		}
+		for name := range m {
+			if !hasField(fields, name) {
+				return fmt.Errorf("unknown field %v in %v", name, t)
+			}
+		}
+	case reflect.Pointer:
+		if data == nil {
+			v.SetZero()
+			return nil
+		}
+		v.Set(reflect.New(t.Elem()))
+		return decodeJSON(v.Elem(), data)
+	case reflect.Interface:
+		if data == nil {
+			v.SetZero()
+			return nil
+		}
+		m, ok := data.(map[string]any)
+		name, _ := m["type"].(string)
+		if !ok || len(m) != 2 || name == "" {
+			return fmt.Errorf("expected object with type and value, got %v", data)
+		}
+		var concrete reflect.Type
+		if t == anyType {
+			var err error
+			if concrete, err = parseAnyTypeName(name); err != nil {
+				return err
+			}
+		} else if concrete, ok = jsonVariants[t][name]; !ok {
+			return fmt.Errorf("unknown %v type %q", t, name)
+		}
+		value := reflect.New(concrete).Elem()
+		if err := decodeJSON(value, m["value"]); err != nil {
+			return fmt.Errorf("%v: %w", name, err)
+		}
+		v.Set(value)
+	default:
+		return fmt.Errorf("unsupported type %v", t)
+	}
+	return nil
+}
+
+// remarshal decodes JSON data, as decoded by a json.Decoder, into the value pointed to by v using the
+// encoding/json package.
+func remarshal(data any, v any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// decodeBase64 decodes a base64 string in JSON data.
+func decodeBase64(data any) ([]byte, error) {
+	s, ok := data.(string)
+	if !ok {
+		return nil, typeError(data, "base64 string")
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	return b, nil
+}
+
+// mapKeyJSON returns the JSON data that a map key of the type passed decodes from.
+func mapKeyJSON(t reflect.Type, k string) any {
+	if t.Kind() == reflect.String {
+		return k
+	}
+	return json.Number(k)
+}
+
+// hasField checks if a field with the name passed is in fields.
+func hasField(fields []jsonField, name string) bool {
+	for _, f := range fields {
+		if f.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// typeError returns an error for JSON data that is not of the type expected.
+func typeError(data any, expected string) error {
+	return fmt.Errorf("expected %v, got %v", expected, data)
+}
@@ -3,28 +3,41 @@ package minecraft
 import (
 	"context"
 	"github.com/sandertv/go-raknet"
+	"github.com/sandertv/gophertunnel/minecraft/internal"
 	"log/slog"
 	"net"
 )
 
 // RakNet is an implementation of a RakNet v10 Network.
 type RakNet struct {
+	// UpstreamPacketListener, if non-nil, is used to create the UDP socket that a Listener listens on, so
+	// that it may be shared with other protocols such as the query protocol implemented by query.Server.
+	UpstreamPacketListener raknet.UpstreamPacketListener
+
 	l *slog.Logger
 }
 
 // DialContext ...
 func (r RakNet) DialContext(ctx context.Context, address string) (net.Conn, error) {
-	return raknet.Dialer{ErrorLog: r.l.With("net origin", "raknet")}.DialContext(ctx, address)
+	return raknet.Dialer{ErrorLog: r.log()}.DialContext(ctx, address)
 }
 
 // PingContext ...
 func (r RakNet) PingContext(ctx context.Context, address string) (response []byte, err error) {
-	return raknet.Dialer{ErrorLog: r.l.With("net origin", "raknet")}.PingContext(ctx, address)
+	return raknet.Dialer{ErrorLog: r.log()}.PingContext(ctx, address)
 }
 
 // Listen ...
 func (r RakNet) Listen(address string) (NetworkListener, error) {
-	return raknet.ListenConfig{ErrorLog: r.l.With("net origin", "raknet")}.Listen(address)
+	return raknet.ListenConfig{ErrorLog: r.log(), UpstreamPacketListener: r.UpstreamPacketListener}.Listen(address)
+}
+
+// log returns the logger passed to RakNet dialers and listeners.
+func (r RakNet) log() *slog.Logger {
+	if r.l == nil {
+		return slog.New(internal.DiscardHandler{}).With("net origin", "raknet")
+	}
+	return r.l.With("net origin", "raknet")
 }
 
 // init registers the RakNet network.
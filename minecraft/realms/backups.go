@@ -0,0 +1,113 @@
+package realms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Backup is a backup of the world in the active slot of a realm.
+type Backup struct {
+	// ID is the ID of the backup, used to download or restore it.
+	ID string `json:"backupId"`
+	// LastModifiedDate is the time at which the backup was made, as a Unix timestamp in milliseconds.
+	LastModifiedDate int64 `json:"lastModifiedDate"`
+	// Size is the size of the backup in bytes.
+	Size int64 `json:"size"`
+	// Metadata holds information on the world backed up, such as its name and game mode.
+	Metadata map[string]any `json:"metadata"`
+}
+
+// LastModified returns the time at which the backup was made.
+func (b Backup) LastModified() time.Time {
+	return time.UnixMilli(b.LastModifiedDate)
+}
+
+// Backups requests the backups of the world in the active slot of the realm. Only the owner of the realm
+// may request its backups.
+func (r *Realm) Backups(ctx context.Context) ([]Backup, error) {
+	c, err := r.api()
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Backups []Backup `json:"backups"`
+	}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/worlds/%d/backups", r.ID), nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Backups, nil
+}
+
+// RestoreBackup replaces the world in the active slot of the realm with the backup with the ID passed. The
+// realm is unavailable while the backup is being restored.
+func (r *Realm) RestoreBackup(ctx context.Context, backupID string) error {
+	c, err := r.api()
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/worlds/%d/backups?backupId=%s&clientSupportsRetries", r.ID, url.QueryEscape(backupID))
+	return c.do(ctx, http.MethodPut, path, nil, nil)
+}
+
+// Download holds the location of a world download of a realm.
+type Download struct {
+	// URL is the URL that the world may be downloaded from.
+	URL string `json:"downloadUrl"`
+	// FileExtension is the extension of the file downloaded, typically '.mcworld'.
+	FileExtension string `json:"fileExtension"`
+	// Size is the size of the world in bytes.
+	Size int64 `json:"size"`
+	// Token is the token used to authorise the download.
+	Token string `json:"token"`
+}
+
+// Download requests the location of a download of the world in the slot passed. If backupID is empty, the
+// latest version of the world is downloaded, and the backup with that ID otherwise.
+func (r *Realm) Download(ctx context.Context, slot int, backupID string) (Download, error) {
+	c, err := r.api()
+	if err != nil {
+		return Download{}, err
+	}
+	if backupID == "" {
+		backupID = "latest"
+	}
+	var download Download
+	path := fmt.Sprintf("/archive/download/world/%d/%d/%s", r.ID, slot, url.PathEscape(backupID))
+	if err := c.do(ctx, http.MethodGet, path, nil, &download); err != nil {
+		return Download{}, err
+	}
+	return download, nil
+}
+
+// DownloadWorld downloads the world in the slot passed and writes it to w, as described in Realm.Download.
+// It returns the number of bytes written.
+func (r *Realm) DownloadWorld(ctx context.Context, slot int, backupID string, w io.Writer) (int64, error) {
+	download, err := r.Download(ctx, slot, backupID)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, download.URL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("make request: %w", err)
+	}
+	if download.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+download.Token)
+	}
+	resp, err := r.client.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("download world: %v", resp.Status)
+	}
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("download world: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,70 @@
+package realms
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrPlayerNotInRealm is returned when requesting the address or players of a realm that the user is
+	// not a member of.
+	ErrPlayerNotInRealm = errors.New("player not in realm")
+	// ErrRealmNotFound is returned when requesting a realm, invite, backup or other resource that does not
+	// exist.
+	ErrRealmNotFound = errors.New("realm not found")
+	// ErrBadRequest is returned when the realms api rejects the parameters of a request, for example an
+	// unknown permission or an invalid world slot.
+	ErrBadRequest = errors.New("bad request")
+	// ErrUnauthorized is returned when the realms api rejects the Xbox Live token of the user.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is returned when the user is not allowed to perform an action, such as managing a
+	// realm that they do not own.
+	ErrForbidden = errors.New("forbidden")
+	// ErrTooManyRequests is returned when the user is rate limited by the realms api.
+	ErrTooManyRequests = errors.New("too many requests")
+	// ErrRealmUnavailable is returned when a realm is temporarily unavailable, for example because it is
+	// starting or a backup is being restored.
+	ErrRealmUnavailable = errors.New("realm unavailable")
+)
+
+// Error is an error returned by the realms api. It wraps one of the errors declared in this package based
+// on its status code, so that it may be checked using errors.Is.
+type Error struct {
+	// Method and Path are the HTTP method and path of the request that failed.
+	Method, Path string
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Code and Message are the error code and message returned by the realms api, if any.
+	Code    int    `json:"errorCode"`
+	Message string `json:"errorMsg"`
+}
+
+// Error ...
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("realms: %v %v: %v", e.Method, e.Path, e.StatusCode)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// Unwrap returns the error declared in this package that matches the status code of the Error, or nil if
+// there is none.
+func (e *Error) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrRealmNotFound
+	case http.StatusTooManyRequests:
+		return ErrTooManyRequests
+	case http.StatusServiceUnavailable:
+		return ErrRealmUnavailable
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package realms
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Permission is the permission level of a member of a realm.
+type Permission string
+
+const (
+	PermissionVisitor  Permission = "VISITOR"
+	PermissionMember   Permission = "MEMBER"
+	PermissionOperator Permission = "OPERATOR"
+)
+
+// Invite invites the players with the XUIDs passed to the realm. Invited players receive a pending invite
+// that they may accept using Client.AcceptInvite.
+func (r *Realm) Invite(ctx context.Context, xuids ...string) error {
+	return r.updateInvites(ctx, "ADD", xuids)
+}
+
+// Uninvite removes the players with the XUIDs passed from the realm, or withdraws their invites if they
+// have not yet accepted them.
+func (r *Realm) Uninvite(ctx context.Context, xuids ...string) error {
+	return r.updateInvites(ctx, "REMOVE", xuids)
+}
+
+// updateInvites applies the invite action passed to the players with the XUIDs passed.
+func (r *Realm) updateInvites(ctx context.Context, action string, xuids []string) error {
+	c, err := r.api()
+	if err != nil {
+		return err
+	}
+	if len(xuids) == 0 {
+		return nil
+	}
+	invites := make(map[string]string, len(xuids))
+	for _, xuid := range xuids {
+		invites[xuid] = action
+	}
+	body := map[string]any{"invites": invites}
+	return c.do(ctx, http.MethodPut, fmt.Sprintf("/invites/%d/invite/update", r.ID), body, nil)
+}
+
+// SetPermission changes the permission level of the member of the realm with the XUID passed.
+func (r *Realm) SetPermission(ctx context.Context, xuid string, permission Permission) error {
+	c, err := r.api()
+	if err != nil {
+		return err
+	}
+	body := map[string]any{"permission": permission, "xuid": xuid}
+	return c.do(ctx, http.MethodPut, fmt.Sprintf("/worlds/%d/userPermission", r.ID), body, nil)
+}
+
+// SetDefaultPermission changes the permission level that players are given when they join the realm for
+// the first time.
+func (r *Realm) SetDefaultPermission(ctx context.Context, permission Permission) error {
+	c, err := r.api()
+	if err != nil {
+		return err
+	}
+	body := map[string]any{"permission": permission}
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/worlds/%d/defaultPermission", r.ID), body, nil); err != nil {
+		return err
+	}
+	r.DefaultPermission = string(permission)
+	return nil
+}
+
+// PendingInvite is an invite to a realm that the user has not yet accepted or rejected.
+type PendingInvite struct {
+	// ID is the ID of the invite, used to accept or reject it.
+	ID string `json:"invitationId"`
+	// WorldName and WorldDescription are the name and description of the realm.
+	WorldName        string `json:"worldName"`
+	WorldDescription string `json:"worldDescription"`
+	// WorldOwnerName and WorldOwnerXUID are the name and XUID of the owner of the realm.
+	WorldOwnerName string `json:"worldOwnerName"`
+	WorldOwnerXUID string `json:"worldOwnerXuid"`
+	// CreatedTime is the time at which the invite was sent, as a Unix timestamp in milliseconds.
+	CreatedTime int64 `json:"createdTime"`
+}
+
+// Created returns the time at which the invite was sent.
+func (i PendingInvite) Created() time.Time {
+	return time.UnixMilli(i.CreatedTime)
+}
+
+// PendingInvites gets all invites to realms that the user has not yet accepted or rejected.
+func (c *Client) PendingInvites(ctx context.Context) ([]PendingInvite, error) {
+	var response struct {
+		Invites []PendingInvite `json:"invites"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/invites/pending", nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Invites, nil
+}
+
+// PendingInviteCount gets the number of invites to realms that the user has not yet accepted or rejected.
+func (c *Client) PendingInviteCount(ctx context.Context) (int, error) {
+	var count int
+	if err := c.do(ctx, http.MethodGet, "/invites/count/pending", nil, &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// AcceptInvite accepts the pending invite with the ID passed, making the user a member of the realm.
+func (c *Client) AcceptInvite(ctx context.Context, inviteID string) error {
+	return c.do(ctx, http.MethodPut, "/invites/accept/"+url.PathEscape(inviteID), nil, nil)
+}
+
+// RejectInvite rejects the pending invite with the ID passed.
+func (c *Client) RejectInvite(ctx context.Context, inviteID string) error {
+	return c.do(ctx, http.MethodPut, "/invites/reject/"+url.PathEscape(inviteID), nil, nil)
+}
+
+// AcceptInviteCode joins the realm with the invite code passed, making the user a member of it.
+func (c *Client) AcceptInviteCode(ctx context.Context, code string) (Realm, error) {
+	var realm Realm
+	if err := c.do(ctx, http.MethodPost, "/worlds/v1/link/accept/"+url.PathEscape(code), nil, &realm); err != nil {
+		return Realm{}, err
+	}
+	realm.client = c
+	return realm, nil
+}
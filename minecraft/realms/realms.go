@@ -1,12 +1,16 @@
 package realms
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/sandertv/gophertunnel/minecraft/auth"
@@ -17,8 +21,11 @@ import (
 // Client is an instance of the realms api with a token.
 type Client struct {
 	tokenSrc   oauth2.TokenSource
-	xblToken   *auth.XBLToken
 	httpClient *http.Client
+	baseURL    string
+
+	xblToken   *auth.XBLToken
+	xblTokenMu sync.Mutex
 }
 
 const (
@@ -26,20 +33,34 @@ const (
 	realmsRelyingParty = "https://pocket.realms.minecraft.net/"
 )
 
-var (
-	ErrPlayerNotInRealm = errors.New("player not in realm")
-	ErrRealmNotFound    = errors.New("realm not found")
-)
+// ClientConfig holds the configuration of a Client.
+type ClientConfig struct {
+	// HTTPClient is the HTTP client used to request the realms api and the Xbox Live token used to
+	// authenticate with it. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+	// BaseURL is the base URL of the realms api, such as the URL of a local stand-in used for testing.
+	// If empty, the URL of the Bedrock Edition realms api is used.
+	BaseURL string
+}
 
 // NewClient returns a new Client instance with the supplied token source for authentication.
 // If httpClient is nil, http.DefaultClient will be used to request the realms api.
 func NewClient(src oauth2.TokenSource, httpClient *http.Client) *Client {
-	if httpClient == nil {
-		httpClient = http.DefaultClient
+	return ClientConfig{HTTPClient: httpClient}.New(src)
+}
+
+// New returns a new Client using the ClientConfig, with the supplied token source for authentication.
+func (conf ClientConfig) New(src oauth2.TokenSource) *Client {
+	if conf.HTTPClient == nil {
+		conf.HTTPClient = http.DefaultClient
+	}
+	if conf.BaseURL == "" {
+		conf.BaseURL = realmsBaseURL
 	}
 	return &Client{
 		tokenSrc:   src,
-		httpClient: httpClient,
+		httpClient: conf.HTTPClient,
+		baseURL:    strings.TrimSuffix(conf.BaseURL, "/"),
 	}
 }
 
@@ -95,10 +116,11 @@ type Realm struct {
 	MinigameID string `json:"minigameId"`
 	// MinigameImage is always null
 	MinigameImage string `json:"minigameImage"`
-	// ActiveSlot is unused, always 1
+	// ActiveSlot is the ID of the world slot that is currently loaded, from 1 to 3.
 	ActiveSlot int `json:"activeSlot"`
-	// Slots is unused, always null
-	Slots []struct{} `json:"slots"`
+	// Slots holds the world slots of the realm.
+	// NOTE: this is only sent when directly requesting a realm as its owner.
+	Slots []Slot `json:"slots"`
 	// Member is Unknown, always false. (even when member or owner)
 	Member bool `json:"member"`
 	// ClubID is the ID of the associated Xbox Live club as an integer.
@@ -125,10 +147,11 @@ type RealmAddress struct {
 // Address requests the address and protocol used to connect to this realm.
 // It will wait for the realm to start if it is currently offline.
 func (r *Realm) Address(ctx context.Context) (RealmAddress, error) {
-	if r.client == nil {
-		return RealmAddress{}, fmt.Errorf("realm client is nil")
+	c, err := r.api()
+	if err != nil {
+		return RealmAddress{}, err
 	}
-	return r.client.RealmAddress(ctx, r.ID)
+	return c.RealmAddress(ctx, r.ID)
 }
 
 // RealmAddress requests the address and protocol used to connect to a realm
@@ -141,22 +164,12 @@ func (r *Client) RealmAddress(ctx context.Context, realmID int) (RealmAddress, e
 		case <-ctx.Done():
 			return RealmAddress{}, ctx.Err()
 		case <-ticker.C:
-			body, status, err := r.request(ctx, fmt.Sprintf("/worlds/%d/join", realmID))
-			if err != nil {
-				switch status {
-				case 503:
+			var address RealmAddress
+			if err := r.do(ctx, http.MethodGet, fmt.Sprintf("/worlds/%d/join", realmID), nil, &address); err != nil {
+				if errors.Is(err, ErrRealmUnavailable) {
 					continue
-				case 404:
-					return RealmAddress{}, ErrRealmNotFound
-				case 403:
-					return RealmAddress{}, ErrPlayerNotInRealm
 				}
-				return RealmAddress{}, err
-			}
-
-			var address RealmAddress
-			if err := json.Unmarshal(body, &address); err != nil {
-				return RealmAddress{}, err
+				return RealmAddress{}, notInRealm(err)
 			}
 			return address, nil
 		}
@@ -166,27 +179,41 @@ func (r *Client) RealmAddress(ctx context.Context, realmID int) (RealmAddress, e
 // OnlinePlayers gets all the players currently on this realm,
 // Returns a 403 error if the current user is not the owner of the Realm.
 func (r *Realm) OnlinePlayers(ctx context.Context) (players []Player, err error) {
-	body, status, err := r.client.request(ctx, fmt.Sprintf("/worlds/%d", r.ID))
+	c, err := r.api()
 	if err != nil {
-		switch status {
-		case 403:
-			return nil, ErrPlayerNotInRealm
-		case 404:
-			return nil, ErrRealmNotFound
-		}
 		return nil, err
 	}
+	response, err := c.RealmByID(ctx, r.ID)
+	if err != nil {
+		return nil, notInRealm(err)
+	}
+	return response.Players, nil
+}
 
-	var response Realm
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, err
+// api returns the Client that the Realm belongs to.
+func (r *Realm) api() (*Client, error) {
+	if r.client == nil {
+		return nil, fmt.Errorf("realm client is nil")
 	}
+	return r.client, nil
+}
 
-	return response.Players, nil
+// notInRealm returns ErrPlayerNotInRealm or ErrRealmNotFound if err is an *Error with a 403 or 404 status
+// code respectively, and err otherwise.
+func notInRealm(err error) error {
+	switch {
+	case errors.Is(err, ErrForbidden):
+		return ErrPlayerNotInRealm
+	case errors.Is(err, ErrRealmNotFound):
+		return ErrRealmNotFound
+	}
+	return err
 }
 
 // xboxToken returns the xbox token used for the api.
 func (r *Client) xboxToken(ctx context.Context) (*auth.XBLToken, error) {
+	r.xblTokenMu.Lock()
+	defer r.xblTokenMu.Unlock()
 	if r.xblToken != nil && r.xblToken.Valid() {
 		return r.xblToken, nil
 	}
@@ -199,75 +226,105 @@ func (r *Client) xboxToken(ctx context.Context) (*auth.XBLToken, error) {
 		return nil, err
 	}
 
-	r.xblToken, err = auth.RequestXBLToken(ctx, t, realmsRelyingParty)
+	r.xblToken, err = auth.RequestXBLToken(auth.WithContextClient(ctx, r.httpClient), t, realmsRelyingParty)
 	return r.xblToken, err
 }
 
-// request sends an http get request to path with the right headers for the api set.
-func (r *Client) request(ctx context.Context, path string) (body []byte, status int, err error) {
+// request sends an http request to path with the right headers for the api set. If in is non-nil, it
+// is encoded as the JSON body of the request. If the api responds with an error status code, an *Error is
+// returned.
+func (r *Client) request(ctx context.Context, method, path string, in any) ([]byte, error) {
 	if path == "" {
-		return nil, 0, fmt.Errorf("path is empty")
+		return nil, fmt.Errorf("path is empty")
 	}
 	if path[0] != '/' {
 		path = "/" + path
 	}
-	req, err := http.NewRequestWithContext(ctx, "GET", realmsBaseURL+path, nil)
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return nil, fmt.Errorf("encode request body: %w", err)
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.baseURL+path, body)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	req.Header.Set("User-Agent", "MCPE/UWP")
 	req.Header.Set("Client-Version", protocol.CurrentVersion)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 	xbl, err := r.xboxToken(ctx)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	xbl.SetAuthHeader(req)
 
 	resp, err := r.httpClient.Do(req)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, err = io.ReadAll(resp.Body)
+	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, resp.StatusCode, err
+		return nil, err
 	}
 
 	if resp.StatusCode >= 400 {
-		return body, resp.StatusCode, fmt.Errorf("HTTP Error: %d", resp.StatusCode)
+		e := &Error{Method: method, Path: path, StatusCode: resp.StatusCode}
+		// The body is not always JSON, in which case only the status code is reported.
+		_ = json.Unmarshal(b, e)
+		return nil, e
 	}
+	return b, nil
+}
 
-	return body, resp.StatusCode, nil
+// do sends an http request using request and decodes the JSON response body into out, if non-nil.
+func (r *Client) do(ctx context.Context, method, path string, in, out any) error {
+	b, err := r.request(ctx, method, path, in)
+	if err != nil {
+		return err
+	}
+	if out == nil || len(b) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("decode response body: %w", err)
+	}
+	return nil
 }
 
 // Realm gets a realm by its invite code.
 func (c *Client) Realm(ctx context.Context, code string) (Realm, error) {
-	body, _, err := c.request(ctx, fmt.Sprintf("/worlds/v1/link/%s", code))
-	if err != nil {
+	var realm Realm
+	if err := c.do(ctx, http.MethodGet, "/worlds/v1/link/"+url.PathEscape(code), nil, &realm); err != nil {
 		return Realm{}, err
 	}
+	realm.client = c
+	return realm, nil
+}
 
+// RealmByID gets a realm by its ID. The realm returned holds all of its members and, if the user owns it,
+// its world slots.
+func (c *Client) RealmByID(ctx context.Context, realmID int) (Realm, error) {
 	var realm Realm
-	if err := json.Unmarshal(body, &realm); err != nil {
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/worlds/%d", realmID), nil, &realm); err != nil {
 		return Realm{}, err
 	}
 	realm.client = c
-
 	return realm, nil
 }
 
 // Realms gets a list of all realms the token has access to.
 func (c *Client) Realms(ctx context.Context) ([]Realm, error) {
-	body, _, err := c.request(ctx, "/worlds")
-	if err != nil {
-		return nil, err
-	}
-
 	var response struct {
 		Servers []Realm `json:"servers"`
 	}
-	if err := json.Unmarshal(body, &response); err != nil {
+	if err := c.do(ctx, http.MethodGet, "/worlds", nil, &response); err != nil {
 		return nil, err
 	}
 
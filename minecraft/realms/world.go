@@ -0,0 +1,191 @@
+package realms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Slot is a world slot of a realm. A realm has three world slots, of which one is loaded at a time.
+type Slot struct {
+	// ID is the ID of the slot, from 1 to 3.
+	ID int `json:"slotId"`
+	// Options holds the options of the world in the slot.
+	Options SlotOptions `json:"options"`
+}
+
+// UnmarshalJSON decodes a Slot, whose options are sent as a JSON object encoded in a string.
+func (s *Slot) UnmarshalJSON(b []byte) error {
+	var data struct {
+		ID      int             `json:"slotId"`
+		Options json.RawMessage `json:"options"`
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	s.ID, s.Options = data.ID, SlotOptions{}
+	options := []byte(data.Options)
+	var str string
+	if err := json.Unmarshal(options, &str); err == nil {
+		options = []byte(str)
+	}
+	if len(options) == 0 || string(options) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(options, &s.Options); err != nil {
+		return fmt.Errorf("decode slot options: %w", err)
+	}
+	return nil
+}
+
+// SlotOptions holds the options of the world in a world slot of a realm.
+type SlotOptions struct {
+	// SlotName is the name of the slot, as shown in the realm settings.
+	SlotName string `json:"slotName"`
+	// GameMode and Difficulty are the default game mode and the difficulty of the world.
+	GameMode   int `json:"gameMode"`
+	Difficulty int `json:"difficulty"`
+	// ForceGameMode specifies if players are put in the default game mode every time they join.
+	ForceGameMode bool `json:"forceGameMode"`
+	// PVP specifies if players can damage each other.
+	PVP bool `json:"pvp"`
+	// SpawnAnimals, SpawnMonsters and SpawnNPCs specify if animals, monsters and NPCs spawn in the world.
+	SpawnAnimals  bool `json:"spawnAnimals"`
+	SpawnMonsters bool `json:"spawnMonsters"`
+	SpawnNPCs     bool `json:"spawnNPCs"`
+	// SpawnProtection is the radius around the spawn in which only operators can build.
+	SpawnProtection int `json:"spawnProtection"`
+	// CommandBlocks specifies if command blocks are enabled.
+	CommandBlocks bool `json:"commandBlocks"`
+	// CheatsAllowed specifies if cheats are enabled.
+	CheatsAllowed bool `json:"cheatsAllowed"`
+	// TexturePacksRequired specifies if players must download the resource packs of the world to join.
+	TexturePacksRequired bool `json:"texturePacksRequired"`
+	// AdventureMap specifies if the world is an adventure map.
+	AdventureMap bool `json:"adventureMap"`
+	// WorldTemplateID and WorldTemplateImage are the ID and image of the template the world was created
+	// from, if any.
+	WorldTemplateID    int    `json:"worldTemplateId"`
+	WorldTemplateImage string `json:"worldTemplateImage"`
+}
+
+// Settings holds the settings of a realm that may be changed using Realm.Configure.
+type Settings struct {
+	// Name and Description are the name and description of the realm.
+	Name, Description string
+	// Options holds the options of the world in the active slot of the realm.
+	Options SlotOptions
+}
+
+// Open opens the realm, so that members may join it.
+func (r *Realm) Open(ctx context.Context) error {
+	if err := r.setState(ctx, "open"); err != nil {
+		return err
+	}
+	r.State = "OPEN"
+	return nil
+}
+
+// Close closes the realm, disconnecting all players and preventing members from joining it until it is
+// opened again.
+func (r *Realm) Close(ctx context.Context) error {
+	if err := r.setState(ctx, "close"); err != nil {
+		return err
+	}
+	r.State = "CLOSED"
+	return nil
+}
+
+// setState opens or closes the realm.
+func (r *Realm) setState(ctx context.Context, state string) error {
+	c, err := r.api()
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPut, fmt.Sprintf("/worlds/%d/%s", r.ID, state), nil, nil)
+}
+
+// Settings requests the current settings of the realm, including the options of its active slot. An error
+// is returned if the realm has no slot with the ID of its active slot.
+func (r *Realm) Settings(ctx context.Context) (Settings, error) {
+	c, err := r.api()
+	if err != nil {
+		return Settings{}, err
+	}
+	realm, err := c.RealmByID(ctx, r.ID)
+	if err != nil {
+		return Settings{}, err
+	}
+	for _, slot := range realm.Slots {
+		if slot.ID == realm.ActiveSlot {
+			return Settings{Name: realm.Name, Description: realm.MOTD, Options: slot.Options}, nil
+		}
+	}
+	return Settings{}, fmt.Errorf("realm %d has no active slot %d", r.ID, realm.ActiveSlot)
+}
+
+// Configure changes the name and description of the realm and the options of its active slot. Settings
+// obtained using Realm.Settings may be modified and passed to change only some of the settings.
+func (r *Realm) Configure(ctx context.Context, settings Settings) error {
+	c, err := r.api()
+	if err != nil {
+		return err
+	}
+	body := map[string]any{
+		"description": map[string]string{"name": settings.Name, "description": settings.Description},
+		"options":     settings.Options,
+	}
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/worlds/%d/configuration", r.ID), body, nil); err != nil {
+		return err
+	}
+	r.Name, r.MOTD = settings.Name, settings.Description
+	return nil
+}
+
+// Rename changes the name of the realm, leaving its description and the options of its slots unchanged.
+func (r *Realm) Rename(ctx context.Context, name string) error {
+	c, err := r.api()
+	if err != nil {
+		return err
+	}
+	realm, err := c.RealmByID(ctx, r.ID)
+	if err != nil {
+		return err
+	}
+	body := map[string]any{
+		"description": map[string]string{"name": name, "description": realm.MOTD},
+	}
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/worlds/%d/configuration", r.ID), body, nil); err != nil {
+		return err
+	}
+	r.Name, r.MOTD = name, realm.MOTD
+	return nil
+}
+
+// WorldSlots requests the world slots of the realm. Only the owner of the realm may request its slots.
+func (r *Realm) WorldSlots(ctx context.Context) ([]Slot, error) {
+	c, err := r.api()
+	if err != nil {
+		return nil, err
+	}
+	realm, err := c.RealmByID(ctx, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	r.Slots, r.ActiveSlot = realm.Slots, realm.ActiveSlot
+	return realm.Slots, nil
+}
+
+// SwitchSlot loads the world in the slot with the ID passed, from 1 to 3, restarting the realm.
+func (r *Realm) SwitchSlot(ctx context.Context, slot int) error {
+	c, err := r.api()
+	if err != nil {
+		return err
+	}
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/worlds/%d/slot/%d", r.ID, slot), nil, nil); err != nil {
+		return err
+	}
+	r.ActiveSlot = slot
+	return nil
+}
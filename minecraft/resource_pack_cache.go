@@ -1,13 +1,20 @@
 package minecraft
 
 import (
+	"bytes"
 	"context"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sandertv/gophertunnel/minecraft/resource"
@@ -27,53 +34,345 @@ func (key ResourcePackCacheKey) Matches(pack *resource.Pack) bool {
 	return pack.UUID() == key.UUID && pack.Version() == key.Version && uint64(pack.Len()) == key.Size
 }
 
-// ResourcePackCache allows a Dialer to reuse resource packs downloaded earlier. Cache failures are
-// non-fatal: a nil pack or an error from Load falls back to a normal download, and errors from Store are
-// only logged.
+// ResourcePackCache allows a Dialer to reuse resource packs downloaded earlier. A cached pack is only used
+// if its Checksum equals the SHA256 checksum the server advertises in the ResourcePackDataInfo packet.
+// Cache failures are non-fatal: a nil pack or an error from Load falls back to a normal download, and
+// errors from Store are only logged.
 type ResourcePackCache interface {
 	// Load returns the pack stored under key, or nil if it is not cached.
 	Load(ctx context.Context, key ResourcePackCacheKey) (*resource.Pack, error)
-	// Store stores a pack under key for a later Load.
+	// Store stores a pack under key for a later Load. Packs passed to Store have a Checksum equal to the
+	// SHA256 checksum the server advertised for them in the ResourcePackDataInfo packet.
 	Store(ctx context.Context, key ResourcePackCacheKey, pack *resource.Pack) error
 }
 
-// DirResourcePackCache is a ResourcePackCache that stores resource packs as files in a directory. Entries
-// are never evicted: the caller owns the directory and its lifecycle.
+// EvictingResourcePackCache is a ResourcePackCache that can also remove packs. A Dialer with an
+// EvictingResourcePackCache deletes a cached pack once its checksum no longer matches the checksum advertised
+// by the server, instead of leaving it in the cache until it is replaced by the download that follows.
+type EvictingResourcePackCache interface {
+	ResourcePackCache
+	// Delete removes the pack stored under key, if any. Errors returned are only logged.
+	Delete(ctx context.Context, key ResourcePackCacheKey) error
+}
+
+// ResumableResourcePackCache is a ResourcePackCache that also persists the chunks of packs that are still
+// being downloaded. A Dialer with a ResumableResourcePackCache resumes a download interrupted by a
+// disconnect from the last completed chunk on the next login. Partial downloads are identified by the SHA256
+// checksum the server advertised in the ResourcePackDataInfo packet, so that data of a different pack
+// content is never resumed.
+type ResumableResourcePackCache interface {
+	ResourcePackCache
+	// LoadPartial returns the data downloaded so far for the pack under key with the checksum passed, or nil
+	// if no such partial download exists.
+	LoadPartial(ctx context.Context, key ResourcePackCacheKey, checksum []byte) ([]byte, error)
+	// StorePartial writes data at offset in the partial download of the pack under key with the checksum
+	// passed. An offset of 0 starts a new partial download, replacing any previous one.
+	StorePartial(ctx context.Context, key ResourcePackCacheKey, checksum []byte, offset uint64, data []byte) error
+	// DeletePartial removes the partial download of the pack under key, if any.
+	DeletePartial(ctx context.Context, key ResourcePackCacheKey) error
+}
+
+// DirResourcePackCache is a ResourcePackCache that stores resource packs as files in a directory. Next to
+// every pack, the SHA256 checksum advertised by the server is stored, and Load only returns a pack if its
+// content still matches that checksum. Entries that fail to read or verify are removed and reported as a
+// miss, so that the pack is downloaded again.
+//
+// Entries are evicted least recently used first once the packs in the directory exceed MaxSize bytes, and
+// entries not used for longer than MaxAge are evicted regardless of size. With both left zero, entries are
+// never evicted.
+//
+// DirResourcePackCache implements EvictingResourcePackCache and ResumableResourcePackCache: Partial downloads
+// are stored next to the packs and are evicted once older than MaxAge, but do not count towards MaxSize.
+//
+// DirResourcePackCache is safe for concurrent use, including by multiple Dialers that each hold their own
+// DirResourcePackCache for the same directory.
 type DirResourcePackCache struct {
 	// Dir is the directory packs are stored in. It is created when the first pack is stored.
 	Dir string
+	// MaxSize is the maximum total size in bytes of the packs stored in Dir. When a Store would exceed it,
+	// the least recently used packs are removed until it fits. Zero means no limit.
+	MaxSize int64
+	// MaxAge is the maximum duration since a pack was last stored or loaded before it is evicted. Zero
+	// means no limit.
+	MaxAge time.Duration
 }
 
-// Load returns the pack stored under key, or nil if no file exists for it.
+// dirResourcePackCacheLocks holds a *sync.Mutex for every directory used by a DirResourcePackCache, so
+// that separate DirResourcePackCache values for the same directory do not race.
+var dirResourcePackCacheLocks sync.Map
+
+// Load returns the pack stored under key, or nil if no valid, unexpired entry exists for it.
 func (cache DirResourcePackCache) Load(_ context.Context, key ResourcePackCacheKey) (*resource.Pack, error) {
-	pack, err := resource.ReadPath(cache.path(key))
+	mu := cache.lock()
+	mu.Lock()
+	defer mu.Unlock()
+
+	path := cache.path(key)
+	info, err := os.Stat(path)
 	if errors.Is(err, fs.ErrNotExist) {
 		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if cache.MaxAge > 0 && time.Since(info.ModTime()) > cache.MaxAge {
+		return nil, cache.remove(path)
+	}
+	checksum, err := os.ReadFile(checksumPath(path))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	pack, err := resource.ReadPath(path)
+	if err != nil || !validChecksum(pack, checksum) {
+		// The entry was corrupted or written by an older version without a checksum: Drop it so that the
+		// pack is downloaded and stored again.
+		return nil, cache.remove(path)
+	}
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		return nil, err
 	}
-	return pack, err
+	return pack, nil
 }
 
-// Store writes the pack to a file under key, replacing any previous entry.
+// Store writes the pack and its checksum to files under key, replacing any previous entry, and evicts
+// entries to stay within MaxSize and MaxAge.
 func (cache DirResourcePackCache) Store(_ context.Context, key ResourcePackCacheKey, pack *resource.Pack) error {
+	if cache.MaxSize > 0 && int64(pack.Len()) > cache.MaxSize {
+		return fmt.Errorf("store resource pack: pack size %v exceeds cache size %v", pack.Len(), cache.MaxSize)
+	}
+	mu := cache.lock()
+	mu.Lock()
+	defer mu.Unlock()
+
 	if err := os.MkdirAll(cache.Dir, 0o755); err != nil {
 		return err
 	}
+	checksum := pack.Checksum()
+	path := cache.path(key)
+	if err := cache.writeFile(checksumPath(path), strings.NewReader(hex.EncodeToString(checksum[:]))); err != nil {
+		return err
+	}
+	if err := cache.writeFile(path, io.NewSectionReader(pack, 0, int64(pack.Len()))); err != nil {
+		return err
+	}
+	return cache.evict(path)
+}
+
+// Delete removes the pack stored under key and its checksum.
+func (cache DirResourcePackCache) Delete(_ context.Context, key ResourcePackCacheKey) error {
+	mu := cache.lock()
+	mu.Lock()
+	defer mu.Unlock()
+
+	return cache.remove(cache.path(key))
+}
+
+// LoadPartial returns the data of the partial download stored under key, or nil if none exists for the
+// checksum passed.
+func (cache DirResourcePackCache) LoadPartial(_ context.Context, key ResourcePackCacheKey, checksum []byte) ([]byte, error) {
+	mu := cache.lock()
+	mu.Lock()
+	defer mu.Unlock()
+
+	path := partialPath(cache.path(key))
+	stored, err := os.ReadFile(checksumPath(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if string(bytes.TrimSpace(stored)) != hex.EncodeToString(checksum) {
+		// The server now advertises different pack content under the same UUID and version.
+		return nil, cache.remove(path)
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, cache.remove(path)
+	} else if err != nil {
+		return nil, err
+	}
+	if cache.MaxAge > 0 && time.Since(info.ModTime()) > cache.MaxAge {
+		return nil, cache.remove(path)
+	}
+	return os.ReadFile(path)
+}
+
+// StorePartial writes data at offset to the partial download stored under key.
+func (cache DirResourcePackCache) StorePartial(_ context.Context, key ResourcePackCacheKey, checksum []byte, offset uint64, data []byte) error {
+	mu := cache.lock()
+	mu.Lock()
+	defer mu.Unlock()
+
+	path := partialPath(cache.path(key))
+	if offset == 0 {
+		if err := os.MkdirAll(cache.Dir, 0o755); err != nil {
+			return err
+		}
+		if err := cache.writeFile(checksumPath(path), strings.NewReader(hex.EncodeToString(checksum))); err != nil {
+			return err
+		}
+		return cache.writeFile(path, bytes.NewReader(data))
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	if uint64(info.Size()) < offset {
+		_ = f.Close()
+		return fmt.Errorf("store partial resource pack: offset %v beyond partial size %v", offset, info.Size())
+	}
+	if err := f.Truncate(int64(offset)); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if _, err := f.WriteAt(data, int64(offset)); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// DeletePartial removes the partial download stored under key.
+func (cache DirResourcePackCache) DeletePartial(_ context.Context, key ResourcePackCacheKey) error {
+	mu := cache.lock()
+	mu.Lock()
+	defer mu.Unlock()
+
+	return cache.remove(partialPath(cache.path(key)))
+}
+
+// writeFile atomically writes the data in r to path through a temporary file in the cache directory.
+func (cache DirResourcePackCache) writeFile(path string, r io.Reader) error {
 	temp, err := os.CreateTemp(cache.Dir, "pack-*.tmp")
 	if err != nil {
 		return err
 	}
 	defer func() { _ = os.Remove(temp.Name()) }()
-	if _, err := io.Copy(temp, io.NewSectionReader(pack, 0, int64(pack.Len()))); err != nil {
+	if _, err := io.Copy(temp, r); err != nil {
 		_ = temp.Close()
 		return err
 	}
 	if err := temp.Close(); err != nil {
 		return err
 	}
-	return os.Rename(temp.Name(), cache.path(key))
+	return os.Rename(temp.Name(), path)
+}
+
+// evict removes expired entries and, least recently used first, entries exceeding MaxSize. The entry at
+// keep, which was just stored, is never removed.
+func (cache DirResourcePackCache) evict(keep string) error {
+	if cache.MaxSize <= 0 && cache.MaxAge <= 0 {
+		return nil
+	}
+	files, err := os.ReadDir(cache.Dir)
+	if err != nil {
+		return err
+	}
+	type entry struct {
+		path    string
+		size    int64
+		lastUse time.Time
+	}
+	var (
+		entries []entry
+		total   int64
+	)
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		if f.IsDir() || (ext != ".mcpack" && ext != ".part") {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			// The file was removed after reading the directory.
+			continue
+		}
+		path := filepath.Join(cache.Dir, f.Name())
+		if ext == ".part" {
+			if cache.MaxAge > 0 && time.Since(info.ModTime()) > cache.MaxAge {
+				if err := cache.remove(path); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if path != keep && cache.MaxAge > 0 && time.Since(info.ModTime()) > cache.MaxAge {
+			if err := cache.remove(path); err != nil {
+				return err
+			}
+			continue
+		}
+		entries = append(entries, entry{path: path, size: info.Size(), lastUse: info.ModTime()})
+		total += info.Size()
+	}
+	if cache.MaxSize <= 0 || total <= cache.MaxSize {
+		return nil
+	}
+	slices.SortFunc(entries, func(a, b entry) int {
+		return a.lastUse.Compare(b.lastUse)
+	})
+	for _, e := range entries {
+		if total <= cache.MaxSize {
+			break
+		}
+		if e.path == keep {
+			continue
+		}
+		if err := cache.remove(e.path); err != nil {
+			return err
+		}
+		total -= e.size
+	}
+	return nil
+}
+
+// remove removes the pack or partial download at path and its checksum file.
+func (cache DirResourcePackCache) remove(path string) error {
+	if err := os.Remove(checksumPath(path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// lock returns the mutex guarding the directory of the cache.
+func (cache DirResourcePackCache) lock() *sync.Mutex {
+	dir, err := filepath.Abs(cache.Dir)
+	if err != nil {
+		dir = filepath.Clean(cache.Dir)
+	}
+	mu, _ := dirResourcePackCacheLocks.LoadOrStore(dir, new(sync.Mutex))
+	return mu.(*sync.Mutex)
 }
 
 // path returns the file a pack with key is stored at. The version is escaped as it comes from the server.
 func (cache DirResourcePackCache) path(key ResourcePackCacheKey) string {
 	return filepath.Join(cache.Dir, key.UUID.String()+"_"+url.PathEscape(key.Version)+".mcpack")
 }
+
+// checksumPath returns the file the checksum of the pack stored at path is stored at.
+func checksumPath(path string) string {
+	return path + ".sha256"
+}
+
+// partialPath returns the file the partial download of the pack stored at path is stored at.
+func partialPath(path string) string {
+	return path + ".part"
+}
+
+// validChecksum checks if the hex encoded checksum stored for a pack matches the checksum of its content.
+func validChecksum(pack *resource.Pack, stored []byte) bool {
+	expected, err := hex.DecodeString(string(bytes.TrimSpace(stored)))
+	if err != nil {
+		return false
+	}
+	checksum := pack.Checksum()
+	return bytes.Equal(expected, checksum[:])
+}
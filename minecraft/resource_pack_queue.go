@@ -21,9 +21,24 @@ type resourcePackQueue struct {
 	downloadingPacks map[string]downloadingPack
 	awaitingPacks    map[string]*downloadingPack
 	chunkSize        uint32
+
+	// cachedPacks holds the packs of which the ResourcePackDataInfo matched a cached pack, but for which it
+	// is not yet known if the server lets the client skip downloading them. Servers that send one
+	// ResourcePackDataInfo at a time only send the next after all chunks of the previous pack were requested.
+	cachedPacks []cachedResourcePack
+	// parallel is true once the server sent a ResourcePackDataInfo while cached packs were held, meaning it
+	// does not wait for the chunks of one pack to be requested before sending the data info of the next.
+	parallel bool
+}
+
+// cachedResourcePack is a downloading pack for which the cached pack may be used instead of downloading it.
+type cachedResourcePack struct {
+	rawID, id string
+	pack      *downloadingPack
 }
 
-// downloadingPack is a resource pack that is being downloaded by a client connection.
+// downloadingPack is a resource pack that is being downloaded by a client connection. The buffer may hold
+// chunks resumed from an earlier connection, in which case expectedIndex starts after them.
 type downloadingPack struct {
 	buf           *bytes.Buffer
 	chunkSize     uint32
@@ -31,7 +46,12 @@ type downloadingPack struct {
 	expectedIndex uint32
 	newFrag       chan []byte
 	contentKey    string
+	checksum      []byte
 	cacheKey      ResourcePackCacheKey
+	// cached is the pack found in the ResourcePackCache for cacheKey, if any. It is used instead of
+	// downloading the pack if its checksum matches the one in the ResourcePackDataInfo packet and the server
+	// does not wait for the pack to be downloaded.
+	cached *resource.Pack
 }
 
 // Request 'requests' all resource packs passed, provided they all exist in the resourcePackQueue. Clients
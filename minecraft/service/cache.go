@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sandertv/gophertunnel/minecraft/auth"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// CacheConfig holds the configuration of a Cache.
+type CacheConfig struct {
+	// Dir is the directory that the Cache stores its entries in, so that they survive a restart. It is
+	// created if it does not exist. If empty, entries are only kept in memory.
+	Dir string
+	// TTL is the duration after which a Discovery is considered stale. Stale documents are still returned
+	// by Cache.Discover, while a fresh one is requested in the background. If zero, a TTL of 24 hours is
+	// used.
+	TTL time.Duration
+	// Pinned is a Discovery returned by Cache.Discover when no Discovery was cached and the discovery
+	// endpoint is unavailable, such as one shipped along with a server. It is returned regardless of the
+	// application type and version passed.
+	Pinned *Discovery
+}
+
+// Cache is a Discovery cache that is kept on disk, so that services may be discovered while the discovery
+// endpoint is unavailable. It additionally stores the OpenID configuration and keys of the
+// AuthorizationEnvironment returned by Cache.AuthorizationEnvironment, so that multiplayer tokens may be
+// verified while the authorization service is unavailable.
+//
+// A Cache must be created using CacheConfig.New. It is safe for concurrent use.
+type Cache struct {
+	conf CacheConfig
+
+	mu         sync.Mutex
+	entries    map[string]cacheEntry
+	refreshing map[string]struct{}
+
+	env   *AuthorizationEnvironment
+	envMu sync.Mutex
+}
+
+// cacheEntry is an entry of a Cache, as stored in a file in the directory of the Cache.
+type cacheEntry struct {
+	// Fetched is the time at which the data of the entry was obtained from the remote endpoint.
+	Fetched time.Time `json:"fetched"`
+	// Data is the data obtained.
+	Data json.RawMessage `json:"data"`
+}
+
+// New creates a Cache using the CacheConfig. The directory of the Cache is created if it does not exist.
+func (conf CacheConfig) New() (*Cache, error) {
+	if conf.TTL <= 0 {
+		conf.TTL = 24 * time.Hour
+	}
+	if conf.Dir != "" {
+		if err := os.MkdirAll(conf.Dir, 0700); err != nil {
+			return nil, fmt.Errorf("create cache directory: %w", err)
+		}
+	}
+	return &Cache{
+		conf:       conf,
+		entries:    make(map[string]cacheEntry),
+		refreshing: make(map[string]struct{}),
+	}, nil
+}
+
+// Default obtains a Discovery using ApplicationTypeMinecraftPE and protocol.CurrentVersion as the
+// application type and version, as described in Cache.Discover.
+func (c *Cache) Default(ctx context.Context) (*Discovery, error) {
+	return c.Discover(ctx, ApplicationTypeMinecraftPE, protocol.CurrentVersion)
+}
+
+// Discover obtains a Discovery for the application type and version passed, like the package-level Discover
+// function. A Discovery cached within the TTL of the Cache is returned without making any requests. A stale
+// Discovery is returned as well, while a fresh one is requested in the background, so that an outage of the
+// discovery endpoint does not affect callers. If no Discovery was cached, one is requested, falling back to
+// the pinned Discovery of the Cache if the request fails.
+func (c *Cache) Discover(ctx context.Context, appType, version string) (*Discovery, error) {
+	requestURL := discoveryRequestURL(appType, version)
+	name := cacheName("discovery", appType+"-"+version)
+
+	if entry, ok := c.load(name); ok {
+		d := new(Discovery)
+		if err := json.Unmarshal(entry.Data, d); err == nil {
+			if time.Since(entry.Fetched) >= c.conf.TTL {
+				c.revalidate(ctx, name, requestURL)
+			}
+			return d, nil
+		}
+	}
+	d, err := requestDiscovery(ctx, requestURL)
+	if err != nil {
+		if c.conf.Pinned != nil {
+			return c.conf.Pinned, nil
+		}
+		return nil, err
+	}
+	// The Discovery is valid even if it could not be written to disk: It is still held in memory and is
+	// stored again once it is revalidated.
+	_ = c.store(name, d)
+	return d, nil
+}
+
+// revalidate requests a fresh Discovery from the URL passed in the background and stores it under the name
+// passed, unless it is already being requested.
+func (c *Cache) revalidate(ctx context.Context, name, requestURL string) {
+	c.mu.Lock()
+	if _, ok := c.refreshing[name]; ok {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[name] = struct{}{}
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 15*time.Second)
+	go func() {
+		defer cancel()
+		if d, err := requestDiscovery(ctx, requestURL); err == nil {
+			_ = c.store(name, d)
+		}
+		c.mu.Lock()
+		delete(c.refreshing, name)
+		c.mu.Unlock()
+	}()
+}
+
+// AuthorizationEnvironment returns the AuthorizationEnvironment discovered using Cache.Default. The
+// OpenID configuration and keys used by the verifier of the environment are stored in the Cache, and are
+// used if they cannot be requested from the authorization service. The environment is only discovered
+// once and uses the HTTP client set on the context.Context of the first call.
+func (c *Cache) AuthorizationEnvironment(ctx context.Context) (*AuthorizationEnvironment, error) {
+	c.envMu.Lock()
+	defer c.envMu.Unlock()
+	if c.env != nil {
+		return c.env, nil
+	}
+	d, err := c.Default(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("discover service endpoints: %w", err)
+	}
+	e := new(AuthorizationEnvironment)
+	if err := d.Environment(e); err != nil {
+		return nil, fmt.Errorf("decode environment for auth: %w", err)
+	}
+	e.HTTPClient = auth.ContextClient(ctx)
+	e.Cache = c
+	c.env = e
+	return e, nil
+}
+
+// load returns the entry stored under the name passed, reading it from the directory of the Cache if it
+// is not held in memory.
+func (c *Cache) load(name string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[name]; ok {
+		return entry, true
+	}
+	if c.conf.Dir == "" {
+		return cacheEntry{}, false
+	}
+	b, err := os.ReadFile(filepath.Join(c.conf.Dir, name))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return cacheEntry{}, false
+	}
+	c.entries[name] = entry
+	return entry, true
+}
+
+// store encodes the value passed as JSON and stores it under the name passed, writing it to the directory
+// of the Cache if it has one.
+func (c *Cache) store(name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode entry: %w", err)
+	}
+	entry := cacheEntry{Fetched: time.Now(), Data: data}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[name] = entry
+	if c.conf.Dir == "" {
+		return nil
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode entry: %w", err)
+	}
+	// Write to a temporary file first, so that a crash while writing never leaves a corrupt entry behind.
+	f, err := os.CreateTemp(c.conf.Dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create entry file: %w", err)
+	}
+	_, err = f.Write(b)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filepath.Join(c.conf.Dir, name))
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("write entry file: %w", err)
+	}
+	return nil
+}
+
+// loadJSON decodes the entry stored under the name passed into v. If no such entry exists, an error wrapping
+// fs.ErrNotExist is returned.
+func (c *Cache) loadJSON(name string, v any) (time.Time, error) {
+	entry, ok := c.load(name)
+	if !ok {
+		return time.Time{}, fmt.Errorf("load %v: %w", name, fs.ErrNotExist)
+	}
+	if err := json.Unmarshal(entry.Data, v); err != nil {
+		return time.Time{}, fmt.Errorf("decode %v: %w", name, err)
+	}
+	return entry.Fetched, nil
+}
+
+// cacheName returns the file name of the entry of the kind passed for the key passed. Keys that are not
+// safe to use in a file name are hashed.
+func cacheName(kind, key string) string {
+	if strings.ContainsFunc(key, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_')
+	}) {
+		sum := sha256.Sum256([]byte(key))
+		key = hex.EncodeToString(sum[:8])
+	}
+	return kind + "-" + key + ".json"
+}
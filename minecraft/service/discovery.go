@@ -110,18 +110,34 @@ func Discover(ctx context.Context, appType, version string) (*Discovery, error)
 	discoveryCacheMu.Lock()
 	defer discoveryCacheMu.Unlock()
 
-	requestURL := discoveryURL.JoinPath("/api/v1.0/discovery", appType, "builds", version).String()
+	requestURL := discoveryRequestURL(appType, version)
 	if d, ok := discoveryCache[requestURL]; ok {
 		return d, nil
 	}
+	d, err := requestDiscovery(ctx, requestURL)
+	if err != nil {
+		return nil, err
+	}
+	discoveryCache[requestURL] = d
+	return d, nil
+}
 
+// discoveryRequestURL returns the URL requested to discover the environments of an application type and
+// version.
+func discoveryRequestURL(appType, version string) string {
+	return discoveryURL.JoinPath("/api/v1.0/discovery", appType, "builds", version).String()
+}
+
+// requestDiscovery requests a Discovery from the URL passed, using the HTTP client set on the context.Context
+// passed.
+func requestDiscovery(ctx context.Context, requestURL string) (*Discovery, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("make request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", internal.UserAgent)
-	
+
 	resp, err := auth.ContextClient(ctx).Do(req)
 	if err != nil {
 		return nil, err
@@ -135,7 +151,5 @@ func Discover(ctx context.Context, appType, version string) (*Discovery, error)
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, fmt.Errorf("decode response body: %w", err)
 	}
-	d := &result.Data
-	discoveryCache[requestURL] = d
-	return d, nil
+	return &result.Data, nil
 }
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/df-mc/go-playfab/v2/title"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// multiplayerAudience is the audience of multiplayer tokens, which is also used as the client ID when
+// verifying them.
+const multiplayerAudience = "api://auth-minecraft-services/multiplayer"
+
+// IssuerConfig holds the configuration of an Issuer.
+type IssuerConfig struct {
+	// Key is the private key used to sign multiplayer tokens. It must be an *rsa.PrivateKey, in which case
+	// tokens are signed using RS256, or an *ecdsa.PrivateKey on the P-256, P-384 or P-521 curve, in which
+	// case tokens are signed using ES256, ES384 or ES512 respectively. If nil, a new 2048-bit RSA key is
+	// generated, which means that tokens issued are no longer accepted once the Issuer is recreated.
+	Key crypto.Signer
+	// KeyID is the ID of the key, as published in the JWKS of the Issuer and set as the 'kid' header of
+	// tokens issued. If empty, the JWK thumbprint of the key is used.
+	KeyID string
+	// Lifetime is the duration for which multiplayer tokens are valid. If zero, tokens are valid for an
+	// hour.
+	Lifetime time.Duration
+	// PlayFabTitleID is the PlayFab title ID claimed by tokens issued for a MultiplayerIdentity that has no
+	// PlayFabTitleID. If empty, the title ID of the base version of the game, '20CA2', is used.
+	PlayFabTitleID string
+}
+
+// Issuer is a self-hosted issuer of multiplayer tokens, for networks that authenticate players themselves
+// instead of through the authorization service of Minecraft. Tokens issued hold the same claims as those
+// issued by the authorization service, so that they can be verified in the same way, using the verifier
+// returned by Issuer.Verifier or the OpenID configuration and JWKS served by Issuer.ServeHTTP.
+//
+// An Issuer must be created using IssuerConfig.New. It is safe for concurrent use.
+type Issuer struct {
+	conf   IssuerConfig
+	url    *url.URL
+	issuer string
+	alg    jose.SignatureAlgorithm
+	signer jose.Signer
+	jwk    jose.JSONWebKey
+}
+
+// New creates an Issuer that issues multiplayer tokens under the issuer URL passed, such as
+// 'https://auth.example.com'. If the OpenID configuration of the Issuer is served using Issuer.ServeHTTP,
+// it must be served under this URL.
+func (conf IssuerConfig) New(issuer *url.URL) (*Issuer, error) {
+	if issuer == nil || !issuer.IsAbs() {
+		return nil, errors.New("service: issuer URL must be absolute")
+	}
+	if conf.Key == nil {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, fmt.Errorf("generate key: %w", err)
+		}
+		conf.Key = key
+	}
+	if conf.Lifetime <= 0 {
+		conf.Lifetime = time.Hour
+	}
+	if conf.PlayFabTitleID == "" {
+		conf.PlayFabTitleID = "20CA2"
+	}
+
+	var alg jose.SignatureAlgorithm
+	switch key := conf.Key.(type) {
+	case *rsa.PrivateKey:
+		alg = jose.RS256
+	case *ecdsa.PrivateKey:
+		switch key.Curve {
+		case elliptic.P256():
+			alg = jose.ES256
+		case elliptic.P384():
+			alg = jose.ES384
+		case elliptic.P521():
+			alg = jose.ES512
+		default:
+			return nil, fmt.Errorf("service: unsupported curve %v", key.Curve.Params().Name)
+		}
+	default:
+		return nil, fmt.Errorf("service: unsupported key type %T", conf.Key)
+	}
+
+	public := conf.Key.Public()
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("encode public key: %w", err)
+	}
+	// The authorization service publishes a SHA-1 thumbprint for every key, which the verifiers returned by
+	// AuthorizationEnvironment.VerifierContext require to be present.
+	thumbprint := sha1.Sum(der)
+	jwk := jose.JSONWebKey{
+		Key:                       public,
+		Algorithm:                 string(alg),
+		Use:                       "sig",
+		CertificateThumbprintSHA1: thumbprint[:],
+	}
+	if conf.KeyID == "" {
+		sum, err := jwk.Thumbprint(crypto.SHA256)
+		if err != nil {
+			return nil, fmt.Errorf("compute key thumbprint: %w", err)
+		}
+		conf.KeyID = base64.RawURLEncoding.EncodeToString(sum)
+	}
+	jwk.KeyID = conf.KeyID
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: conf.Key}, (&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", conf.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("create signer: %w", err)
+	}
+	return &Issuer{
+		conf: conf,
+		url:  issuer,
+		// The issuer is normalised in the same way as by AuthorizationEnvironment.VerifierContext, which
+		// adds a trailing '/' to issuer URLs without a path.
+		issuer: issuer.JoinPath().String(),
+		alg:    alg,
+		signer: signer,
+		jwk:    jwk,
+	}, nil
+}
+
+// MultiplayerIdentity is the identity of a player claimed by a multiplayer token.
+type MultiplayerIdentity struct {
+	// XUID is the Xbox Live user ID of the player. Players whose token claims no XUID are not considered
+	// authenticated by a Listener.
+	XUID string
+	// DisplayName is the name of the player.
+	DisplayName string
+	// PlayFabID is the ID of the PlayFab master player account of the player.
+	PlayFabID string
+	// PlayFabTitleID is the PlayFab title ID that the token is issued for. If empty, the PlayFabTitleID of
+	// the IssuerConfig is used.
+	PlayFabTitleID string
+}
+
+// issuedClaims holds the claims of a multiplayer token issued by an Issuer.
+type issuedClaims struct {
+	jwt.Claims
+	IdentityProviderType string `json:"ipt"`
+	PlayFabID            string `json:"mid,omitempty"`
+	PlayFabTitleID       string `json:"tid"`
+	ClientPublicKey      string `json:"cpk"`
+	XUID                 string `json:"xid,omitempty"`
+	DisplayName          string `json:"xname"`
+}
+
+// MultiplayerToken issues a multiplayer token for the player with the identity passed, bound to the public
+// key of the player's connection.
+func (i *Issuer) MultiplayerToken(identity MultiplayerIdentity, key *ecdsa.PublicKey) (string, error) {
+	if identity.DisplayName == "" {
+		return "", errors.New("service: MultiplayerIdentity.DisplayName cannot be empty string")
+	}
+	if identity.PlayFabTitleID == "" {
+		identity.PlayFabTitleID = i.conf.PlayFabTitleID
+	}
+	b, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", fmt.Errorf("encode public key: %w", err)
+	}
+	now := time.Now()
+	claims := issuedClaims{
+		Claims: jwt.Claims{
+			Issuer:    i.issuer,
+			Subject:   identity.PlayFabID,
+			Audience:  jwt.Audience{multiplayerAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Expiry:    jwt.NewNumericDate(now.Add(i.conf.Lifetime)),
+		},
+		IdentityProviderType: "PlayFab",
+		PlayFabID:            identity.PlayFabID,
+		PlayFabTitleID:       identity.PlayFabTitleID,
+		ClientPublicKey:      base64.StdEncoding.EncodeToString(b),
+		XUID:                 identity.XUID,
+		DisplayName:          identity.DisplayName,
+	}
+	token, err := jwt.Signed(i.signer).Claims(claims).Serialize()
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
+	return token, nil
+}
+
+// TokenSource returns an IssuerTokenSource that issues multiplayer tokens for the identity passed. It may
+// be used as the MultiplayerTokenSource of a minecraft.Dialer.
+func (i *Issuer) TokenSource(identity MultiplayerIdentity) *IssuerTokenSource {
+	return &IssuerTokenSource{issuer: i, identity: identity}
+}
+
+// IssuerTokenSource issues multiplayer tokens for a single identity using an Issuer. It implements the
+// minecraft.MultiplayerTokenSource interface.
+type IssuerTokenSource struct {
+	issuer   *Issuer
+	identity MultiplayerIdentity
+}
+
+// MultiplayerToken issues a multiplayer token bound to the public key passed.
+func (s *IssuerTokenSource) MultiplayerToken(_ context.Context, key *ecdsa.PublicKey) (string, error) {
+	return s.issuer.MultiplayerToken(s.identity, key)
+}
+
+// KeySet returns the JWKS of the Issuer, holding the public key that tokens are signed with.
+func (i *Issuer) KeySet() jose.JSONWebKeySet {
+	return jose.JSONWebKeySet{Keys: []jose.JSONWebKey{i.jwk}}
+}
+
+// Verifier returns an oidc.IDTokenVerifier that verifies multiplayer tokens issued by the Issuer without
+// making any requests. It may be set as the Verifier of a minecraft.ListenConfig.
+func (i *Issuer) Verifier() *oidc.IDTokenVerifier {
+	return oidc.NewVerifier(i.issuer, &oidc.StaticKeySet{PublicKeys: []crypto.PublicKey{i.jwk.Key}}, &oidc.Config{
+		ClientID:             multiplayerAudience,
+		SupportedSigningAlgs: []string{string(i.alg)},
+	})
+}
+
+// Environment returns an AuthorizationEnvironment whose verifier, as returned by
+// AuthorizationEnvironment.VerifierContext, verifies tokens issued by the Issuer by requesting the OpenID
+// configuration served by Issuer.ServeHTTP under the issuer URL.
+func (i *Issuer) Environment() *AuthorizationEnvironment {
+	return &AuthorizationEnvironment{
+		ServiceURI:     i.url,
+		Issuer:         i.url,
+		PlayFabTitleID: title.Title(i.conf.PlayFabTitleID),
+	}
+}
+
+// ServeHTTP serves the OpenID configuration of the Issuer under '/.well-known/openid-configuration' and its
+// JWKS under '/.well-known/keys', both relative to the path of the issuer URL. Other requests are responded
+// to with 404 Not Found.
+func (i *Issuer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	var v any
+	switch r.URL.Path {
+	case i.url.JoinPath("/.well-known/openid-configuration").Path:
+		v = map[string]any{
+			"issuer":                                i.issuer,
+			"jwks_uri":                              i.url.JoinPath("/.well-known/keys").String(),
+			"response_types_supported":              []string{"id_token"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{string(i.alg)},
+			"claims_supported":                      []string{"iss", "sub", "aud", "exp", "nbf", "iat", "ipt", "mid", "tid", "cpk", "xid", "xname"},
+		}
+	case i.url.JoinPath("/.well-known/keys").Path:
+		v = i.KeySet()
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/hex"
@@ -58,13 +59,24 @@ func newRefreshingKeySet(ctx context.Context, env *AuthorizationEnvironment, jwk
 		sigAlgs = []jose.SignatureAlgorithm{jose.RS256}
 	}
 
-	return &refreshingKeySet{
+	r := &refreshingKeySet{
 		env:             env,
 		jwksURL:         jwksURL,
 		refreshInterval: refreshInterval,
 		sigAlgs:         sigAlgs,
 		ctx:             context.WithoutCancel(ctx),
 	}
+	if env.Cache != nil {
+		// Seed the keys with those obtained previously, so that tokens may be verified without
+		// requesting the JWKS while the authorization service is unavailable.
+		var raw json.RawMessage
+		if fetched, err := env.Cache.loadJSON(cacheName("jwks", jwksURL), &raw); err == nil {
+			if keyset, err := decodeKeySet(bytes.NewReader(raw)); err == nil {
+				r.cachedKeys, r.lastFetch = keyset.Keys, fetched
+			}
+		}
+	}
+	return r
 }
 
 // VerifySignature verifies a JWT signature using cached keys, optionally refreshing on mismatch.
@@ -169,10 +181,17 @@ func (r *refreshingKeySet) updateKeys() ([]jose.JSONWebKey, error) {
 		return nil, internal.Err(resp)
 	}
 
-	keyset, err := decodeKeySet(resp.Body)
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+	keyset, err := decodeKeySet(bytes.NewReader(b))
 	if err != nil {
 		return nil, fmt.Errorf("decode key set: %w", err)
 	}
+	if r.env.Cache != nil {
+		_ = r.env.Cache.store(cacheName("jwks", r.jwksURL), json.RawMessage(b))
+	}
 	return keyset.Keys, nil
 }
 
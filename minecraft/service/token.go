@@ -63,6 +63,11 @@ type AuthorizationEnvironment struct {
 	// be refreshed. If zero, a default of 30 minutes is used.
 	KeyRefreshInterval time.Duration `json:"-"`
 
+	// Cache, if non-nil, stores the OpenID configuration and public keys obtained for verifying
+	// multiplayer tokens, which are used in place of the remote ones if they cannot be requested.
+	// It is set on environments returned by [Cache.AuthorizationEnvironment].
+	Cache *Cache `json:"-"`
+
 	// verifier verifies OpenID Multiplayer Token issued by the authorization service.
 	// It is cached and kept by [Environment.Verifier] to reduce network time.
 	verifier *oidc.IDTokenVerifier
@@ -237,7 +242,7 @@ func (e *AuthorizationEnvironment) VerifierContext(ctx context.Context) (*oidc.I
 	// We need to append '/' on the issuer if not present.
 	issuer := e.Issuer.JoinPath().String()
 	e.verifier = oidc.NewVerifier(issuer, keySet, &oidc.Config{
-		ClientID:             "api://auth-minecraft-services/multiplayer",
+		ClientID:             multiplayerAudience,
 		SupportedSigningAlgs: config.Algorithms,
 	})
 	return e.verifier, nil
@@ -248,6 +253,26 @@ func (e *AuthorizationEnvironment) VerifierContext(ctx context.Context) (*oidc.I
 // may be used to resolve the signing keys and algorithms accepted when verifying
 // tokens issued by the authorization service.
 func (e *AuthorizationEnvironment) configuration(ctx context.Context) (*oidc.ProviderConfig, error) {
+	config, err := e.requestConfiguration(ctx)
+	if e.Cache == nil {
+		return config, err
+	}
+	name := cacheName("openid", e.Issuer.String())
+	if err != nil {
+		// Fall back to the configuration obtained previously, so that tokens may still be
+		// verified while the authorization service is unavailable.
+		cached := new(oidc.ProviderConfig)
+		if _, cacheErr := e.Cache.loadJSON(name, cached); cacheErr != nil {
+			return nil, err
+		}
+		return cached, nil
+	}
+	_ = e.Cache.store(name, config)
+	return config, nil
+}
+
+// requestConfiguration requests the OpenID configuration published by the authorization service.
+func (e *AuthorizationEnvironment) requestConfiguration(ctx context.Context) (*oidc.ProviderConfig, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.Issuer.JoinPath("/.well-known/openid-configuration").String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("make request: %w", err)
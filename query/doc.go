@@ -6,4 +6,7 @@
 // others do not. A different kind of 'query', which is supported by all servers, may be performed using the
 // go-raknet library. (raknet.Ping()) Server softwares which do not implement the query protocol include the
 // Bedrock Dedicated Server.
+//
+// Do queries a server as a client. A Server answers the queries of clients using the information of a
+// minecraft.Listener, either on a UDP socket of its own or by sharing the UDP socket of a RakNet Listener.
 package query
@@ -0,0 +1,15 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+)
+
+// discardHandler implements a slog.Handler that is always disabled. It mirrors
+// the DiscardHandler of the minecraft package, which cannot be imported here.
+type discardHandler struct{}
+
+func (d discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (d discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler        { return d }
+func (d discardHandler) WithGroup(string) slog.Handler             { return d }
@@ -0,0 +1,333 @@
+package query
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/netip"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sandertv/gophertunnel/minecraft"
+	"github.com/sandertv/gophertunnel/minecraft/protocol"
+)
+
+// ServerConfig holds the configuration of a Server.
+type ServerConfig struct {
+	// ErrorLog is the logger that errors encountered while answering queries are logged to. By default,
+	// errors are not logged.
+	ErrorLog *slog.Logger
+	// TokenExpiry is the duration for which a handshake token issued to a client may be used to request
+	// information. If zero, tokens expire after 30 seconds.
+	TokenExpiry time.Duration
+
+	// GameType is the game type reported to clients. If empty, 'SMP' is used.
+	GameType string
+	// Map is the name of the map reported to clients. If empty, the sub-name of the ServerStatus of the
+	// Listener is used.
+	Map string
+	// Plugins is the server software and plugins reported in full information responses, conventionally
+	// formatted as 'Software: Plugin A; Plugin B'. If empty, 'Gophertunnel' is used.
+	Plugins string
+	// Extra holds additional keys and values reported in full information responses. Values in Extra
+	// replace the values otherwise reported under the same keys.
+	Extra map[string]string
+}
+
+// Server answers queries sent by clients using the UT3 query protocol, such as those of server list
+// websites, with information obtained from a minecraft.Listener and its ServerStatusProvider.
+//
+// A Server either listens on a UDP socket of its own, using Server.Serve or Server.ListenAndServe, or shares
+// the UDP socket of a RakNet Listener, by setting it as the UpstreamPacketListener of a minecraft.RakNet.
+// A Server must be created using ServerConfig.New and answers queries once Server.SetListener is called.
+type Server struct {
+	conf ServerConfig
+
+	listener atomic.Pointer[minecraft.Listener]
+
+	mu        sync.Mutex
+	tokens    map[netip.AddrPort]handshakeToken
+	lastSweep time.Time
+}
+
+// handshakeToken is a token issued to a client in response to a handshake request.
+type handshakeToken struct {
+	value  int32
+	expiry time.Time
+}
+
+// New creates a Server using the ServerConfig.
+func (conf ServerConfig) New() *Server {
+	if conf.ErrorLog == nil {
+		conf.ErrorLog = slog.New(discardHandler{})
+	}
+	conf.ErrorLog = conf.ErrorLog.With("src", "query")
+	if conf.TokenExpiry <= 0 {
+		conf.TokenExpiry = time.Second * 30
+	}
+	if conf.GameType == "" {
+		conf.GameType = "SMP"
+	}
+	if conf.Plugins == "" {
+		conf.Plugins = "Gophertunnel"
+	}
+	return &Server{conf: conf, tokens: make(map[netip.AddrPort]handshakeToken)}
+}
+
+// SetListener sets the minecraft.Listener that the Server obtains the information sent to clients from.
+// Queries received before SetListener is called are ignored.
+func (s *Server) SetListener(l *minecraft.Listener) {
+	s.listener.Store(l)
+}
+
+// ListenAndServe listens on the UDP address passed and answers queries received on it, as described in
+// Server.Serve.
+func (s *Server) ListenAndServe(address string) error {
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return err
+	}
+	return s.Serve(conn)
+}
+
+// Serve answers queries received on the net.PacketConn passed until it is closed. Packets that are not
+// queries are discarded. Serve always returns a non-nil error.
+func (s *Server) Serve(conn net.PacketConn) error {
+	defer conn.Close()
+	b := make([]byte, 1500)
+	for {
+		n, addr, err := conn.ReadFrom(b)
+		if err != nil {
+			return err
+		}
+		if isQuery(b[:n]) {
+			s.handle(conn, b[:n], addr)
+		}
+	}
+}
+
+// ListenPacket listens on the UDP address passed and returns a net.PacketConn that answers queries
+// received on it, and returns all other packets from ReadFrom. It implements raknet.UpstreamPacketListener,
+// so that a Server may be set as the UpstreamPacketListener of a minecraft.RakNet to share its UDP socket:
+//
+//	srv := query.ServerConfig{}.New()
+//	l, err := minecraft.ListenConfig{}.ListenNetwork(minecraft.RakNet{UpstreamPacketListener: srv}, ":19132")
+//	if err != nil {
+//		panic(err)
+//	}
+//	srv.SetListener(l)
+func (s *Server) ListenPacket(network, address string) (net.PacketConn, error) {
+	conn, err := net.ListenPacket(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &sharedConn{PacketConn: conn, s: s}, nil
+}
+
+// sharedConn is a net.PacketConn shared between a Server and another protocol, such as RakNet.
+type sharedConn struct {
+	net.PacketConn
+	s *Server
+}
+
+// ReadFrom reads the next packet that is not a query from the connection, answering all queries read
+// before it.
+func (c *sharedConn) ReadFrom(b []byte) (n int, addr net.Addr, err error) {
+	for {
+		n, addr, err = c.PacketConn.ReadFrom(b)
+		if err != nil || !isQuery(b[:n]) {
+			return n, addr, err
+		}
+		c.s.handle(c.PacketConn, b[:n], addr)
+	}
+}
+
+// isQuery checks if the packet passed is a query request. Query requests start with the magic bytes of the
+// protocol, which do not collide with any RakNet packet IDs.
+func isQuery(b []byte) bool {
+	return len(b) >= len(version) && bytes.Equal(b[:len(version)], version[:])
+}
+
+// handle handles the query request b received from addr and writes the response to conn.
+func (s *Server) handle(conn net.PacketConn, b []byte, addr net.Addr) {
+	resp, err := s.respond(b, addr)
+	if err != nil {
+		s.conf.ErrorLog.Debug("handle query: "+err.Error(), "raddr", addr.String())
+		return
+	}
+	if resp == nil {
+		return
+	}
+	if _, err := conn.WriteTo(resp, addr); err != nil && !errors.Is(err, net.ErrClosed) {
+		s.conf.ErrorLog.Error("write query response: "+err.Error(), "raddr", addr.String())
+	}
+}
+
+// respond returns the response to the query request b received from addr, or nil if no response should be
+// sent.
+func (s *Server) respond(b []byte, addr net.Addr) ([]byte, error) {
+	l := s.listener.Load()
+	if l == nil {
+		return nil, nil
+	}
+	addrPort, err := netip.ParseAddrPort(addr.String())
+	if err != nil {
+		return nil, fmt.Errorf("parse address: %w", err)
+	}
+	// The request is formed of the magic bytes, the request type, the session ID and, for information
+	// requests, the handshake token, optionally followed by 4 bytes of padding to request full information.
+	b = b[len(version):]
+	if len(b) < 5 {
+		return nil, fmt.Errorf("request too short: %v bytes", len(b))
+	}
+	requestType, sessionID := b[0], b[1:5]
+	buf := bytes.NewBuffer(make([]byte, 0, 256))
+	buf.WriteByte(requestType)
+	buf.Write(sessionID)
+
+	switch requestType {
+	case queryTypeHandshake:
+		token, err := s.issueToken(addrPort)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(strconv.Itoa(int(token)))
+		buf.WriteByte(0)
+	case queryTypeInformation:
+		if len(b) < 9 {
+			return nil, fmt.Errorf("information request too short: %v bytes", len(b))
+		}
+		if !s.validToken(addrPort, int32(binary.BigEndian.Uint32(b[5:9]))) {
+			return nil, fmt.Errorf("invalid or expired handshake token")
+		}
+		if len(b) >= 13 {
+			s.writeFull(buf, l)
+		} else {
+			s.writeBasic(buf, l)
+		}
+	default:
+		return nil, fmt.Errorf("unknown request type %X", requestType)
+	}
+	return buf.Bytes(), nil
+}
+
+// issueToken issues a new handshake token for the address passed.
+func (s *Server) issueToken(addr netip.AddrPort) (int32, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1<<31))
+	if err != nil {
+		return 0, fmt.Errorf("generate token: %w", err)
+	}
+	token := int32(n.Int64())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= s.conf.TokenExpiry {
+		// Remove expired tokens periodically, so that tokens of clients that never request information do
+		// not pile up.
+		for a, t := range s.tokens {
+			if now.After(t.expiry) {
+				delete(s.tokens, a)
+			}
+		}
+		s.lastSweep = now
+	}
+	s.tokens[addr] = handshakeToken{value: token, expiry: now.Add(s.conf.TokenExpiry)}
+	return token, nil
+}
+
+// validToken checks if the token passed was issued to the address passed and has not yet expired.
+func (s *Server) validToken(addr netip.AddrPort, token int32) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tokens[addr]
+	return ok && t.value == token && time.Now().Before(t.expiry)
+}
+
+// writeBasic writes the basic information of the Listener passed to buf.
+func (s *Server) writeBasic(buf *bytes.Buffer, l *minecraft.Listener) {
+	status := l.Status()
+	ip, port := s.host(l)
+	for _, v := range []string{status.ServerName, s.conf.GameType, s.mapName(status), strconv.Itoa(status.PlayerCount), strconv.Itoa(status.MaxPlayers)} {
+		buf.WriteString(v)
+		buf.WriteByte(0)
+	}
+	_ = binary.Write(buf, binary.LittleEndian, port)
+	buf.WriteString(ip)
+	buf.WriteByte(0)
+}
+
+// writeFull writes the full information of the Listener passed, including the names of all players
+// connected to it, to buf.
+func (s *Server) writeFull(buf *bytes.Buffer, l *minecraft.Listener) {
+	status := l.Status()
+	ip, port := s.host(l)
+	info := [][2]string{
+		{"hostname", status.ServerName},
+		{"gametype", s.conf.GameType},
+		{"game_id", "MINECRAFTPE"},
+		{"version", protocol.CurrentVersion},
+		{"server_engine", "Gophertunnel"},
+		{"plugins", s.conf.Plugins},
+		{"map", s.mapName(status)},
+		{"numplayers", strconv.Itoa(status.PlayerCount)},
+		{"maxplayers", strconv.Itoa(status.MaxPlayers)},
+		{"whitelist", "off"},
+		{"hostip", ip},
+		{"hostport", strconv.Itoa(int(port))},
+	}
+	buf.Write(splitNum[:])
+	buf.Write([]byte{0x80, 0x00})
+	written := make(map[string]struct{}, len(info))
+	for _, kv := range info {
+		if v, ok := s.conf.Extra[kv[0]]; ok {
+			kv[1] = v
+		}
+		written[kv[0]] = struct{}{}
+		writeKeyValue(buf, kv[0], kv[1])
+	}
+	for k, v := range s.conf.Extra {
+		if _, ok := written[k]; !ok {
+			writeKeyValue(buf, k, v)
+		}
+	}
+	// The player key starts with a null byte, which terminates the information section with an empty key.
+	buf.Write(playerKey[:])
+	for _, conn := range l.Conns() {
+		buf.WriteString(conn.IdentityData().DisplayName)
+		buf.WriteByte(0)
+	}
+	buf.WriteByte(0)
+}
+
+// writeKeyValue writes a null-terminated key and value to buf.
+func writeKeyValue(buf *bytes.Buffer, k, v string) {
+	buf.WriteString(k)
+	buf.WriteByte(0)
+	buf.WriteString(v)
+	buf.WriteByte(0)
+}
+
+// mapName returns the map name reported to clients.
+func (s *Server) mapName(status minecraft.ServerStatus) string {
+	if s.conf.Map != "" {
+		return s.conf.Map
+	}
+	return status.ServerSubName
+}
+
+// host returns the IP address and port that the Listener passed is listening on.
+func (s *Server) host(l *minecraft.Listener) (string, uint16) {
+	addr, err := netip.ParseAddrPort(l.Addr().String())
+	if err != nil {
+		return "0.0.0.0", 0
+	}
+	return addr.Addr().Unmap().String(), addr.Port()
+}